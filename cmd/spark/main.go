@@ -1,15 +1,85 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"runtime/debug"
+	"strings"
 
 	"github.com/charmbracelet/bubbletea"
+	"github.com/dpeluche/spark/internal/cli"
+	"github.com/dpeluche/spark/internal/config"
+	"github.com/dpeluche/spark/internal/core"
 	"github.com/dpeluche/spark/internal/tui"
+	"github.com/dpeluche/spark/internal/updater"
 )
 
 func main() {
+	// Load the inventory before touching the terminal so errors stay readable
+	tools, err := core.LoadInventory(config.ToolsFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "spark: invalid tool inventory:")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	settings, err := config.Load(config.SettingsFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "spark: invalid settings:", err)
+		os.Exit(1)
+	}
+	pins, unknown, err := updater.ParsePins(settings.Pins, tools)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "spark: invalid settings: %s: %v\n", config.SettingsFile(), err)
+		os.Exit(1)
+	}
+	// A pin outlives the tool it was for, e.g. when the tool is disabled
+	if len(unknown) > 0 {
+		fmt.Fprintf(os.Stderr, "spark: warning: ignoring pins for unknown or disabled tools: %s\n", strings.Join(unknown, ", "))
+	}
+
+	// Unreadable records are left for the user to fix; without a store nothing
+	// is recorded this run, so they are not overwritten either
+	rollbacks, err := updater.OpenRollbacks(config.RollbackFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "spark: warning: ignoring invalid rollback records:", err)
+		rollbacks = nil
+	}
+	history := updater.NewHistory(config.HistoryFile())
+
+	// Headless subcommands (list, check, update, rollback, history, export, sync) never start the TUI
+	if args := os.Args[1:]; cli.IsCommand(args) {
+		os.Exit(cli.Run(tools, settings, pins, rollbacks, history, args))
+	}
+
+	// Dashboard flags
+	fs := flag.NewFlagSet("spark", flag.ExitOnError)
+	refresh := fs.Bool("refresh", false, "ignore cached versions and query the package managers again")
+	manifestPath := fs.String("manifest", "", "team manifest for the drift view (default ./"+updater.ManifestFile+" when present)")
+	fs.Parse(os.Args[1:])
+
+	var manifest *updater.Manifest
+	path := *manifestPath
+	if path == "" {
+		path = updater.ManifestFile
+	}
+	switch m, err := updater.LoadManifest(path, tools); {
+	case err == nil:
+		manifest = &m
+	case errors.Is(err, updater.ErrNoManifest) && *manifestPath == "":
+		// No spark.lock here, the drift view stays off
+	default:
+		fmt.Fprintln(os.Stderr, "spark: invalid manifest:", err)
+		os.Exit(1)
+	}
+
+	cache := updater.OpenCache(config.CacheFile(), settings.Cache.TTL)
+	if *refresh {
+		cache.Clear()
+	}
+
 	// FORCE LOGGING FOR DEBUGGING
 	f, err := tea.LogToFile("spark_debug.log", "debug")
 	if err != nil {
@@ -28,12 +98,16 @@ func main() {
 		}
 	}()
 
-	p := tea.NewProgram(tui.NewModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	p := tea.NewProgram(tui.NewModel(tools, settings, pins, rollbacks, history, manifest, cache), tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
+	if m, ok := final.(tui.Model); ok {
+		m.SaveCache() // Keep versions detected after updates for the next launch
+	}
 
 	fmt.Println("\n  See you later, Space Cowboy... 🚀")
-	fmt.Println("  Spark sequence complete.\n")
+	fmt.Print("  Spark sequence complete.\n\n")
 }
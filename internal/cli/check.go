@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/report"
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// runCheck detects local and latest versions for the selected tools
+func (a *App) runCheck(args []string) int {
+	fs := a.newFlagSet("check")
+	format := fs.String("format", "text", "output format: text or json")
+	refresh := fs.Bool("refresh", false, "ignore cached versions")
+	project := fs.Bool("project", false, "check the requirements of the .spark.toml that applies here")
+	quiet := fs.Bool("quiet", false, "with --project, print only a warning when requirements are unmet")
+	selectors, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(a.Stderr, "spark: unknown format %q (want text or json)\n", *format)
+		return ExitUsage
+	}
+	if *project {
+		if *format != "text" {
+			fmt.Fprintln(a.Stderr, "spark: --project only supports --format text")
+			return ExitUsage
+		}
+		return a.checkProject(selectors, *refresh, *quiet)
+	}
+	if *quiet {
+		fmt.Fprintln(a.Stderr, "spark: --quiet needs --project")
+		return ExitUsage
+	}
+
+	tools, err := selectTools(a.Tools, selectors)
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitUsage
+	}
+
+	states := checkTools(a.newDetector(*refresh), a.Pins, tools)
+
+	outdated := 0
+	for _, s := range states {
+		if s.Status == core.StatusOutdated {
+			outdated++
+		}
+	}
+
+	if *format == "json" {
+		if err := report.Write(a.Stdout, states); err != nil {
+			fmt.Fprintln(a.Stderr, "spark:", err)
+			return ExitFailure
+		}
+	} else {
+		a.printCheckTable(states)
+		if outdated > 0 {
+			fmt.Fprintf(a.Stderr, "\n%d update(s) available\n", outdated)
+		}
+		shadowed := 0
+		for _, s := range states {
+			if s.Shadowed != "" {
+				shadowed++
+			}
+		}
+		if shadowed > 0 {
+			fmt.Fprintf(a.Stderr, "%d tool(s) shadowed (⇅) by another copy earlier in PATH, see `spark which`\n", shadowed)
+		}
+	}
+
+	if outdated > 0 {
+		return ExitUpdatesAvailable
+	}
+	return ExitOK
+}
+
+// printCheckTable renders check results as an aligned table
+func (a *App) printCheckTable(states []core.ToolState) {
+	w := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tLOCAL\tLATEST\tSTATUS\tOWNER\tPIN")
+	for _, s := range states {
+		pin := ""
+		if s.Pin != "" {
+			pin = "🔒 " + s.Pin
+		}
+		owner := s.Owner
+		if !updater.OwnerMatches(s.Tool, updater.Owner(s.Owner)) {
+			owner = "⚠ " + owner // Upgrades are redirected or refused, see updater.Redirect
+		}
+		if s.Shadowed != "" {
+			owner += " ⇅" // Hides the managed copy, see spark which
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.Tool.ID, s.Tool.Name, s.LocalVersion, s.RemoteVersion, s.Status, owner, pin)
+	}
+	w.Flush()
+}
+
+// checkTools runs local detection for every tool in parallel while the
+// remote caches warm up, then classifies each tool the same way the TUI does,
+// honoring pins.
+func checkTools(d *updater.Detector, pins updater.Pins, tools []core.Tool) []core.ToolState {
+	states := make([]core.ToolState, len(tools))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.WarmUpCache()
+	}()
+
+	for i, t := range tools {
+		wg.Add(1)
+		go func(i int, t core.Tool) {
+			defer wg.Done()
+			local, source := d.DetectLocal(t)
+			p, shadowed := d.DetectOwnership(t)
+			states[i] = core.ToolState{Tool: t, LocalVersion: local, LocalSource: source, Pin: pins.Spec(t),
+				Owner: string(p.Owner), BinaryPath: p.Resolved, Shadowed: shadowed}
+		}(i, t)
+	}
+	wg.Wait()
+
+	// Registry lookups go over the network, so these run in parallel too
+	for i := range states {
+		wg.Add(1)
+		go func(s *core.ToolState) {
+			defer wg.Done()
+			s.RemoteVersion, s.RemoteSource = d.DetectRemote(s.Tool, s.LocalVersion)
+			s.Status, s.Message = pins.Classify(s.Tool, s.LocalVersion, s.RemoteVersion)
+		}(&states[i])
+	}
+	wg.Wait()
+	d.SaveCache() // Best effort, like the warm-up
+	return states
+}
@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/config"
+	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// Exit codes returned by the headless subcommands
+const (
+	ExitOK               = 0 // Everything up to date / all updates succeeded
+	ExitFailure          = 1 // A check or update failed
+	ExitUsage            = 2 // Bad arguments or unknown tool selector
+	ExitUpdatesAvailable = 3 // `spark check` found outdated tools
+	ExitDrift            = 3 // `spark sync` found tools that do not match the manifest
+	ExitUnmet            = 3 // `spark check --project` found unmet requirements
+)
+
+// App bundles what every subcommand needs
+type App struct {
+	Tools     []core.Tool
+	Settings  config.Settings
+	Pins      updater.Pins
+	Rollbacks *updater.Rollbacks
+	History   *updater.History
+	Runner    updater.CommandRunner // Runs the package managers; nil runs the real commands
+	Stdout    io.Writer
+	Stderr    io.Writer
+}
+
+// IsCommand reports whether args select a headless subcommand instead of the TUI.
+// Leading flags (other than help) are left for the dashboard.
+func IsCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "-h", "--help":
+		return true
+	}
+	return !strings.HasPrefix(args[0], "-")
+}
+
+// Run executes the subcommand in args (without the program name) and returns the exit code
+func Run(tools []core.Tool, settings config.Settings, pins updater.Pins, rollbacks *updater.Rollbacks, history *updater.History, args []string) int {
+	app := &App{
+		Tools:     tools,
+		Settings:  settings,
+		Pins:      pins,
+		Rollbacks: rollbacks,
+		History:   history,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+	}
+	return app.Run(args)
+}
+
+// Run dispatches to the subcommand named by args[0]
+func (a *App) Run(args []string) int {
+	if len(args) == 0 {
+		a.usage()
+		return ExitUsage
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		return a.runList(args[1:])
+	case "check":
+		return a.runCheck(args[1:])
+	case "update", "upgrade":
+		return a.runUpdate(args[1:])
+	case "rollback":
+		return a.runRollback(args[1:])
+	case "history":
+		return a.runHistory(args[1:])
+	case "export":
+		return a.runExport(args[1:])
+	case "sync":
+		return a.runSync(args[1:])
+	case "which":
+		return a.runWhich(args[1:])
+	case "help", "-h", "--help":
+		a.usage()
+		return ExitOK
+	default:
+		fmt.Fprintf(a.Stderr, "spark: unknown command %q\n\n", args[0])
+		a.usage()
+		return ExitUsage
+	}
+}
+
+func (a *App) usage() {
+	fmt.Fprint(a.Stderr, `Usage:
+  spark                         Launch the interactive dashboard
+  spark list [selectors...]     List the tool inventory
+  spark check [selectors...]    Check installed and latest versions
+  spark update [flags] [selectors...]
+                                Update the selected tools
+  spark rollback [flags] [selectors...]
+                                Restore the version the selected tools had
+                                before their last update; lists what can be
+                                rolled back without selectors
+  spark history [flags] [selectors...]
+                                Show past update attempts
+  spark export [flags] [selectors...]
+                                Write the installed versions to spark.lock
+  spark sync [flags] [selectors...]
+                                Compare the tools with spark.lock and plan
+                                the installs, upgrades and downgrades needed
+  spark which [selectors...]    List every copy of the tools' binaries with its
+                                version and owner; without selectors, only
+                                tools installed more than once
+
+Selectors match a tool ID (S-07), key or binary (claude) or a category (CODE).
+Tools pinned in config.toml ([pins]) are only updated within their pin.
+
+Check flags:
+  --format     Output format: text (default) or json
+  --refresh    Ignore cached versions and query the package managers again
+  --project    Check the requirements of the nearest .spark.toml instead
+               (installed versions only, no network)
+  --quiet      With --project, print one warning line when requirements are unmet
+
+Update flags:
+  --outdated   Only update tools that have a newer version available (pins honored)
+  --yes        Allow updating critical runtimes (RUNTIME category)
+  --jobs N     Updates to run at once (default from config.toml, 3)
+  --refresh    Ignore cached versions and query the package managers again
+  --dry-run    Print the exact commands each update would run, then exit
+
+Rollback flags:
+  --yes        Allow rolling back critical runtimes (RUNTIME category)
+
+History flags:
+  --since      Only attempts on or after a date (2006-01-02) or within a duration (7d, 12h)
+  --until      Only attempts up to and including a date (2006-01-02)
+  --format     Output format: text (default) or json (one entry per line)
+  --output     Also print the captured command output of each attempt
+
+Export flags:
+  -o FILE      File to write (default spark.lock, - for stdout)
+  --refresh    Ignore cached versions and query the package managers again
+
+Sync flags:
+  --manifest   Manifest to compare against (default spark.lock)
+  --apply      Run the installs and upgrades of the plan; downgrades stay manual
+  --yes        Allow installing or updating critical runtimes (RUNTIME category)
+  --jobs N     Updates to run at once (default from config.toml, 3)
+  --refresh    Ignore cached versions and query the package managers again
+
+Exit codes:
+  0  Up to date / all updates or rollbacks succeeded
+  1  A check, update or rollback failed
+  2  Usage error
+  3  Updates available (spark check) / requirements unmet (spark check --project) /
+     tools drift from the manifest (spark sync)
+`)
+}
+
+// parseFlags parses flags that may appear before or after positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// newFlagSet creates a FlagSet that reports errors to the app's stderr
+func (a *App) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("spark "+name, flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	return fs
+}
+
+// newDetector returns a Detector backed by the on-disk version cache and the
+// configured package registries.
+// refresh ignores what is cached but still saves the new results.
+func (a *App) newDetector(refresh bool) *updater.Detector {
+	cache := updater.OpenCache(config.CacheFile(), a.Settings.Cache.TTL)
+	if refresh {
+		cache.Clear()
+	}
+	d := updater.NewDetectorWithRunner(a.runner())
+	d.UseCache(cache)
+	d.UseRegistries(updater.NewRegistries(updater.RegistryURLs(a.Settings.Registries)))
+	return d
+}
+
+// newExecutor returns an Executor running commands through the app's runner
+func (a *App) newExecutor() *updater.Executor {
+	return updater.NewExecutorWithRunner(a.runner())
+}
+
+func (a *App) runner() updater.CommandRunner {
+	if a.Runner == nil {
+		return updater.ExecRunner{}
+	}
+	return a.Runner
+}
+
+// selectTools resolves selectors to tools, keeping inventory order.
+// No selectors selects the whole inventory.
+func selectTools(tools []core.Tool, selectors []string) ([]core.Tool, error) {
+	if len(selectors) == 0 {
+		return tools, nil
+	}
+
+	picked := make(map[string]bool)
+	for _, sel := range selectors {
+		matched := false
+		for _, t := range tools {
+			if matchesSelector(t, sel) {
+				picked[t.ID] = true
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no tool matches %q", sel)
+		}
+	}
+
+	var selected []core.Tool
+	for _, t := range tools {
+		if picked[t.ID] {
+			selected = append(selected, t)
+		}
+	}
+	return selected, nil
+}
+
+func matchesSelector(t core.Tool, sel string) bool {
+	return strings.EqualFold(t.ID, sel) ||
+		t.Key == sel ||
+		t.Binary == sel ||
+		strings.EqualFold(string(t.Category), sel)
+}
@@ -0,0 +1,294 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/config"
+	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/updater"
+	"github.com/dpeluche/spark/internal/updater/updatertest"
+)
+
+// testTools is a small Homebrew inventory: jq and node are outdated, fzf is
+// current, bat is missing and rg comes from the system instead of brew
+var testTools = []core.Tool{
+	{ID: "S-01", Key: "jq", Name: "jq", Binary: "jq", Package: "jq", Method: core.MethodBrew, Category: core.CategoryUtils},
+	{ID: "S-02", Key: "fzf", Name: "fzf", Binary: "fzf", Package: "fzf", Method: core.MethodBrew, Category: core.CategoryUtils},
+	{ID: "S-03", Key: "node", Name: "Node.js", Binary: "node", Package: "node", Method: core.MethodBrew, Category: core.CategoryRuntime},
+	{ID: "S-04", Key: "bat", Name: "bat", Binary: "bat", Package: "bat", Method: core.MethodBrew, Category: core.CategoryUtils},
+	{ID: "S-05", Key: "rg", Name: "ripgrep", Binary: "rg", Package: "ripgrep", Method: core.MethodBrew, Category: core.CategoryInfra},
+}
+
+const testBrewOutdated = `{"formulae": [
+	{"name": "jq", "installed_versions": ["1.7.1"], "current_version": "1.8.0"},
+	{"name": "node", "installed_versions": ["22.1.0"], "current_version": "22.2.0"},
+	{"name": "ripgrep", "installed_versions": ["14.1.0"], "current_version": "14.1.1"}
+], "casks": []}`
+
+// newTestRunner answers the detection commands for testTools
+func newTestRunner() *updatertest.FakeRunner {
+	return updatertest.NewFakeRunner().
+		OnPath("brew", "/opt/homebrew/bin/brew").
+		OnPath("jq", "/opt/homebrew/bin/jq").
+		OnPath("fzf", "/opt/homebrew/bin/fzf").
+		OnPath("node", "/opt/homebrew/bin/node").
+		OnPath("rg", "/usr/bin/rg").
+		On("jq --version", updatertest.Stdout("jq-1.7.1\n")).
+		On("fzf --version", updatertest.Stdout("0.46.0\n")).
+		On("node --version", updatertest.Stdout("v22.1.0\n")).
+		On("rg --version", updatertest.Stdout("ripgrep 14.1.0\n")).
+		On("brew outdated --json=v2", updatertest.Stdout(testBrewOutdated)).
+		On("brew list --versions jq", updatertest.Stdout("jq 1.7.1\n")).
+		On("brew list --versions node", updatertest.Stdout("node 22.1.0\n"))
+}
+
+// newTestApp returns an App over testTools running commands through r, with
+// HOME (and so the version cache) in a temporary directory
+func newTestApp(t *testing.T, r *updatertest.FakeRunner) (a *App, stdout, stderr *bytes.Buffer) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	settings := config.Defaults()
+	settings.Registries = config.RegistrySettings{} // No network
+	stdout, stderr = new(bytes.Buffer), new(bytes.Buffer)
+	return &App{
+		Tools:     testTools,
+		Settings:  settings,
+		Rollbacks: updater.NewRollbacks(filepath.Join(home, "rollbacks.json")),
+		History:   updater.NewHistory(filepath.Join(home, "history.jsonl")),
+		Runner:    r,
+		Stdout:    stdout,
+		Stderr:    stderr,
+	}, stdout, stderr
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout []string // Substrings of stdout
+		skipStdout []string // Not in stdout
+		wantStderr string
+	}{
+		// Usage errors
+		{name: "no command", args: nil, wantCode: ExitUsage, wantStderr: "Usage:"},
+		{name: "unknown command", args: []string{"frobnicate"}, wantCode: ExitUsage, wantStderr: `unknown command "frobnicate"`},
+		{name: "help", args: []string{"help"}, wantCode: ExitOK, wantStderr: "Exit codes:"},
+		{name: "unknown flag", args: []string{"check", "--bogus"}, wantCode: ExitUsage, wantStderr: "flag provided but not defined: -bogus"},
+		{name: "unknown format", args: []string{"check", "--format", "xml"}, wantCode: ExitUsage, wantStderr: `unknown format "xml"`},
+		{name: "unknown selector", args: []string{"check", "nosuch"}, wantCode: ExitUsage, wantStderr: `no tool matches "nosuch"`},
+		{name: "update without selectors", args: []string{"update"}, wantCode: ExitUsage, wantStderr: "update needs tool selectors or --outdated"},
+		{name: "update with no jobs", args: []string{"update", "--jobs", "0", "jq"}, wantCode: ExitUsage, wantStderr: "--jobs must be at least 1"},
+
+		// Listing
+		{
+			name:       "list",
+			args:       []string{"list"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"ID", "PACKAGE", "S-01", "S-05", "ripgrep"},
+		},
+		{
+			name:       "ls by category",
+			args:       []string{"ls", "runtime"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"S-03", "Node.js"},
+			skipStdout: []string{"S-01", "S-05"},
+		},
+
+		// Selectors: ID, key, binary and category
+		{
+			name:       "check by ID",
+			args:       []string{"check", "S-02"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"S-02  fzf"},
+			skipStdout: []string{"S-01", "S-03"},
+		},
+		{
+			name:       "check by key",
+			args:       []string{"check", "fzf"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"0.46.0"},
+			skipStdout: []string{"S-01"},
+		},
+		{
+			name:       "check by binary",
+			args:       []string{"check", "rg"},
+			wantCode:   ExitUpdatesAvailable,
+			wantStdout: []string{"S-05", "14.1.0", "14.1.1"},
+			skipStdout: []string{"S-01"},
+		},
+		{
+			name:       "check by category",
+			args:       []string{"check", "utils"},
+			wantCode:   ExitUpdatesAvailable,
+			wantStdout: []string{"S-01", "S-02", "S-04"},
+			skipStdout: []string{"S-03", "S-05"},
+			wantStderr: "1 update(s) available",
+		},
+		{
+			name:       "check everything",
+			args:       []string{"check"},
+			wantCode:   ExitUpdatesAvailable,
+			wantStdout: []string{"1.7.1", "1.8.0", "MISSING"},
+			wantStderr: "3 update(s) available",
+		},
+		{
+			name:       "check as JSON",
+			args:       []string{"check", "--format", "json", "jq"},
+			wantCode:   ExitUpdatesAvailable,
+			wantStdout: []string{`"key": "jq"`, `"remote_version": "1.8.0"`, `"outdated": 1`},
+			skipStdout: []string{"fzf"},
+		},
+
+		// Updates
+		{
+			name:       "missing tools are skipped",
+			args:       []string{"update", "bat"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"bat (S-04): not installed, skipping", "Nothing to update."},
+		},
+		{
+			name:       "refused upgrades fail",
+			args:       []string{"update", "rg"},
+			wantCode:   ExitFailure,
+			wantStdout: []string{"ripgrep (S-05): wrong package manager", "Nothing to update."},
+		},
+		{
+			name:       "dry run",
+			args:       []string{"update", "jq", "--dry-run"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"[1/1] upgrade jq (S-01) via brew: 1.7.1 → 1.8.0", "$ brew upgrade jq"},
+		},
+		{
+			name:       "upgrade is update",
+			args:       []string{"upgrade", "S-01", "--dry-run"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"$ brew upgrade jq"},
+		},
+		{
+			name:       "outdated holds back runtimes",
+			args:       []string{"update", "--outdated", "--dry-run", "jq", "node"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"Node.js (S-03): critical runtime, pass --yes to update", "$ brew upgrade jq"},
+			skipStdout: []string{"fzf", "brew upgrade node"},
+		},
+		{
+			name:       "yes allows runtimes",
+			args:       []string{"update", "--outdated", "--yes", "--dry-run", "jq", "node"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"[1/2]", "$ brew upgrade jq", "[2/2]", "$ brew upgrade node"},
+		},
+		{
+			name:       "dry run exits like the real run",
+			args:       []string{"update", "--outdated", "--dry-run"},
+			wantCode:   ExitFailure,
+			wantStdout: []string{"$ brew upgrade jq", "ripgrep (S-05): wrong package manager"},
+			skipStdout: []string{"brew upgrade node", "brew upgrade ripgrep"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, stdout, stderr := newTestApp(t, newTestRunner())
+			if code := a.Run(tt.args); code != tt.wantCode {
+				t.Errorf("Run(%q) = %d, want %d\nstdout:\n%s\nstderr:\n%s", tt.args, code, tt.wantCode, stdout, stderr)
+			}
+			for _, want := range tt.wantStdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout does not contain %q:\n%s", want, stdout)
+				}
+			}
+			for _, skip := range tt.skipStdout {
+				if strings.Contains(stdout.String(), skip) {
+					t.Errorf("stdout contains %q:\n%s", skip, stdout)
+				}
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr does not contain %q:\n%s", tt.wantStderr, stderr)
+			}
+		})
+	}
+}
+
+func TestRunListRunsNothing(t *testing.T) {
+	r := newTestRunner()
+	a, _, _ := newTestApp(t, r)
+	if code := a.Run([]string{"list"}); code != ExitOK {
+		t.Fatalf("Run(list) = %d, want %d", code, ExitOK)
+	}
+	if calls := r.Calls(); len(calls) != 0 {
+		t.Errorf("list ran %q, want nothing", calls)
+	}
+}
+
+func TestRunUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		upgrades   map[string]updater.Result
+		wantCode   int
+		wantStdout []string
+		wantSaved  []string // Tools whose rollback was saved
+	}{
+		{
+			name:       "all succeed",
+			args:       []string{"update", "--outdated", "--yes", "--jobs", "2", "jq", "node"},
+			upgrades:   map[string]updater.Result{"brew upgrade jq": updatertest.Stdout(""), "brew upgrade node": updatertest.Stdout("")},
+			wantCode:   ExitOK,
+			wantStdout: []string{"✔ jq: updated to 1.8.0", "✔ Node.js: updated to 22.2.0", "Successful: 2  |  Failed: 0"},
+			wantSaved:  []string{"jq", "node"},
+		},
+		{
+			name:       "one fails",
+			args:       []string{"update", "--yes", "jq", "node"},
+			upgrades:   map[string]updater.Result{"brew upgrade jq": updatertest.Stdout(""), "brew upgrade node": updatertest.Failure(1, "Error: node: no bottle available")},
+			wantCode:   ExitFailure,
+			wantStdout: []string{"✔ jq: updated to 1.8.0", "✘ Node.js: failed", "Successful: 1  |  Failed: 1"},
+			wantSaved:  []string{"jq"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Versions seen after the first check
+			r := newTestRunner().
+				On("jq --version", updatertest.Stdout("jq-1.8.0\n")).
+				On("node --version", updatertest.Stdout("v22.2.0\n"))
+			for cmd, res := range tt.upgrades {
+				r.On(cmd, res)
+			}
+			a, stdout, stderr := newTestApp(t, r)
+			if code := a.Run(tt.args); code != tt.wantCode {
+				t.Errorf("Run(%q) = %d, want %d\nstdout:\n%s\nstderr:\n%s", tt.args, code, tt.wantCode, stdout, stderr)
+			}
+			for _, want := range tt.wantStdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout does not contain %q:\n%s", want, stdout)
+				}
+			}
+
+			// Each upgrade ran once; only those that succeeded replaced the way back
+			calls := strings.Join(r.Calls(), "\n")
+			for cmd := range tt.upgrades {
+				if strings.Count(calls, cmd) != 1 {
+					t.Errorf("%q ran %d times, want once", cmd, strings.Count(calls, cmd))
+				}
+			}
+			var saved []string
+			for _, rb := range a.Rollbacks.List() {
+				saved = append(saved, rb.Key)
+			}
+			sort.Strings(saved)
+			if !reflect.DeepEqual(saved, tt.wantSaved) {
+				t.Errorf("rollbacks saved for %q, want %q", saved, tt.wantSaved)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// runExport writes a manifest of the installed versions of the selected tools
+func (a *App) runExport(args []string) int {
+	fs := a.newFlagSet("export")
+	output := fs.String("o", updater.ManifestFile, "file to write, - for stdout")
+	refresh := fs.Bool("refresh", false, "ignore cached versions")
+	selectors, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+
+	tools, err := selectTools(a.Tools, selectors)
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitUsage
+	}
+
+	d := a.newDetector(*refresh)
+	states := detectLocal(d, tools)
+	d.SaveCache()
+
+	var buf bytes.Buffer
+	if err := updater.WriteManifest(&buf, states); err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitFailure
+	}
+	if *output == "-" {
+		a.Stdout.Write(buf.Bytes())
+		return ExitOK
+	}
+	if err := os.WriteFile(*output, buf.Bytes(), 0o644); err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitFailure
+	}
+
+	installed := 0
+	for _, s := range states {
+		if s.LocalVersion != "MISSING" {
+			installed++
+		}
+	}
+	fmt.Fprintf(a.Stdout, "Wrote %d tool(s) to %s\n", installed, *output)
+	return ExitOK
+}
+
+// detectLocal detects the installed version of every tool in parallel;
+// unlike checkTools it never looks up the latest releases
+func detectLocal(d *updater.Detector, tools []core.Tool) []core.ToolState {
+	states := make([]core.ToolState, len(tools))
+	var wg sync.WaitGroup
+	for i, t := range tools {
+		wg.Add(1)
+		go func(i int, t core.Tool) {
+			defer wg.Done()
+			local, source := d.DetectLocal(t)
+			states[i] = core.ToolState{Tool: t, LocalVersion: local, LocalSource: source}
+		}(i, t)
+	}
+	wg.Wait()
+	return states
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// historyDate is how dates are given to --since/--until and shown in the table
+const historyDate = "2006-01-02"
+
+// runHistory prints the recorded update attempts, oldest first
+func (a *App) runHistory(args []string) int {
+	fs := a.newFlagSet("history")
+	since := fs.String("since", "", "only attempts on or after a date or within a duration")
+	until := fs.String("until", "", "only attempts up to and including a date")
+	format := fs.String("format", "text", "output format: text or json")
+	withOutput := fs.Bool("output", false, "print the captured command output")
+	selectors, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(a.Stderr, "spark: unknown format %q (want text or json)\n", *format)
+		return ExitUsage
+	}
+
+	var filter updater.HistoryFilter
+	now := time.Now()
+	if *since != "" {
+		if filter.Since, err = parseSince(*since, now); err != nil {
+			fmt.Fprintln(a.Stderr, "spark: --since:", err)
+			return ExitUsage
+		}
+	}
+	if *until != "" {
+		day, err := time.ParseInLocation(historyDate, *until, time.Local)
+		if err != nil {
+			fmt.Fprintf(a.Stderr, "spark: --until: %q is not a date (want %s)\n", *until, historyDate)
+			return ExitUsage
+		}
+		filter.Until = day.AddDate(0, 0, 1)
+	}
+	if len(selectors) > 0 {
+		tools, err := selectTools(a.Tools, selectors)
+		if err != nil {
+			fmt.Fprintln(a.Stderr, "spark:", err)
+			return ExitUsage
+		}
+		for _, t := range tools {
+			filter.Keys = append(filter.Keys, t.Key)
+		}
+	}
+
+	entries, err := a.History.Read(filter)
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitFailure
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(a.Stdout)
+		for _, e := range entries {
+			if !*withOutput {
+				e.Output = ""
+			}
+			if err := enc.Encode(e); err != nil {
+				fmt.Fprintln(a.Stderr, "spark:", err)
+				return ExitFailure
+			}
+		}
+		return ExitOK
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(a.Stdout, "No update attempts recorded.")
+		return ExitOK
+	}
+	if *withOutput {
+		a.printHistoryLog(entries)
+	} else {
+		a.printHistoryTable(entries)
+	}
+	return ExitOK
+}
+
+// parseSince accepts a date or a duration back from now ("7d", "12h")
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if day, err := time.ParseInLocation(historyDate, s, time.Local); err == nil {
+		return day, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is neither a date (%s) nor a duration (7d, 12h)", s, historyDate)
+}
+
+func (a *App) printHistoryTable(entries []updater.HistoryEntry) {
+	w := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tID\tNAME\tACTION\tFROM\tTO\tDURATION\tRESULT")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format(historyDate+" 15:04"),
+			e.ToolID, e.Name, e.Action, e.From, e.To, e.Duration().Round(time.Second), historyResult(e))
+	}
+	w.Flush()
+}
+
+// printHistoryLog prints each attempt followed by its captured output
+func (a *App) printHistoryLog(entries []updater.HistoryEntry) {
+	for i, e := range entries {
+		if i > 0 {
+			fmt.Fprintln(a.Stdout)
+		}
+		versions := e.From
+		if e.To != "" {
+			versions += " → " + e.To
+		}
+		fmt.Fprintf(a.Stdout, "%s  %s (%s)  %s %s  %s  %s\n", e.Time.Local().Format(historyDate+" 15:04:05"),
+			e.Name, e.ToolID, e.Action, versions, e.Duration().Round(time.Second), historyResult(e))
+		for _, line := range strings.Split(e.Output, "\n") {
+			if line != "" {
+				fmt.Fprintln(a.Stdout, "    "+line)
+			}
+		}
+	}
+}
+
+// historyResult is the outcome with the first line of a failure's message
+func historyResult(e updater.HistoryEntry) string {
+	if e.Success || e.Message == "" {
+		return e.Outcome()
+	}
+	message, _, _ := strings.Cut(e.Message, "\n")
+	if len(message) > 80 {
+		message = message[:79] + "…"
+	}
+	return e.Outcome() + ": " + message
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+)
+
+// runList prints the inventory without touching the system
+func (a *App) runList(args []string) int {
+	fs := a.newFlagSet("list")
+	selectors, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+
+	tools, err := selectTools(a.Tools, selectors)
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitUsage
+	}
+
+	w := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tCATEGORY\tMETHOD\tBINARY\tPACKAGE")
+	for _, t := range tools {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.Name, t.Category, t.Method, t.Binary, t.Package)
+	}
+	w.Flush()
+	return ExitOK
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// checkProject checks the installed versions against the .spark.toml that
+// applies in the working directory. Only local versions are detected, so it
+// is quick enough for a shell hook; quiet prints a single warning line, and
+// nothing when every requirement is met.
+func (a *App) checkProject(selectors []string, refresh, quiet bool) int {
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitFailure
+	}
+	path, err := updater.FindProject(wd)
+	if errors.Is(err, updater.ErrNoProject) {
+		fmt.Fprintf(a.Stderr, "spark: no %s in this directory or its parents (up to the repository root)\n", updater.ProjectFile)
+		return ExitUsage
+	}
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitFailure
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil {
+		path = rel
+	}
+
+	project, err := updater.LoadProject(path, a.Tools)
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitUsage
+	}
+
+	tools, err := selectTools(a.Tools, selectors)
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitUsage
+	}
+	var listed []core.Tool
+	for _, t := range tools {
+		if project.Lists(t) {
+			listed = append(listed, t)
+		}
+	}
+
+	d := a.newDetector(refresh)
+	states := detectLocal(d, listed)
+	d.SaveCache()
+	unmet := project.Unmet(states)
+
+	if quiet {
+		if len(unmet) == 0 {
+			return ExitOK
+		}
+		parts := make([]string, len(unmet))
+		for i, s := range unmet {
+			if s.LocalVersion == "MISSING" {
+				parts[i] = s.Tool.Key + " missing"
+			} else {
+				parts[i] = fmt.Sprintf("%s %s (needs %s)", s.Tool.Key, s.LocalVersion, project.Spec(s.Tool))
+			}
+		}
+		fmt.Fprintf(a.Stderr, "spark: %s: %d requirement(s) unmet: %s\n", path, len(unmet), strings.Join(parts, ", "))
+		return ExitUnmet
+	}
+
+	failing := make(map[string]bool, len(unmet))
+	for _, s := range unmet {
+		failing[s.Tool.Key] = true
+	}
+	w := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tLOCAL\tREQUIRED\tSTATUS")
+	for _, s := range states {
+		status := "ok"
+		switch {
+		case s.LocalVersion == "MISSING":
+			status = "missing"
+		case failing[s.Tool.Key]:
+			status = "unmet"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Tool.ID, s.Tool.Name, s.LocalVersion, project.Spec(s.Tool), status)
+	}
+	w.Flush()
+
+	if len(unmet) > 0 {
+		fmt.Fprintf(a.Stderr, "\n%d requirement(s) of %s unmet\n", len(unmet), path)
+		return ExitUnmet
+	}
+	return ExitOK
+}
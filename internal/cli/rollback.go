@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// runRollback restores the versions recorded before the selected tools' last
+// update. Without selectors it lists the recorded rollbacks.
+func (a *App) runRollback(args []string) int {
+	fs := a.newFlagSet("rollback")
+	allowRuntime := fs.Bool("yes", false, "allow rolling back critical runtimes")
+	selectors, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+
+	if len(selectors) == 0 {
+		return a.listRollbacks()
+	}
+
+	tools, err := selectTools(a.Tools, selectors)
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitUsage
+	}
+
+	var queue []core.Tool
+	for _, t := range tools {
+		switch _, ok := a.Rollbacks.Get(t.Key); {
+		case !ok:
+			fmt.Fprintf(a.Stdout, "○ %s (%s): no rollback recorded, skipping\n", t.Name, t.ID)
+		case t.Category == core.CategoryRuntime && !*allowRuntime:
+			fmt.Fprintf(a.Stdout, "⚠ %s (%s): critical runtime, pass --yes to roll back\n", t.Name, t.ID)
+		default:
+			queue = append(queue, t)
+		}
+	}
+
+	if len(queue) == 0 {
+		fmt.Fprintln(a.Stdout, "Nothing to roll back.")
+		return ExitOK
+	}
+
+	// Ctrl+C stops the running command's process group and skips the rest
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	executor := a.newExecutor()
+	executor.UseRollbacks(a.Rollbacks)
+	detector := a.newDetector(false)
+
+	// Rollbacks are rare and often share a package manager, so they run one at a time
+	succeeded, failed := 0, 0
+	for i, t := range queue {
+		if ctx.Err() != nil {
+			break
+		}
+		rb, _ := a.Rollbacks.Get(t.Key)
+		fmt.Fprintf(a.Stdout, "[%d/%d] Rolling back %s (%s) to %s: %s\n", i+1, len(queue), t.Name, t.ID, rb.Version, rb)
+		from, start := detector.GetLocalVersion(t), time.Now()
+
+		var output []updater.OutputLine
+		err := executor.ApplyStreaming(ctx, updater.ActionRollback, t, func(line updater.OutputLine) {
+			output = append(output, line)
+		})
+		var to string
+		if err == nil {
+			to = detector.GetLocalVersion(t)
+		}
+		if err := a.History.Append(newHistoryEntry(t, updater.ActionRollback, from, to, start, err, output)); err != nil {
+			fmt.Fprintf(a.Stdout, "  ⚠ %s: could not write the update history: %v\n", t.Name, err)
+		}
+
+		switch {
+		case errors.Is(err, updater.ErrAborted):
+			fmt.Fprintf(a.Stdout, "  ⊘ %s: aborted\n", t.Name)
+		case err != nil:
+			failed++
+			fmt.Fprintf(a.Stdout, "  ✘ %s: failed: %v\n", t.Name, err)
+		default:
+			succeeded++
+			fmt.Fprintf(a.Stdout, "  ✔ %s: rolled back to %s\n", t.Name, rb.Version)
+		}
+	}
+
+	detector.SaveCache() // Remember the restored local versions
+	fmt.Fprintf(a.Stdout, "\nSuccessful: %d  |  Failed: %d", succeeded, failed)
+	if skipped := len(queue) - succeeded - failed; skipped > 0 {
+		fmt.Fprintf(a.Stdout, "  |  Aborted or skipped: %d", skipped)
+	}
+	fmt.Fprintln(a.Stdout)
+	if succeeded < len(queue) {
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+// listRollbacks prints the recorded rollbacks, most recent first
+func (a *App) listRollbacks() int {
+	list := a.Rollbacks.List()
+	if len(list) == 0 {
+		fmt.Fprintln(a.Stdout, "No rollbacks recorded. Spark records one before each update.")
+		return ExitOK
+	}
+
+	ids := make(map[string]string, len(a.Tools))
+	for _, t := range a.Tools {
+		ids[t.Key] = t.ID
+	}
+
+	w := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREVIOUS\tRECORDED\tCOMMAND")
+	for _, rb := range list {
+		id := ids[rb.Key]
+		if id == "" {
+			id = "-" // Removed from tools.toml since
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", id, rb.Name, rb.Version, rb.RecordedAt.Local().Format("2006-01-02 15:04"), rb)
+	}
+	w.Flush()
+	return ExitOK
+}
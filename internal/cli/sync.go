@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// runSync compares the selected tools with a manifest and prints the plan
+// that brings them in line. --apply runs its installs and upgrades.
+func (a *App) runSync(args []string) int {
+	fs := a.newFlagSet("sync")
+	path := fs.String("manifest", updater.ManifestFile, "manifest to compare against")
+	apply := fs.Bool("apply", false, "run the installs and upgrades of the plan")
+	allowRuntime := fs.Bool("yes", false, "allow updating critical runtimes")
+	workers := fs.Int("jobs", a.Settings.Update.Workers, "number of updates to run at once")
+	refresh := fs.Bool("refresh", false, "ignore cached versions")
+	selectors, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+
+	if *workers < 1 {
+		fmt.Fprintln(a.Stderr, "spark: --jobs must be at least 1")
+		return ExitUsage
+	}
+
+	manifest, err := updater.LoadManifest(*path, a.Tools)
+	if errors.Is(err, updater.ErrNoManifest) {
+		fmt.Fprintf(a.Stderr, "spark: %s not found, write one with `spark export`\n", *path)
+		return ExitUsage
+	}
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitUsage
+	}
+
+	tools, err := selectTools(a.Tools, selectors)
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitUsage
+	}
+	var listed []core.Tool
+	for _, t := range tools {
+		if manifest.Lists(t) {
+			listed = append(listed, t)
+		}
+	}
+	if len(listed) == 0 {
+		fmt.Fprintf(a.Stdout, "%s lists none of the selected tools.\n", *path)
+		return ExitOK
+	}
+
+	detector := a.newDetector(*refresh)
+	states := checkTools(detector, a.Pins, listed)
+	plan := manifest.Plan(states)
+	a.printSyncTable(plan)
+
+	drifting := 0
+	for _, d := range plan {
+		if d.Step != updater.SyncOK {
+			drifting++
+		}
+	}
+	if drifting == 0 {
+		fmt.Fprintf(a.Stderr, "\nEverything matches %s\n", *path)
+		return ExitOK
+	}
+	if !*apply {
+		fmt.Fprintf(a.Stderr, "\n%d tool(s) drift from %s\n", drifting, *path)
+		return ExitDrift
+	}
+
+	// Only installs and upgrades can be run; the rest stays for the user
+	fmt.Fprintln(a.Stdout)
+	var jobs []updater.Job
+	from := make(map[string]string) // Versions before the sync, for the history
+	for i, d := range plan {
+		s := states[i]
+		from[s.Tool.Key] = s.LocalVersion
+		var action updater.Action
+		switch d.Step {
+		case updater.SyncInstall:
+			action = updater.ActionInstall
+		case updater.SyncUpgrade:
+			action = updater.ActionUpgrade
+		default:
+			continue
+		}
+		switch {
+		case action == updater.ActionUpgrade && s.Pin != "" && s.Status != core.StatusOutdated:
+			fmt.Fprintf(a.Stdout, "🔒 %s (%s): pinned to %s, skipping\n", s.Tool.Name, s.Tool.ID, s.Pin)
+		case s.Tool.Category == core.CategoryRuntime && !*allowRuntime:
+			fmt.Fprintf(a.Stdout, "⚠ %s (%s): critical runtime, pass --yes to %s\n", s.Tool.Name, s.Tool.ID, action)
+		case action == updater.ActionUpgrade:
+			if t, ok := a.upgradeTarget(s); ok {
+				jobs = append(jobs, updater.Job{Index: len(jobs), Tool: t, Action: action})
+			}
+		default:
+			jobs = append(jobs, updater.Job{Index: len(jobs), Tool: s.Tool, Action: action})
+		}
+	}
+
+	if len(jobs) == 0 {
+		fmt.Fprintln(a.Stdout, "Nothing Spark can install or upgrade.")
+		return ExitDrift
+	}
+	if !a.applyJobs(jobs, *workers, detector, from) {
+		return ExitFailure
+	}
+	if len(jobs) < drifting {
+		return ExitDrift
+	}
+	return ExitOK
+}
+
+// printSyncTable renders a manifest plan, one tool per row
+func (a *App) printSyncTable(plan []updater.Drift) {
+	w := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tLOCAL\tREQUIRED\tLATEST\tPLAN")
+	for _, d := range plan {
+		step := d.Step.String()
+		if d.Reason != "" {
+			step += ": " + d.Reason
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", d.Tool.ID, d.Tool.Name, d.Local, d.Spec, d.Remote, step)
+	}
+	w.Flush()
+}
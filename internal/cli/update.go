@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// runUpdate upgrades the selected tools, running tools that use different
+// package managers concurrently like the dashboard does
+func (a *App) runUpdate(args []string) int {
+	fs := a.newFlagSet("update")
+	onlyOutdated := fs.Bool("outdated", false, "only update tools with a newer version available")
+	allowRuntime := fs.Bool("yes", false, "allow updating critical runtimes")
+	workers := fs.Int("jobs", a.Settings.Update.Workers, "number of updates to run at once")
+	refresh := fs.Bool("refresh", false, "ignore cached versions")
+	dryRun := fs.Bool("dry-run", false, "print the commands each update would run and exit")
+	selectors, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+
+	if *workers < 1 {
+		fmt.Fprintln(a.Stderr, "spark: --jobs must be at least 1")
+		return ExitUsage
+	}
+
+	if len(selectors) == 0 && !*onlyOutdated {
+		fmt.Fprintln(a.Stderr, "spark: update needs tool selectors or --outdated")
+		return ExitUsage
+	}
+
+	tools, err := selectTools(a.Tools, selectors)
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitUsage
+	}
+
+	detector := a.newDetector(*refresh)
+
+	// Drop missing tools (and up-to-date ones with --outdated) before touching anything
+	var queue []core.Tool
+	refused := 0
+	from := make(map[string]string) // Versions before the update, for the history
+	states := make(map[string]core.ToolState)
+	for _, s := range checkTools(detector, a.Pins, tools) {
+		states[s.Tool.Key] = s
+		from[s.Tool.Key] = s.LocalVersion
+		switch {
+		case s.Status == core.StatusMissing:
+			fmt.Fprintf(a.Stdout, "○ %s (%s): not installed, skipping\n", s.Tool.Name, s.Tool.ID)
+		case *onlyOutdated && s.Status != core.StatusOutdated:
+			continue
+		case s.Pin != "" && s.Status != core.StatusOutdated:
+			fmt.Fprintf(a.Stdout, "🔒 %s (%s): pinned to %s, skipping\n", s.Tool.Name, s.Tool.ID, s.Pin)
+		case s.Tool.Category == core.CategoryRuntime && !*allowRuntime:
+			fmt.Fprintf(a.Stdout, "⚠ %s (%s): critical runtime, pass --yes to update\n", s.Tool.Name, s.Tool.ID)
+		default:
+			if t, ok := a.upgradeTarget(s); ok {
+				queue = append(queue, t)
+			} else {
+				refused++
+			}
+		}
+	}
+
+	if len(queue) == 0 {
+		fmt.Fprintln(a.Stdout, "Nothing to update.")
+		if refused > 0 {
+			return ExitFailure
+		}
+		return ExitOK
+	}
+
+	if *dryRun {
+		executor := a.newExecutor()
+		for i, t := range queue {
+			a.printPlan(i+1, len(queue), executor.Plan(updater.ActionUpgrade, states[t.Key]))
+		}
+		if refused > 0 {
+			return ExitFailure // As the real run would
+		}
+		return ExitOK
+	}
+
+	jobs := make([]updater.Job, len(queue))
+	for i, t := range queue {
+		jobs[i] = updater.Job{Index: i, Tool: t}
+	}
+	if a.applyJobs(jobs, *workers, detector, from) && refused == 0 {
+		return ExitOK
+	}
+	return ExitFailure
+}
+
+// upgradeTarget returns the tool an upgrade of s runs through: s.Tool, or
+// the package the binary on PATH really comes from (see updater.Redirect).
+// It prints the redirect or a shadowed copy, or why the upgrade is refused
+// and reports false.
+func (a *App) upgradeTarget(s core.ToolState) (core.Tool, bool) {
+	t, err := updater.Redirect(s)
+	if err != nil {
+		fmt.Fprintf(a.Stdout, "✘ %s (%s): %v, skipping\n", s.Tool.Name, s.Tool.ID, err)
+		return t, false
+	}
+	switch {
+	case t.Method != s.Tool.Method:
+		fmt.Fprintf(a.Stdout, "↪ %s (%s): %s, upgrading %s via %s instead\n", s.Tool.Name, s.Tool.ID, updater.OwnerReason(s), t.Package, t.Method)
+	case s.Shadowed != "":
+		fmt.Fprintf(a.Stdout, "⇅ %s (%s): shadowed, the upgrade changes %s but %s runs first\n", s.Tool.Name, s.Tool.ID, s.Shadowed, s.BinaryPath)
+	}
+	return t, true
+}
+
+// applyJobs runs jobs through a Scheduler, recording a rollback before each
+// upgrade and every attempt in the update history, then prints the totals.
+// It reports whether every job succeeded.
+func (a *App) applyJobs(jobs []updater.Job, workers int, detector *updater.Detector, from map[string]string) bool {
+	// Ctrl+C stops the running commands' process groups and skips the rest of the queue
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	executor := a.newExecutor()
+	executor.UseRollbacks(a.Rollbacks)
+
+	// Workers finish in any order, so each result line names its tool
+	var mu sync.Mutex
+	succeeded, failed, aborted := 0, 0, 0
+	skippedJobs := updater.NewScheduler(workers).Run(ctx, jobs, func(j updater.Job) {
+		t := j.Tool
+		mu.Lock()
+		fmt.Fprintf(a.Stdout, "[%d/%d] %s %s (%s) via %s...\n", j.Index+1, len(jobs), j.Action.Progressive(), t.Name, t.ID, t.Method)
+		mu.Unlock()
+		start := time.Now()
+
+		// Capture the installed version first so `spark rollback` can restore it
+		var pending *updater.PendingRollback
+		if j.Action == updater.ActionUpgrade {
+			var err error
+			if pending, err = executor.RecordRollback(ctx, t); err != nil && !errors.Is(err, updater.ErrNoRollback) {
+				mu.Lock()
+				fmt.Fprintf(a.Stdout, "  ⚠ %s: %v\n", t.Name, err)
+				mu.Unlock()
+			}
+		}
+
+		var output []updater.OutputLine
+		err := executor.ApplyStreaming(ctx, j.Action, t, func(line updater.OutputLine) {
+			output = append(output, line)
+		})
+		var newVer, shadowedBy string
+		if err == nil {
+			newVer = detector.GetLocalVersion(t)
+			if j.Action == updater.ActionUpgrade || j.Action == updater.ActionInstall {
+				// The new version may have landed behind another copy on PATH
+				copies := detector.Installations(t)
+				if _, ok := updater.Shadowed(t, copies); ok {
+					shadowedBy = copies[0].Path
+				}
+			}
+		}
+		_, rollbackErr := pending.Keep(err, from[t.Key], newVer)
+		historyErr := a.History.Append(newHistoryEntry(t, j.Action, from[t.Key], newVer, start, err, output))
+
+		mu.Lock()
+		defer mu.Unlock()
+		if rollbackErr != nil {
+			fmt.Fprintf(a.Stdout, "  ⚠ %s: could not save the rollback: %v\n", t.Name, rollbackErr)
+		}
+		if historyErr != nil {
+			fmt.Fprintf(a.Stdout, "  ⚠ %s: could not write the update history: %v\n", t.Name, historyErr)
+		}
+		switch {
+		case errors.Is(err, updater.ErrAborted):
+			aborted++
+			fmt.Fprintf(a.Stdout, "  ⊘ %s: aborted\n", t.Name)
+		case err != nil:
+			failed++
+			fmt.Fprintf(a.Stdout, "  ✘ %s: failed: %v\n", t.Name, err)
+		case j.Action == updater.ActionInstall:
+			succeeded++
+			fmt.Fprintf(a.Stdout, "  ✔ %s: installed %s (%s)\n", t.Name, newVer, time.Since(start).Round(time.Second))
+		default:
+			succeeded++
+			fmt.Fprintf(a.Stdout, "  ✔ %s: updated to %s (%s)\n", t.Name, newVer, time.Since(start).Round(time.Second))
+		}
+		if shadowedBy != "" {
+			fmt.Fprintf(a.Stdout, "  ⚠ %s: shadowed by %s, which runs first and is %s (see `spark which %s`)\n", t.Name, shadowedBy, newVer, t.Key)
+		}
+	})
+
+	detector.SaveCache() // Remember the new local versions
+	skipped := len(skippedJobs)
+	fmt.Fprintf(a.Stdout, "\nSuccessful: %d  |  Failed: %d", succeeded, failed)
+	if aborted > 0 || skipped > 0 {
+		fmt.Fprintf(a.Stdout, "  |  Aborted: %d  |  Skipped: %d", aborted, skipped)
+	}
+	fmt.Fprintln(a.Stdout)
+	return failed == 0 && aborted == 0 && skipped == 0
+}
+
+// printPlan prints what a dry run would execute: one line per command, in
+// order, with probes (?) and retries (↻) marked and sudo or remote scripts
+// called out
+func (a *App) printPlan(n, total int, p updater.Plan) {
+	versions := p.From
+	if p.Target != "" {
+		versions += " → " + p.Target
+	}
+	fmt.Fprintf(a.Stdout, "[%d/%d] %s %s (%s) via %s: %s\n", n, total, p.Action, p.Tool.Name, p.Tool.ID, p.Tool.Method, versions)
+	if p.Manual != "" {
+		fmt.Fprintf(a.Stdout, "  ✘ %s\n", p.Manual)
+		return
+	}
+	for _, s := range p.Steps {
+		marker := "$"
+		var notes []string
+		switch {
+		case s.Probe:
+			marker = "?"
+			notes = append(notes, "stops here if it fails: "+s.Note)
+		case s.RetryOn != "":
+			marker = "↻"
+			notes = append(notes, "only if the previous command fails with "+s.RetryOn+": "+s.Note)
+		}
+		if s.Privileged {
+			notes = append(notes, "needs sudo")
+		}
+		if s.Remote {
+			notes = append(notes, "downloads and runs a remote script")
+		}
+		line := "  " + marker + " " + s.Shell()
+		if len(notes) > 0 {
+			line += "  # " + strings.Join(notes, "; ")
+		}
+		fmt.Fprintln(a.Stdout, line)
+	}
+}
+
+// newHistoryEntry describes one attempt at applying action to t for the update history
+func newHistoryEntry(t core.Tool, action updater.Action, from, to string, start time.Time, err error, output []updater.OutputLine) updater.HistoryEntry {
+	e := updater.HistoryEntry{
+		Time:       start,
+		ToolID:     t.ID,
+		Key:        t.Key,
+		Name:       t.Name,
+		Method:     string(t.Method),
+		Action:     action.String(),
+		From:       from,
+		To:         to,
+		DurationMS: time.Since(start).Milliseconds(),
+		Success:    err == nil,
+		Aborted:    errors.Is(err, updater.ErrAborted),
+		Output:     updater.TailOutput(output),
+	}
+	if err != nil {
+		e.Message = err.Error()
+	}
+	return e
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// runWhich lists every copy of the selected tools' binaries, on PATH and in
+// the usual install locations, with its version and owner, plus the versions
+// a version manager keeps side by side. Without selectors only tools
+// installed more than once are shown.
+func (a *App) runWhich(args []string) int {
+	fs := a.newFlagSet("which")
+	selectors, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage
+	}
+
+	tools, err := selectTools(a.Tools, selectors)
+	if err != nil {
+		fmt.Fprintln(a.Stderr, "spark:", err)
+		return ExitUsage
+	}
+
+	d := updater.NewDetectorWithRunner(a.runner())
+	copies := make([][]updater.Installation, len(tools))
+	versions := make([][]string, len(tools))
+	managed := make([]string, len(tools))
+	var wg sync.WaitGroup
+	for i, t := range tools {
+		wg.Add(1)
+		go func(i int, t core.Tool) {
+			defer wg.Done()
+			copies[i] = d.Installations(t)
+			if len(copies[i]) < 2 && len(selectors) == 0 {
+				return // Not worth running --version for
+			}
+			for _, c := range copies[i] {
+				versions[i] = append(versions[i], d.InstallationVersion(t, c))
+			}
+			if installed, current, ok := d.ManagedVersions(t); ok && len(installed) > 0 {
+				managed[i] = managedLine(t, installed, current)
+			}
+		}(i, t)
+	}
+	wg.Wait()
+
+	shown, shadowed := 0, 0
+	for i, t := range tools {
+		if len(copies[i]) == 0 || (len(copies[i]) < 2 && len(selectors) == 0) {
+			continue
+		}
+		if shown > 0 {
+			fmt.Fprintln(a.Stdout)
+		}
+		shown++
+		header := fmt.Sprintf("%s %s (%s)", t.ID, t.Name, t.Method)
+		if hidden, ok := updater.Shadowed(t, copies[i]); ok {
+			shadowed++
+			header += ": shadowed, updates go to " + hidden.Path
+		}
+		fmt.Fprintln(a.Stdout, header)
+
+		w := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', 0)
+		for j, c := range copies[i] {
+			marker := " "
+			if c.Active {
+				marker = "*"
+			}
+			owner := string(c.Owner)
+			if owner == "" {
+				owner = "unknown"
+			}
+			path := c.Path
+			if c.Resolved != c.Path {
+				path += " -> " + c.Resolved
+			}
+			fmt.Fprintf(w, "  %s %s\t%s\t%s\n", marker, path, versions[i][j], owner)
+		}
+		w.Flush()
+		if managed[i] != "" {
+			fmt.Fprintln(a.Stdout, managed[i])
+		}
+	}
+
+	switch {
+	case shown == 0 && len(selectors) == 0:
+		fmt.Fprintln(a.Stdout, "Every installed tool has a single copy.")
+	case shown == 0:
+		fmt.Fprintln(a.Stdout, "None of the selected tools is installed.")
+	case shadowed > 0:
+		fmt.Fprintf(a.Stderr, "\n* runs first. %d tool(s) shadowed: the copy Spark updates is not the one on PATH\n", shadowed)
+	}
+	return ExitOK
+}
+
+// managedLine lists the versions a version manager keeps side by side,
+// marking the global one
+func managedLine(t core.Tool, installed []string, current string) string {
+	list := make([]string, len(installed))
+	for i, v := range installed {
+		list[i] = v
+		if v == current {
+			list[i] += " (global)"
+		}
+	}
+	return fmt.Sprintf("  installed via %s: %s", t.Method, strings.Join(list, ", "))
+}
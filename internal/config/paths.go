@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Dir returns Spark's configuration directory ($XDG_CONFIG_HOME/spark or ~/.config/spark)
+func Dir() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "spark")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".config", "spark")
+}
+
+// ToolsFile returns the path of the user inventory overlay
+func ToolsFile() string {
+	return filepath.Join(Dir(), "tools.toml")
+}
+
+// CacheDir returns Spark's cache directory ($XDG_CACHE_HOME/spark or ~/.cache/spark)
+func CacheDir() string {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "spark")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".cache", "spark")
+}
+
+// CacheFile returns the path of the detected versions cache
+func CacheFile() string {
+	return filepath.Join(CacheDir(), "versions.json")
+}
+
+// StateDir returns where Spark keeps records that must survive a cache wipe
+// ($XDG_STATE_HOME/spark or ~/.local/state/spark)
+func StateDir() string {
+	if xdg := os.Getenv("XDG_STATE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "spark")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".local", "state", "spark")
+}
+
+// RollbackFile returns the path of the recorded rollback recipes
+func RollbackFile() string {
+	return filepath.Join(StateDir(), "rollbacks.json")
+}
+
+// HistoryFile returns the path of the append-only update ledger
+func HistoryFile() string {
+	return filepath.Join(StateDir(), "history.jsonl")
+}
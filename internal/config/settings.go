@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Settings are the user preferences from config.toml. Every field has a
+// default, so a missing file is the same as an empty one.
+type Settings struct {
+	Update     UpdateSettings   `toml:"update"`
+	Cache      CacheSettings    `toml:"cache"`
+	Registries RegistrySettings `toml:"registries"`
+
+	// Pins hold tools back, keyed by inventory key: "hold" or a version
+	// constraint such as "16" or "~1.5" (see updater.ParseConstraint)
+	Pins map[string]string `toml:"pins"`
+}
+
+// UpdateSettings control how updates are executed
+type UpdateSettings struct {
+	// Workers is how many tools update at the same time. Tools sharing a
+	// package manager still run one after another.
+	Workers int `toml:"workers"`
+}
+
+// CacheSettings control the on-disk cache of detected versions
+type CacheSettings struct {
+	// TTL is how long fetched outdated lists count as current, e.g. "1h".
+	// Older data is still shown at startup, marked stale, while it refreshes.
+	TTL time.Duration `toml:"ttl"`
+}
+
+// RegistrySettings are the base URLs used to look up latest releases.
+// Point them at a mirror or a local stub; an empty URL disables that registry.
+type RegistrySettings struct {
+	Npm      string `toml:"npm"`
+	PyPI     string `toml:"pypi"`
+	GitHub   string `toml:"github"`
+	Homebrew string `toml:"homebrew"`
+	Crates   string `toml:"crates"`
+	GoProxy  string `toml:"goproxy"` // Any GOPROXY-compatible module proxy
+}
+
+// DefaultWorkers keeps a few package managers busy without flooding the terminal
+const DefaultWorkers = 3
+
+// DefaultCacheTTL skips the slow brew/npm outdated calls for repeated launches
+const DefaultCacheTTL = time.Hour
+
+// Defaults returns the settings used when config.toml does not override them
+func Defaults() Settings {
+	return Settings{
+		Update: UpdateSettings{Workers: DefaultWorkers},
+		Cache:  CacheSettings{TTL: DefaultCacheTTL},
+		Registries: RegistrySettings{
+			Npm:      "https://registry.npmjs.org",
+			PyPI:     "https://pypi.org",
+			GitHub:   "https://api.github.com",
+			Homebrew: "https://formulae.brew.sh",
+			Crates:   "https://crates.io",
+			GoProxy:  "https://proxy.golang.org",
+		},
+	}
+}
+
+// SettingsFile returns the path of the user settings file
+func SettingsFile() string {
+	return filepath.Join(Dir(), "config.toml")
+}
+
+// Load reads settings from path on top of the defaults; a missing file is not an error
+func Load(path string) (Settings, error) {
+	s := Defaults()
+	md, err := toml.DecodeFile(path, &s)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Defaults(), nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, k := range undecoded {
+			keys[i] = k.String()
+		}
+		return s, fmt.Errorf("%s: unknown setting(s): %s", path, strings.Join(keys, ", "))
+	}
+	if s.Update.Workers < 1 {
+		return s, fmt.Errorf("%s: update.workers must be at least 1, got %d", path, s.Update.Workers)
+	}
+	if s.Cache.TTL < 0 {
+		return s, fmt.Errorf("%s: cache.ttl must not be negative, got %s", path, s.Cache.TTL)
+	}
+	for _, r := range []struct{ key, url string }{
+		{"npm", s.Registries.Npm},
+		{"pypi", s.Registries.PyPI},
+		{"github", s.Registries.GitHub},
+		{"homebrew", s.Registries.Homebrew},
+		{"crates", s.Registries.Crates},
+		{"goproxy", s.Registries.GoProxy},
+	} {
+		if u, err := url.Parse(r.url); r.url != "" && (err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "") {
+			return s, fmt.Errorf("%s: registries.%s must be an http(s) URL, got %q", path, r.key, r.url)
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSettings(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "config.toml"))
+	if err != nil {
+		t.Fatalf("Load() of a missing file error = %v", err)
+	}
+	if !reflect.DeepEqual(s, Defaults()) {
+		t.Errorf("Load() of a missing file = %+v, want the defaults", s)
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	path := writeSettings(t, `
+[update]
+workers = 6
+
+[cache]
+ttl = "15m"
+
+[registries]
+npm = "http://localhost:4873"
+github = ""
+
+[pins]
+node = "22"
+`)
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Defaults()
+	want.Update.Workers = 6
+	want.Cache.TTL = 15 * time.Minute
+	want.Registries.Npm = "http://localhost:4873"
+	want.Registries.GitHub = "" // Disabled
+	want.Pins = map[string]string{"node": "22"}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Load() = %+v, want %+v", s, want)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"no workers", "[update]\nworkers = 0\n", "update.workers must be at least 1, got 0"},
+		{"negative workers", "[update]\nworkers = -2\n", "update.workers must be at least 1, got -2"},
+		{"negative ttl", "[cache]\nttl = \"-1h\"\n", "cache.ttl must not be negative, got -1h0m0s"},
+		{"unparsable ttl", "[cache]\nttl = \"soon\"\n", "soon"},
+		{"unknown setting", "[update]\nworkers = 2\nretries = 3\n", "unknown setting(s): update.retries"},
+		{"registry without scheme", "[registries]\nnpm = \"registry.npmjs.org\"\n", `registries.npm must be an http(s) URL, got "registry.npmjs.org"`},
+		{"registry with another scheme", "[registries]\npypi = \"ftp://pypi.org\"\n", `registries.pypi must be an http(s) URL, got "ftp://pypi.org"`},
+		{"registry without host", "[registries]\ngithub = \"https://\"\n", `registries.github must be an http(s) URL, got "https://"`},
+		{"registry that does not parse", "[registries]\nhomebrew = \"http://[::1\"\n", `registries.homebrew must be an http(s) URL`},
+		{"pin that is not a string", "[pins]\nnode = 22\n", "pins.node"},
+		{"crates registry", "[registries]\ncrates = \"crates.io\"\n", `registries.crates must be an http(s) URL, got "crates.io"`},
+		{"module proxy", "[registries]\ngoproxy = \"file:///srv/goproxy\"\n", `registries.goproxy must be an http(s) URL, got "file:///srv/goproxy"`},
+		{"syntax error", "[update\n", "config.toml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSettings(t, tt.content)
+			_, err := Load(path)
+			if err == nil {
+				t.Fatal("Load() succeeded, want an error")
+			}
+			if !strings.HasPrefix(err.Error(), path+": ") || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %q, want %q prefixed with the path", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,80 +1,356 @@
 package core
 
-import "fmt"
-
-// GetInventory returns the master list of supported tools
-func GetInventory() []Tool {
-	// Helper to generate IDs sequentially could be added, but manual for now is fine
-	// or we can auto-assign IDs at runtime.
-	
-	tools := []Tool{
-		// AI Development
-		{Name: "Claude CLI", Binary: "claude", Package: "@anthropic-ai/claude-code", Category: CategoryCode, Method: MethodClaude},
-		{Name: "Droid CLI", Binary: "droid", Package: "factory-cli", Category: CategoryCode, Method: MethodDroid},
-		{Name: "Gemini CLI", Binary: "gemini", Package: "@google/gemini-cli", Category: CategoryCode, Method: MethodNpmPkg},
-		{Name: "OpenCode", Binary: "opencode", Package: "opencode-ai", Category: CategoryCode, Method: MethodOpencode},
-		{Name: "Codex CLI", Binary: "codex", Package: "@openai/codex", Category: CategoryCode, Method: MethodNpmPkg},
-		{Name: "Crush CLI", Binary: "crush", Package: "crush", Category: CategoryCode, Method: MethodBrewPkg},
-		{Name: "Toad CLI", Binary: "toad", Package: "batrachian-toad", Category: CategoryCode, Method: MethodToad},
-		{Name: "Ollama", Binary: "ollama", Package: "ollama", Category: CategoryCode, Method: MethodManual},
-
-		// Terminal Emulators
-		{Name: "iTerm2", Binary: "iterm", Package: "iterm2", Category: CategoryTerm, Method: MethodMacApp},
-		{Name: "Ghostty", Binary: "ghostty", Package: "ghostty", Category: CategoryTerm, Method: MethodMacApp},
-		{Name: "Warp Terminal", Binary: "warp", Package: "warp", Category: CategoryTerm, Method: MethodMacApp},
-
-		// IDEs
-		{Name: "VS Code", Binary: "code", Package: "visual-studio-code", Category: CategoryIDE, Method: MethodMacApp},
-		{Name: "Cursor IDE", Binary: "cursor", Package: "cursor", Category: CategoryIDE, Method: MethodMacApp},
-		{Name: "Zed Editor", Binary: "zed", Package: "zed", Category: CategoryIDE, Method: MethodMacApp},
-		{Name: "Windsurf", Binary: "windsurf", Package: "windsurf", Category: CategoryIDE, Method: MethodMacApp},
-		{Name: "Antigravity", Binary: "antigravity", Package: "antigravity", Category: CategoryIDE, Method: MethodManual},
-
-		// Productivity
-		{Name: "JQ", Binary: "jq", Package: "jq", Category: CategoryProd, Method: MethodBrewPkg},
-		{Name: "FZF", Binary: "fzf", Package: "fzf", Category: CategoryProd, Method: MethodBrewPkg},
-		{Name: "Ripgrep", Binary: "rg", Package: "ripgrep", Category: CategoryProd, Method: MethodBrewPkg},
-		{Name: "Bat", Binary: "bat", Package: "bat", Category: CategoryProd, Method: MethodBrewPkg},
-		{Name: "HTTPie", Binary: "http", Package: "httpie", Category: CategoryProd, Method: MethodBrewPkg},
-		{Name: "LazyGit", Binary: "lazygit", Package: "lazygit", Category: CategoryProd, Method: MethodBrewPkg},
-		{Name: "TLDR", Binary: "tldr", Package: "tldr", Category: CategoryProd, Method: MethodBrewPkg},
-
-		// Infrastructure
-		{Name: "Docker Desktop", Binary: "docker", Package: "docker", Category: CategoryInfra, Method: MethodMacApp},
-		{Name: "Kubernetes CLI", Binary: "kubectl", Package: "kubernetes-cli", Category: CategoryInfra, Method: MethodBrewPkg},
-		{Name: "Helm", Binary: "helm", Package: "helm", Category: CategoryInfra, Method: MethodBrewPkg},
-		{Name: "Terraform", Binary: "terraform", Package: "terraform", Category: CategoryInfra, Method: MethodBrewPkg},
-		{Name: "AWS CLI", Binary: "aws", Package: "awscli", Category: CategoryInfra, Method: MethodBrewPkg},
-		{Name: "Ngrok", Binary: "ngrok", Package: "ngrok", Category: CategoryInfra, Method: MethodBrewPkg},
-
-		// Utilities
-		{Name: "Oh My Zsh", Binary: "omz", Package: "oh-my-zsh", Category: CategoryUtils, Method: MethodOmz},
-		{Name: "Zellij", Binary: "zellij", Package: "zellij", Category: CategoryUtils, Method: MethodBrewPkg},
-		{Name: "Tmux", Binary: "tmux", Package: "tmux", Category: CategoryUtils, Method: MethodBrewPkg},
-		{Name: "Git", Binary: "git", Package: "git", Category: CategoryUtils, Method: MethodBrewPkg},
-		{Name: "Bash", Binary: "bash", Package: "bash", Category: CategoryUtils, Method: MethodBrewPkg},
-		{Name: "SQLite", Binary: "sqlite3", Package: "sqlite", Category: CategoryUtils, Method: MethodBrewPkg},
-		{Name: "Watchman", Binary: "watchman", Package: "watchman", Category: CategoryUtils, Method: MethodBrewPkg},
-		{Name: "Direnv", Binary: "direnv", Package: "direnv", Category: CategoryUtils, Method: MethodBrewPkg},
-		{Name: "Heroku CLI", Binary: "heroku", Package: "heroku", Category: CategoryUtils, Method: MethodBrewPkg},
-		{Name: "Pre-commit", Binary: "pre-commit", Package: "pre-commit", Category: CategoryUtils, Method: MethodBrewPkg},
-
-		// Runtimes
-		{Name: "Node.js", Binary: "node", Package: "node", Category: CategoryRuntime, Method: MethodBrewPkg},
-		{Name: "Python 3.13", Binary: "python3", Package: "python@3.13", Category: CategoryRuntime, Method: MethodBrewPkg},
-		{Name: "Go Lang", Binary: "go", Package: "go", Category: CategoryRuntime, Method: MethodBrewPkg},
-		{Name: "Ruby", Binary: "ruby", Package: "ruby", Category: CategoryRuntime, Method: MethodBrewPkg},
-		{Name: "PostgreSQL 16", Binary: "psql", Package: "postgresql@16", Category: CategoryRuntime, Method: MethodBrewPkg},
-
-		// System
-		{Name: "Homebrew Core", Binary: "brew", Package: "homebrew", Category: CategorySys, Method: MethodBrewPkg},
-		{Name: "NPM Globals", Binary: "npm", Package: "npm", Category: CategorySys, Method: MethodNpmSys},
-	}
-
-	// Assign IDs automatically S-01, S-02, etc.
-	for i := range tools {
-		tools[i].ID = fmt.Sprintf("S-%02d", i+1)
-	}
-
-	return tools
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// defaultInventory is the built-in tool list shipped with the binary
+//
+//go:embed tools.toml
+var defaultInventory string
+
+// builtinInventoryName is how the embedded file is named in validation errors
+const builtinInventoryName = "tools.toml (built-in)"
+
+// ValidationError reports a bad inventory entry with its file and line
+type ValidationError struct {
+	File string
+	Line int // 0 when the line is unknown
+	Msg  string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Msg)
+}
+
+// toolEntry is a single [[tool]] table as read from an inventory file
+type toolEntry struct {
+	tool     Tool
+	disabled bool
+	set      map[string]bool // Fields present in the file (overlays only apply these)
+	lines    map[string]int  // Field name -> line ("" is the [[tool]] header)
+}
+
+func (e toolEntry) line(field string) int {
+	if l, ok := e.lines[field]; ok {
+		return l
+	}
+	return e.lines[""]
+}
+
+// LoadInventory returns the built-in tool list merged with the user overlay
+// at overlayPath. A missing overlay file is not an error.
+func LoadInventory(overlayPath string) ([]Tool, error) {
+	base, err := parseInventory(builtinInventoryName, defaultInventory)
+	if err != nil {
+		return nil, err
+	}
+
+	var overlay []toolEntry
+	if overlayPath != "" {
+		data, err := os.ReadFile(overlayPath)
+		switch {
+		case err == nil:
+			if overlay, err = parseInventory(overlayPath, string(data)); err != nil {
+				return nil, err
+			}
+		case !os.IsNotExist(err):
+			return nil, fmt.Errorf("reading tool overlay: %w", err)
+		}
+	}
+
+	return mergeInventory(base, overlay, overlayPath)
+}
+
+// parseInventory decodes and validates every [[tool]] table in src
+func parseInventory(file, src string) ([]toolEntry, error) {
+	var doc struct {
+		Tool []map[string]any `toml:"tool"`
+	}
+
+	md, err := toml.Decode(src, &doc)
+	if err != nil {
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			return nil, &ValidationError{File: file, Line: perr.Position.Line, Msg: perr.Message}
+		}
+		return nil, &ValidationError{File: file, Msg: err.Error()}
+	}
+
+	var errs []error
+	for _, key := range md.Undecoded() {
+		errs = append(errs, &ValidationError{File: file, Msg: fmt.Sprintf("unknown table or key %q (expected [[tool]] entries)", key.String())})
+	}
+
+	blocks := scanToolBlocks(src)
+	seen := make(map[string]int)
+	entries := make([]toolEntry, 0, len(doc.Tool))
+
+	for i, raw := range doc.Tool {
+		lines := map[string]int{}
+		if i < len(blocks) {
+			lines = blocks[i]
+		}
+
+		entry, entryErrs := decodeEntry(file, raw, lines)
+		errs = append(errs, entryErrs...)
+		if len(entryErrs) > 0 {
+			continue
+		}
+
+		if prev, ok := seen[entry.tool.Key]; ok {
+			errs = append(errs, &ValidationError{File: file, Line: entry.line("key"),
+				Msg: fmt.Sprintf("duplicate key %q (first defined on line %d)", entry.tool.Key, prev)})
+			continue
+		}
+		seen[entry.tool.Key] = entry.line("key")
+		entries = append(entries, entry)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return entries, nil
+}
+
+// decodeEntry converts a raw TOML table into a toolEntry, checking field names and types
+func decodeEntry(file string, raw map[string]any, lines map[string]int) (toolEntry, []error) {
+	entry := toolEntry{set: make(map[string]bool), lines: lines}
+	var errs []error
+
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, &ValidationError{File: file, Line: entry.line(field), Msg: fmt.Sprintf(format, args...)})
+	}
+
+	// Visit fields in file order so errors read top to bottom
+	fields := make([]string, 0, len(raw))
+	for field := range raw {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool { return entry.line(fields[i]) < entry.line(fields[j]) })
+
+	for _, field := range fields {
+		value := raw[field]
+		if field == "disabled" {
+			b, ok := value.(bool)
+			if !ok {
+				fail(field, "field %q must be true or false", field)
+				continue
+			}
+			entry.disabled = b
+			entry.set[field] = true
+			continue
+		}
+
+		s, ok := value.(string)
+		if !ok {
+			fail(field, "field %q must be a string", field)
+			continue
+		}
+		s = strings.TrimSpace(s)
+
+		switch field {
+		case "key":
+			entry.tool.Key = s
+		case "name":
+			entry.tool.Name = s
+		case "binary":
+			entry.tool.Binary = s
+		case "package":
+			entry.tool.Package = s
+		case "description":
+			entry.tool.Description = s
+		case "category":
+			entry.tool.Category = Category(strings.ToUpper(s))
+			if !isKnownCategory(entry.tool.Category) {
+				fail(field, "unknown category %q", s)
+			}
+		case "method":
+			entry.tool.Method = UpdateMethod(s)
+			if !isKnownMethod(entry.tool.Method) {
+				fail(field, "unknown method %q", s)
+			}
+		case "registry":
+			entry.tool.Registry = s
+			if _, _, err := ParseRegistry(s); err != nil {
+				fail(field, "%v", err)
+			}
+		default:
+			fail(field, "unknown field %q", field)
+			continue
+		}
+		entry.set[field] = true
+	}
+
+	if entry.tool.Key == "" {
+		fail("key", "entry is missing required field \"key\"")
+	}
+	return entry, errs
+}
+
+// requireComplete checks that an entry defining a new tool has every required field
+func requireComplete(file string, e toolEntry) []error {
+	var errs []error
+	for _, field := range []string{"name", "binary", "category", "method"} {
+		if !e.set[field] {
+			errs = append(errs, &ValidationError{File: file, Line: e.line(""),
+				Msg: fmt.Sprintf("tool %q is missing required field %q", e.tool.Key, field)})
+		}
+	}
+	return errs
+}
+
+// mergeInventory applies overlay entries on top of base by key and assigns IDs.
+// IDs follow the built-in order so they stay stable when the overlay changes;
+// tools added by the overlay get the next free numbers.
+func mergeInventory(base, overlay []toolEntry, overlayFile string) ([]Tool, error) {
+	var errs []error
+	for _, e := range base {
+		errs = append(errs, requireComplete(builtinInventoryName, e)...)
+	}
+
+	index := make(map[string]int, len(base))
+	for i := range base {
+		base[i].tool.ID = fmt.Sprintf("S-%02d", i+1)
+		index[base[i].tool.Key] = i
+	}
+
+	merged := base
+	nextID := len(base) + 1
+	for _, o := range overlay {
+		i, exists := index[o.tool.Key]
+		if !exists {
+			if o.disabled {
+				continue // Disabling an unknown tool is a no-op
+			}
+			if missing := requireComplete(overlayFile, o); len(missing) > 0 {
+				errs = append(errs, missing...)
+				continue
+			}
+			o.tool.ID = fmt.Sprintf("S-%02d", nextID)
+			nextID++
+			merged = insertAfterCategory(merged, o)
+			for j := range merged {
+				index[merged[j].tool.Key] = j
+			}
+			continue
+		}
+
+		target := &merged[i]
+		if o.set["name"] {
+			target.tool.Name = o.tool.Name
+		}
+		if o.set["binary"] {
+			target.tool.Binary = o.tool.Binary
+		}
+		if o.set["package"] {
+			target.tool.Package = o.tool.Package
+		}
+		if o.set["description"] {
+			target.tool.Description = o.tool.Description
+		}
+		if o.set["category"] {
+			target.tool.Category = o.tool.Category
+		}
+		if o.set["method"] {
+			target.tool.Method = o.tool.Method
+		}
+		if o.set["registry"] {
+			target.tool.Registry = o.tool.Registry
+		}
+		if o.set["disabled"] {
+			target.disabled = o.disabled
+		}
+
+		// A tool moved to another category is re-slotted to keep categories contiguous
+		if o.set["category"] && (i+1 >= len(merged) || merged[i+1].tool.Category != o.tool.Category) &&
+			(i == 0 || merged[i-1].tool.Category != o.tool.Category) {
+			moved := *target
+			merged = insertAfterCategory(append(merged[:i], merged[i+1:]...), moved)
+			for j := range merged {
+				index[merged[j].tool.Key] = j
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	tools := make([]Tool, 0, len(merged))
+	for _, e := range merged {
+		if !e.disabled {
+			tools = append(tools, e.tool)
+		}
+	}
+	return tools, nil
+}
+
+// insertAfterCategory keeps categories contiguous so TAB navigation and the grid stay ordered
+func insertAfterCategory(entries []toolEntry, e toolEntry) []toolEntry {
+	pos := len(entries)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].tool.Category == e.tool.Category {
+			pos = i + 1
+			break
+		}
+	}
+	entries = append(entries, toolEntry{})
+	copy(entries[pos+1:], entries[pos:])
+	entries[pos] = e
+	return entries
+}
+
+// scanToolBlocks records the line of each [[tool]] header and of the keys inside it.
+// The TOML decoder does not expose positions, so this lightweight scan lets
+// validation errors point at the offending line.
+func scanToolBlocks(src string) []map[string]int {
+	var blocks []map[string]int
+	var current map[string]int
+
+	for n, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, "[[tool]]"):
+			current = map[string]int{"": n + 1}
+			blocks = append(blocks, current)
+		case strings.HasPrefix(trimmed, "["):
+			current = nil
+		case current != nil:
+			if key, _, ok := strings.Cut(trimmed, "="); ok {
+				current[strings.Trim(strings.TrimSpace(key), `"'`)] = n + 1
+			}
+		}
+	}
+	return blocks
+}
+
+func isKnownCategory(c Category) bool {
+	for _, known := range Categories {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+func isKnownMethod(m UpdateMethod) bool {
+	for _, known := range Methods {
+		if m == known {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testBase = `# Base inventory
+[[tool]]
+key      = "claude"
+name     = "Claude CLI"
+binary   = "claude"
+category = "CODE"
+method   = "claude"
+
+[[tool]]
+key      = "gemini"
+name     = "Gemini CLI"
+binary   = "gemini"
+package  = "@google/gemini-cli"
+category = "CODE"
+method   = "npm_pkg"
+
+[[tool]]
+key      = "jq"
+name     = "jq"
+binary   = "jq"
+category = "UTILS"
+method   = "brew_pkg"
+`
+
+func TestParseInventoryPositions(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string // Each error as file:line: message
+	}{
+		{
+			name: "duplicate key",
+			src:  testBase + "\n[[tool]]\nkey = \"jq\"\nname = \"jq again\"\n",
+			want: []string{`overlay.toml:25: duplicate key "jq" (first defined on line 18)`},
+		},
+		{
+			name: "unknown method",
+			src:  "[[tool]]\nkey = \"fd\"\nname = \"fd\"\nmethod = \"brew_pkgs\"\n",
+			want: []string{`overlay.toml:4: unknown method "brew_pkgs"`},
+		},
+		{
+			name: "bad category",
+			src:  "# Utilities\n\n[[tool]]\nkey = \"fd\"\ncategory = \"TOOLS\"\n",
+			want: []string{`overlay.toml:5: unknown category "TOOLS"`},
+		},
+		{
+			name: "every error in file order",
+			src:  "[[tool]]\nkey = \"fd\"\ncategory = \"TOOLS\"\ncolour = \"red\"\nmethod = \"snapd\"\n\n[[tool]]\nname = \"no key\"\n",
+			want: []string{
+				`overlay.toml:3: unknown category "TOOLS"`,
+				`overlay.toml:4: unknown field "colour"`,
+				`overlay.toml:5: unknown method "snapd"`,
+				`overlay.toml:7: entry is missing required field "key"`,
+			},
+		},
+		{
+			name: "wrong type",
+			src:  "[[tool]]\nkey = \"fd\"\ndisabled = \"yes\"\n",
+			want: []string{`overlay.toml:3: field "disabled" must be true or false`},
+		},
+		{
+			name: "syntax error",
+			src:  "[[tool]]\nkey = \"fd\nname = \"fd\"\n",
+			want: []string{`overlay.toml:2: `},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseInventory("overlay.toml", tt.src)
+			if err == nil {
+				t.Fatal("parseInventory() succeeded, want an error")
+			}
+			got := strings.Split(err.Error(), "\n")
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseInventory() errors = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("error %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMergeInventory(t *testing.T) {
+	tests := []struct {
+		name    string
+		overlay string
+		want    []string // ID key category of each tool, in order
+		check   func(t *testing.T, tools []Tool)
+	}{
+		{
+			name: "no overlay",
+			want: []string{"S-01 claude CODE", "S-02 gemini CODE", "S-03 jq UTILS"},
+		},
+		{
+			name:    "fields override in place",
+			overlay: "[[tool]]\nkey = \"gemini\"\nname = \"Gemini\"\nmethod = \"brew_pkg\"\n",
+			want:    []string{"S-01 claude CODE", "S-02 gemini CODE", "S-03 jq UTILS"},
+			check: func(t *testing.T, tools []Tool) {
+				g := tools[1]
+				if g.Name != "Gemini" || g.Method != MethodBrewPkg || g.Package != "@google/gemini-cli" || g.Binary != "gemini" {
+					t.Errorf("gemini = %+v, want name and method overridden, the rest kept", g)
+				}
+			},
+		},
+		{
+			name:    "disabled tools keep the other IDs",
+			overlay: "[[tool]]\nkey = \"claude\"\ndisabled = true\n\n[[tool]]\nkey = \"unknown\"\ndisabled = true\n",
+			want:    []string{"S-02 gemini CODE", "S-03 jq UTILS"},
+		},
+		{
+			name:    "new tools join their category with the next IDs",
+			overlay: "[[tool]]\nkey = \"fd\"\nname = \"fd\"\nbinary = \"fd\"\ncategory = \"UTILS\"\nmethod = \"brew_pkg\"\n\n[[tool]]\nkey = \"codex\"\nname = \"Codex\"\nbinary = \"codex\"\ncategory = \"CODE\"\nmethod = \"npm_pkg\"\n",
+			want:    []string{"S-01 claude CODE", "S-02 gemini CODE", "S-05 codex CODE", "S-03 jq UTILS", "S-04 fd UTILS"},
+		},
+		{
+			name:    "a moved tool keeps its ID",
+			overlay: "[[tool]]\nkey = \"claude\"\ncategory = \"utils\"\n",
+			want:    []string{"S-02 gemini CODE", "S-03 jq UTILS", "S-01 claude UTILS"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, err := parseInventory(builtinInventoryName, testBase)
+			if err != nil {
+				t.Fatal(err)
+			}
+			overlay, err := parseInventory("overlay.toml", tt.overlay)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tools, err := mergeInventory(base, overlay, "overlay.toml")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, tool := range tools {
+				got = append(got, tool.ID+" "+tool.Key+" "+string(tool.Category))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged = %q, want %q", got, tt.want)
+			}
+			if tt.check != nil {
+				tt.check(t, tools)
+			}
+		})
+	}
+}
+
+func TestMergeInventoryIncompleteTool(t *testing.T) {
+	base, _ := parseInventory(builtinInventoryName, testBase)
+	overlay, err := parseInventory("overlay.toml", "# New tool\n[[tool]]\nkey = \"fd\"\nname = \"fd\"\ncategory = \"UTILS\"\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = mergeInventory(base, overlay, "overlay.toml")
+	want := "overlay.toml:2: tool \"fd\" is missing required field \"binary\"\noverlay.toml:2: tool \"fd\" is missing required field \"method\""
+	if err == nil || err.Error() != want {
+		t.Errorf("mergeInventory() error = %v, want %s", err, want)
+	}
+}
+
+func TestLoadInventory(t *testing.T) {
+	builtin, err := LoadInventory("")
+	if err != nil {
+		t.Fatalf("built-in inventory: %v", err)
+	}
+
+	dir := t.TempDir()
+	missing, err := LoadInventory(filepath.Join(dir, "tools.toml"))
+	if err != nil || !reflect.DeepEqual(missing, builtin) {
+		t.Errorf("LoadInventory() without an overlay = (%d tools, %v), want the built-in list", len(missing), err)
+	}
+
+	path := filepath.Join(dir, "overlay.toml")
+	if err := os.WriteFile(path, []byte("[[tool]]\nkey = \"claude\"\n\nmethod = \"npm\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadInventory(path); err == nil || err.Error() != path+`:4: unknown method "npm"` {
+		t.Errorf("LoadInventory() error = %v, want it at %s:4", err, path)
+	}
+
+	if err := os.WriteFile(path, []byte("[[tool]]\nkey = \"claude\"\nname = \"Claude\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tools, err := LoadInventory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != len(builtin) || tools[0].Key != "claude" || tools[0].Name != "Claude" || tools[0].ID != "S-01" {
+		t.Errorf("LoadInventory() first tool = %+v, want claude renamed in place", tools[0])
+	}
+}
@@ -1,5 +1,10 @@
 package core
 
+import (
+	"fmt"
+	"strings"
+)
+
 // UpdateMethod defines how a tool is updated
 type UpdateMethod string
 
@@ -15,8 +20,35 @@ const (
 	MethodOpencode  UpdateMethod = "opencode"
 	MethodOmz       UpdateMethod = "omz"
 	MethodManual    UpdateMethod = "manual" // For tools like Antigravity
+	MethodApt       UpdateMethod = "apt"    // Debian/Ubuntu (dpkg)
+	MethodDnf       UpdateMethod = "dnf"    // Fedora/RHEL (rpm)
+	MethodPacman    UpdateMethod = "pacman" // Arch Linux
+	MethodAsdf      UpdateMethod = "asdf"   // Version managers: upgrades switch the global runtime
+	MethodMise      UpdateMethod = "mise"
+	MethodNvm       UpdateMethod = "nvm"
+	MethodPyenv     UpdateMethod = "pyenv"
+	MethodRbenv     UpdateMethod = "rbenv"
+	MethodGoenv     UpdateMethod = "goenv"
+	MethodPipx      UpdateMethod = "pipx"       // Language-level tool installers: one package each
+	MethodUv        UpdateMethod = "uv"         // uv tool
+	MethodCargo     UpdateMethod = "cargo"      // cargo install
+	MethodGoInstall UpdateMethod = "go_install" // go install <package>@latest
+	MethodGem       UpdateMethod = "gem"
+	MethodSnap      UpdateMethod = "snap"     // Linux desktop apps, counterparts of mac_app
+	MethodFlatpak   UpdateMethod = "flatpak"  // Application ID as package
+	MethodAppImage  UpdateMethod = "appimage" // Path of the .AppImage file as package
 )
 
+// Methods lists every UpdateMethod accepted in the inventory
+var Methods = []UpdateMethod{
+	MethodBrew, MethodNpmSys, MethodNpmPkg, MethodBrewPkg, MethodMacApp,
+	MethodClaude, MethodDroid, MethodToad, MethodOpencode, MethodOmz, MethodManual,
+	MethodApt, MethodDnf, MethodPacman,
+	MethodAsdf, MethodMise, MethodNvm, MethodPyenv, MethodRbenv, MethodGoenv,
+	MethodPipx, MethodUv, MethodCargo, MethodGoInstall, MethodGem,
+	MethodSnap, MethodFlatpak, MethodAppImage,
+}
+
 // Category groups tools logically
 type Category string
 
@@ -31,14 +63,22 @@ const (
 	CategorySys     Category = "SYS"     // System Managers
 )
 
+// Categories lists every Category in dashboard order
+var Categories = []Category{
+	CategoryCode, CategoryTerm, CategoryIDE, CategoryProd,
+	CategoryInfra, CategoryUtils, CategoryRuntime, CategorySys,
+}
+
 // Tool represents a software component managed by Spark
 type Tool struct {
 	ID          string       // Unique internal ID (S-01, etc.)
+	Key         string       // Stable inventory key (e.g., "claude"), used by overlays
 	Name        string       // Display Name (e.g., "Claude CLI")
 	Binary      string       // Binary command (e.g., "claude") or App Name
 	Package     string       // Package name (e.g., "@anthropic-ai/claude-code")
 	Category    Category     // Grouping category
 	Method      UpdateMethod // How to update it
+	Registry    string       // Where to look up the latest release, e.g. "pypi:toad" (see ParseRegistry)
 	Description string       // Optional description
 }
 
@@ -55,13 +95,84 @@ const (
 	StatusUpdating                      // Update in progress
 	StatusUpdated                       // Successfully updated
 	StatusFailed                        // Update failed
+	StatusAhead                         // Installed version is newer than the latest release
+	StatusAborted                       // Update cancelled while running
+	StatusSkipped                       // Queued for update but never started
 )
 
+// String returns the lower-case status name used in CLI and report output
+func (s ToolStatus) String() string {
+	switch s {
+	case StatusChecking:
+		return "checking"
+	case StatusInstalled:
+		return "up_to_date"
+	case StatusOutdated:
+		return "outdated"
+	case StatusMissing:
+		return "missing"
+	case StatusUnmanaged:
+		return "unmanaged"
+	case StatusManualCheck:
+		return "manual_check"
+	case StatusUpdating:
+		return "updating"
+	case StatusUpdated:
+		return "updated"
+	case StatusFailed:
+		return "failed"
+	case StatusAhead:
+		return "ahead"
+	case StatusAborted:
+		return "aborted"
+	case StatusSkipped:
+		return "skipped"
+	default:
+		return "unknown"
+	}
+}
+
 // ToolState holds the runtime data for a tool
 type ToolState struct {
-	Tool         Tool
-	Status       ToolStatus
-	LocalVersion string
+	Tool          Tool
+	Status        ToolStatus
+	LocalVersion  string
 	RemoteVersion string
 	Message       string // Error message or status detail
+	LocalSource   string // Where LocalVersion was detected (e.g. "path", "brew_list")
+	RemoteSource  string // Where RemoteVersion came from (e.g. "brew_outdated")
+	RemoteStale   bool   // RemoteVersion comes from an expired cache and is being refreshed
+	Pin           string // Pin spec from config.toml ("hold", "~1.5"), "" when not pinned
+	Owner         string // Installer of the binary on PATH ("brew", "nvm"), "" when unknown
+	BinaryPath    string // Binary on PATH with symlinks followed, "" when not found
+	Shadowed      string // Copy the method manages but an earlier one on PATH hides, "" when none
+}
+
+// Registry kinds accepted in Tool.Registry ("kind:name")
+const (
+	RegistryNpm    = "npm"    // npm package, e.g. npm:@openai/codex
+	RegistryPyPI   = "pypi"   // PyPI project, e.g. pypi:batrachian-toad
+	RegistryGitHub = "github" // GitHub releases, e.g. github:ollama/ollama
+	RegistryBrew   = "brew"   // Homebrew formula, e.g. brew:jq
+	RegistryCask   = "cask"   // Homebrew cask, e.g. cask:ghostty
+	RegistryCrates = "crates" // crates.io crate, e.g. crates:ripgrep
+	RegistryGo     = "go"     // Go module proxy, e.g. go:golang.org/x/tools/gopls
+)
+
+// ParseRegistry splits a "kind:name" registry reference and checks its shape
+func ParseRegistry(ref string) (kind, name string, err error) {
+	kind, name, ok := strings.Cut(ref, ":")
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("registry %q must look like kind:name", ref)
+	}
+	switch kind {
+	case RegistryNpm, RegistryPyPI, RegistryBrew, RegistryCask, RegistryCrates, RegistryGo:
+	case RegistryGitHub:
+		if owner, repo, ok := strings.Cut(name, "/"); !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			return "", "", fmt.Errorf("registry %q: GitHub repositories look like github:owner/repo", ref)
+		}
+	default:
+		return "", "", fmt.Errorf("registry %q: unknown kind %q (want npm, pypi, github, brew, cask, crates or go)", ref, kind)
+	}
+	return kind, name, nil
 }
@@ -0,0 +1,138 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// SchemaVersion is bumped whenever a field is renamed or removed.
+// Adding fields is backwards compatible and keeps the version.
+const SchemaVersion = 1
+
+// Report is the machine-readable result of a version check
+type Report struct {
+	SchemaVersion int          `json:"schema_version"`
+	GeneratedAt   time.Time    `json:"generated_at"`
+	Summary       Summary      `json:"summary"`
+	Tools         []ToolReport `json:"tools"`
+}
+
+// Summary counts tools per status
+type Summary struct {
+	Total    int `json:"total"`
+	UpToDate int `json:"up_to_date"`
+	Outdated int `json:"outdated"`
+	Ahead    int `json:"ahead"`
+	Missing  int `json:"missing"`
+	Other    int `json:"other"`
+}
+
+// ToolReport is one tool's entry in the report
+type ToolReport struct {
+	ID            string `json:"id"`
+	Key           string `json:"key"`
+	Name          string `json:"name"`
+	Category      string `json:"category"`
+	Method        string `json:"method"`
+	Binary        string `json:"binary"`
+	Package       string `json:"package,omitempty"`
+	LocalVersion  string `json:"local_version"`
+	RemoteVersion string `json:"remote_version"`
+	Status        string `json:"status"`
+	Message       string `json:"message,omitempty"`
+	Pin           string `json:"pin,omitempty"`
+	Owner         string `json:"owner,omitempty"`
+	BinaryPath    string `json:"binary_path,omitempty"`
+	Shadowed      string `json:"shadowed,omitempty"`
+	Source        Source `json:"source"`
+}
+
+// Source tells where each version was detected
+type Source struct {
+	Local  string `json:"local,omitempty"`
+	Remote string `json:"remote,omitempty"`
+}
+
+// New builds a report from tool states, keeping their order
+func New(states []core.ToolState) Report {
+	r := Report{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   time.Now().UTC().Truncate(time.Second),
+		Tools:         make([]ToolReport, 0, len(states)),
+	}
+
+	for _, s := range states {
+		source := Source{Local: s.LocalSource, Remote: s.RemoteSource}
+		if s.Status == core.StatusMissing {
+			source.Local = "" // Not found anywhere, whatever was looked at
+		}
+		r.Tools = append(r.Tools, ToolReport{
+			ID:            s.Tool.ID,
+			Key:           s.Tool.Key,
+			Name:          s.Tool.Name,
+			Category:      string(s.Tool.Category),
+			Method:        string(s.Tool.Method),
+			Binary:        s.Tool.Binary,
+			Package:       s.Tool.Package,
+			LocalVersion:  s.LocalVersion,
+			RemoteVersion: s.RemoteVersion,
+			Status:        s.Status.String(),
+			Message:       s.Message,
+			Pin:           s.Pin,
+			Owner:         s.Owner,
+			BinaryPath:    s.BinaryPath,
+			Shadowed:      s.Shadowed,
+			Source:        source,
+		})
+
+		r.Summary.Total++
+		switch s.Status {
+		case core.StatusInstalled:
+			r.Summary.UpToDate++
+		case core.StatusOutdated:
+			r.Summary.Outdated++
+		case core.StatusAhead:
+			r.Summary.Ahead++
+		case core.StatusMissing:
+			r.Summary.Missing++
+		default:
+			r.Summary.Other++
+		}
+	}
+	return r
+}
+
+// Write encodes the report as indented JSON
+func Write(w io.Writer, states []core.ToolState) error {
+	return encode(w, New(states))
+}
+
+func encode(w io.Writer, r Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteFile writes the report to dir with a timestamped name and returns its path
+func WriteFile(dir string, states []core.ToolState) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "spark-report-"+time.Now().Format("20060102-150405")+".json")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := Write(f, states); err != nil {
+		return "", err
+	}
+	return path, f.Close()
+}
@@ -0,0 +1,75 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// reportStates covers every status the summary counts and every optional field
+var reportStates = []core.ToolState{
+	{
+		Tool:         core.Tool{ID: "S-01", Key: "jq", Name: "jq", Category: core.CategoryUtils, Method: core.MethodBrew, Binary: "jq", Package: "jq"},
+		LocalVersion: "1.7.1", RemoteVersion: "1.7.1", Status: core.StatusInstalled,
+		LocalSource: "path", RemoteSource: "brew_outdated", Owner: "brew", BinaryPath: "/opt/homebrew/bin/jq",
+	},
+	{
+		Tool:         core.Tool{ID: "S-02", Key: "node", Name: "Node.js", Category: core.CategoryRuntime, Method: core.MethodBrew, Binary: "node", Package: "node@22"},
+		LocalVersion: "22.1.0", RemoteVersion: "22.2.0", Status: core.StatusOutdated, Message: "Update available", Pin: "22",
+		LocalSource: "path", RemoteSource: "brew_outdated", Owner: "brew", BinaryPath: "/opt/homebrew/bin/node", Shadowed: "/usr/local/bin/node",
+	},
+	{
+		Tool:         core.Tool{ID: "S-03", Key: "claude", Name: "Claude Code", Category: core.CategoryCode, Method: core.MethodNpmPkg, Binary: "claude"},
+		LocalVersion: "2.1.0", RemoteVersion: "2.0.9", Status: core.StatusAhead, Message: "Newer than latest release",
+		LocalSource: "path", RemoteSource: "npm_registry", Owner: "npm",
+	},
+	{
+		// The source looked at is not reported for a tool that was not found
+		Tool:         core.Tool{ID: "S-04", Key: "zed", Name: "Zed", Category: core.CategoryIDE, Method: core.MethodMacApp, Binary: "zed", Package: "zed"},
+		LocalVersion: "MISSING", RemoteVersion: "0.141.2", Status: core.StatusMissing, Message: "Not installed",
+		LocalSource: "app_bundle", RemoteSource: "brew_outdated",
+	},
+	{
+		Tool:         core.Tool{ID: "S-05", Key: "docker", Name: "Docker", Category: core.CategoryInfra, Method: core.MethodManual, Binary: "docker"},
+		LocalVersion: "27.0.3", RemoteVersion: "Unknown", Status: core.StatusManualCheck,
+		LocalSource: "path",
+	},
+}
+
+func TestReportGolden(t *testing.T) {
+	r := New(reportStates)
+	r.GeneratedAt = time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	if err := encode(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "report.golden.json")
+	if *update {
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A changed field name or status string breaks every consumer: bump SchemaVersion first
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("report does not match %s (go test -update rewrites it):\n%s", golden, buf.String())
+	}
+}
+
+func TestReportSummary(t *testing.T) {
+	want := Summary{Total: 5, UpToDate: 1, Outdated: 1, Ahead: 1, Missing: 1, Other: 1}
+	if got := New(reportStates).Summary; got != want {
+		t.Errorf("Summary = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// driftRow is a tool that does not match the manifest
+type driftRow struct {
+	Index int // Into Model.items
+	Drift updater.Drift
+}
+
+// drift compares the checked items with the manifest. It returns the tools
+// that deviate, how many tools the manifest lists and how many of those are
+// still being checked.
+func (m Model) drift() (rows []driftRow, listed, pending int) {
+	if m.manifest == nil {
+		return nil, 0, 0
+	}
+	for i, item := range m.items {
+		if !m.manifest.Lists(item.Tool) {
+			continue
+		}
+		listed++
+		if item.Status == core.StatusChecking {
+			pending++
+			continue
+		}
+		if d := m.manifest.Check(item); d.Step != updater.SyncOK {
+			rows = append(rows, driftRow{Index: i, Drift: d})
+		}
+	}
+	return rows, listed, pending
+}
+
+// selectFixable selects the drifting tools an install or upgrade brings in
+// line and reports how many there are
+func (m *Model) selectFixable() int {
+	rows, _, _ := m.drift()
+	n := 0
+	for _, r := range rows {
+		switch r.Drift.Step {
+		case updater.SyncInstall, updater.SyncUpgrade:
+			m.checked[r.Index] = true
+			n++
+		}
+	}
+	return n
+}
+
+// ViewDrift lists the tools that deviate from the manifest with the step
+// that fixes each, colored by how much Spark can do about it
+func (m Model) ViewDrift() string {
+	rows, listed, pending := m.drift()
+	title := lipgloss.NewStyle().
+		Background(cPurple).
+		Foreground(cWhite).
+		Bold(true).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" MANIFEST DRIFT: %s (%d of %d tools) ", m.manifest.Path, len(rows), listed))
+
+	var body []string
+	if pending > 0 {
+		body = append(body, lipgloss.NewStyle().Foreground(cGray).Render(fmt.Sprintf("(%d tool(s) still being checked)", pending)))
+	}
+	if len(rows) == 0 && pending == 0 {
+		body = append(body, lipgloss.NewStyle().Foreground(cGreen).Render("✓ Every tool matches the manifest"))
+	}
+
+	page := max(m.height-10, 5)
+	pos := min(m.driftPos, max(len(rows)-1, 0))
+	start := (pos / page) * page
+	for i := start; i < min(start+page, len(rows)); i++ {
+		d := rows[i].Drift
+		var color lipgloss.Color
+		switch d.Step {
+		case updater.SyncInstall, updater.SyncUpgrade:
+			color = cGreen
+		case updater.SyncDowngrade:
+			color = cYellow
+		default:
+			color = cRed
+		}
+		line := fmt.Sprintf("%-5s %-22s %-14s %-14s %-14s",
+			d.Tool.ID, truncateLine(d.Tool.Name, 22), truncateLine(d.Local, 14), truncateLine(d.Spec, 14), truncateLine(d.Remote, 14))
+		step := lipgloss.NewStyle().Foreground(color).Bold(true).Render(d.Step.String())
+		if i == pos {
+			body = append(body, lipgloss.NewStyle().Foreground(cWhite).Bold(true).Render("▸ "+line)+" "+step)
+		} else {
+			body = append(body, lipgloss.NewStyle().Foreground(lipgloss.Color("#A8A8A8")).Render("  "+line)+" "+step)
+		}
+	}
+	if pos < len(rows) && rows[pos].Drift.Reason != "" {
+		body = append(body, "", lipgloss.NewStyle().Foreground(cYellow).Render(truncateLine(rows[pos].Drift.Reason, m.transcriptWidth())))
+	}
+
+	header := lipgloss.NewStyle().Foreground(cGray).Render(fmt.Sprintf("  %-5s %-22s %-14s %-14s %-14s %s", "ID", "NAME", "LOCAL", "REQUIRED", "LATEST", "PLAN"))
+	help := lipgloss.NewStyle().
+		Foreground(cGray).
+		Render("[↑/↓] Select • [S] Select installs and upgrades on the dashboard • [ESC] Back to dashboard")
+
+	content := title + "\n\n" + header + "\n" + strings.Join(body, "\n") + "\n\n" + help
+	return appStyle.Render(content)
+}
@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// historyEntry describes the attempt msg reports, for the update history.
+// Call it before the item takes its new version.
+func (m Model) historyEntry(msg UpdateResultMsg) updater.HistoryEntry {
+	i := msg.Index
+	t := m.items[i].Tool
+	start := m.startedAt[i]
+	e := updater.HistoryEntry{
+		Time:       start,
+		ToolID:     t.ID,
+		Key:        t.Key,
+		Name:       t.Name,
+		Method:     string(t.Method),
+		Action:     m.sessionAction.String(),
+		From:       m.items[i].LocalVersion,
+		DurationMS: time.Since(start).Milliseconds(),
+		Success:    msg.Success,
+		Aborted:    msg.Aborted,
+		Output:     updater.TailOutput(m.transcripts[i]),
+	}
+	if msg.Success {
+		e.To = msg.NewVersion
+	} else {
+		e.Message = msg.Message
+	}
+	return e
+}
+
+// recordHistory appends e to the ledger in the background
+func (m Model) recordHistory(e updater.HistoryEntry) tea.Cmd {
+	if m.history == nil {
+		return nil
+	}
+	h := m.history
+	return func() tea.Msg {
+		return historyWrittenMsg{Err: h.Append(e)}
+	}
+}
+
+// loadHistory reads the ledger, filtered on historyKey, for stateHistory
+func (m Model) loadHistory() tea.Cmd {
+	if m.history == nil {
+		return nil
+	}
+	h := m.history
+	var filter updater.HistoryFilter
+	if m.historyKey != "" {
+		filter.Keys = []string{m.historyKey}
+	}
+	return func() tea.Msg {
+		entries, err := h.Read(filter)
+		for l, r := 0, len(entries)-1; l < r; l, r = l+1, r-1 {
+			entries[l], entries[r] = entries[r], entries[l]
+		}
+		return HistoryLoadedMsg{Entries: entries, Err: err}
+	}
+}
+
+// ViewHistory lists past update attempts, newest first, with the output of
+// the selected one when expanded
+func (m Model) ViewHistory() string {
+	heading := fmt.Sprintf(" UPDATE HISTORY (%d) ", len(m.historyEntries))
+	if m.historyKey != "" {
+		heading = fmt.Sprintf(" UPDATE HISTORY: %s (%d) ", m.items[m.cursor].Tool.Name, len(m.historyEntries))
+	}
+	title := lipgloss.NewStyle().
+		Background(cPurple).
+		Foreground(cWhite).
+		Bold(true).
+		Padding(0, 1).
+		Render(heading)
+
+	var body []string
+	switch {
+	case m.historyErr != nil:
+		body = append(body, lipgloss.NewStyle().Foreground(cRed).Render("Could not read the history: "+m.historyErr.Error()))
+	case len(m.historyEntries) == 0:
+		body = append(body, lipgloss.NewStyle().Foreground(cGray).Render("(no update attempts recorded)"))
+	}
+
+	page := m.historyPageSize()
+	start := (m.historyPos / page) * page
+	end := min(start+page, len(m.historyEntries))
+	for i := start; i < end; i++ {
+		body = append(body, m.renderHistoryLine(i))
+	}
+
+	if m.historyExpanded && m.historyPos < len(m.historyEntries) {
+		e := m.historyEntries[m.historyPos]
+		body = append(body, "")
+		if e.Message != "" {
+			body = append(body, lipgloss.NewStyle().Foreground(cRed).Render(truncateLine(e.Message, m.transcriptWidth())))
+		}
+		output := strings.Split(e.Output, "\n")
+		if e.Output == "" {
+			output = []string{"(no output)"}
+		}
+		for _, line := range output[max(len(output)-m.historyOutputSize(), 0):] {
+			body = append(body, lipgloss.NewStyle().Foreground(cGray).Render(truncateLine(line, m.transcriptWidth())))
+		}
+	}
+
+	help := lipgloss.NewStyle().
+		Foreground(cGray).
+		Render("[↑/↓/PGUP/PGDN] Select • [ENTER] Show output • [T] This tool / all tools • [ESC] Back to dashboard")
+
+	content := title + "\n\n" + strings.Join(body, "\n") + "\n\n" + help
+	return appStyle.Render(content)
+}
+
+// renderHistoryLine renders entry i as one row: when, what, versions, result
+func (m Model) renderHistoryLine(i int) string {
+	e := m.historyEntries[i]
+
+	var result string
+	switch e.Outcome() {
+	case "success":
+		result = lipgloss.NewStyle().Foreground(cGreen).Render("✓")
+	case "aborted":
+		result = lipgloss.NewStyle().Foreground(cYellow).Render("⊘")
+	default:
+		result = lipgloss.NewStyle().Foreground(cRed).Render("✘")
+	}
+
+	versions := e.From
+	if e.To != "" {
+		versions += " → " + e.To
+	}
+	line := fmt.Sprintf("%s  %-5s %-22s %-10s %-28s %6s",
+		e.Time.Local().Format("2006-01-02 15:04"), e.ToolID, truncateLine(e.Name, 22), e.Action,
+		truncateLine(versions, 28), e.Duration().Round(time.Second))
+
+	if i == m.historyPos {
+		return lipgloss.NewStyle().Foreground(cWhite).Bold(true).Render("▸ "+line) + " " + result
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#A8A8A8")).Render("  "+line) + " " + result
+}
+
+// historyPageSize is how many entries fit on screen, fewer when an entry's output is shown
+func (m Model) historyPageSize() int {
+	rows := max(m.height-10, 5)
+	if m.historyExpanded {
+		rows = max(rows/2, 3)
+	}
+	return rows
+}
+
+// historyOutputSize is how many output lines of the expanded entry fit under the list
+func (m Model) historyOutputSize() int {
+	return max(m.height-10-m.historyPageSize()-3, 3)
+}
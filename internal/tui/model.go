@@ -1,12 +1,18 @@
 package tui
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbletea"
+	"github.com/dpeluche/spark/internal/config"
 	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/report"
 	"github.com/dpeluche/spark/internal/updater"
 )
 
@@ -21,6 +27,12 @@ const (
 	stateConfirm
 	stateUpdating
 	stateSummary
+	stateTranscript       // Full update output of one tool, opened from the summary
+	stateInstallConfirm   // Lists the missing tools about to be installed
+	stateUninstallConfirm // Lists the tools about to be removed, with dependency warnings
+	stateRollbackConfirm  // Lists the tools of the last session about to be rolled back
+	stateHistory          // Past update attempts from the history ledger
+	stateDrift            // Tools that deviate from the team manifest
 )
 
 // Message Types
@@ -30,6 +42,12 @@ type CheckResultMsg struct {
 	RemoteVersion string
 	Status        core.ToolStatus
 	Message       string
+	LocalSource   string
+	RemoteSource  string
+	RemoteStale   bool // From an expired cache; a refresh is on its way
+	Owner         string
+	BinaryPath    string
+	Shadowed      string
 }
 
 type WarmUpFinishedMsg struct{}
@@ -37,34 +55,114 @@ type WarmUpFinishedMsg struct{}
 type UpdateResultMsg struct {
 	Index      int
 	Success    bool
+	Aborted    bool // Cancelled by the user rather than failed
 	Message    string
 	NewVersion string // Capture the new version string
+	Recorded   bool   // A rollback to the previous version was saved
 }
 
+// UpdateStartedMsg is sent when the scheduler hands a tool to a worker.
+// cancel aborts just that update.
+type UpdateStartedMsg struct {
+	Index  int
+	cancel context.CancelFunc
+}
+
+// UpdateOutputMsg carries one line of live output from a running update
+type UpdateOutputMsg struct {
+	Index int
+	Line  updater.OutputLine
+}
+
+// UpdatesFinishedMsg ends an update session; Skipped never started because it was aborted
+type UpdatesFinishedMsg struct {
+	Skipped []int
+}
+
+// DependentsMsg carries what depends on a tool queued for uninstall.
+// Batch is the uninstall confirmation that asked, see beginUninstalls.
+type DependentsMsg struct {
+	Batch      int
+	Index      int
+	Dependents []string
+	Err        error
+}
+
+// HistoryLoadedMsg carries the ledger entries shown by stateHistory, newest first
+type HistoryLoadedMsg struct {
+	Entries []updater.HistoryEntry
+	Err     error
+}
+
+// historyWrittenMsg reports a failed ledger append; nil errors need no message
+type historyWrittenMsg struct {
+	Err error
+}
+
+// liveLine is one line of the merged output shown in the updating modal
+type liveLine struct {
+	Index int
+	Line  updater.OutputLine
+}
+
+// maxLiveLines bounds the merged live output; full output stays in transcripts
+const maxLiveLines = 500
+
 type Model struct {
-	state         sessionState
-	items         []core.ToolState // Using core.ToolState instead of local duplicate
-	detector      *updater.Detector
-	executor      *updater.Executor
-	cursor        int
-	checked       map[int]bool
-	quitting      bool
-	width         int
-	height        int
-	loading       int
-	updating      int
-	totalUpdate   int            // Total items to update
-	updateQueue   []int          // Queue of items to update sequentially
-	currentUpdate int            // Index of the item currently being updated
-	currentLog    string         // Log message showing current command/action
-	progress      progress.Model // Progress bar component
-	searchQuery   string         // Current search query
-	filteredItems []int          // Indices of filtered items
-	splashFrame   int            // Current animation frame for splash screen
-}
-
-func NewModel() Model {
-	inv := core.GetInventory()
+	state            sessionState
+	items            []core.ToolState // Using core.ToolState instead of local duplicate
+	detector         *updater.Detector
+	pins             updater.Pins // Tools held back by config.toml
+	executor         *updater.Executor
+	rollbacks        *updater.Rollbacks // Recipes restoring the version before each upgrade
+	history          *updater.History   // Ledger every update attempt is appended to
+	cursor           int
+	checked          map[int]bool
+	quitting         bool
+	width            int
+	height           int
+	loading          int
+	remoteReady      bool // Warm-up done, remote versions are current
+	updating         int
+	totalUpdate      int                          // Total items to update
+	workers          int                          // Parallel updates (config: update.workers)
+	running          map[int]context.CancelFunc   // Items being updated right now, with their cancel
+	liveLog          []liveLine                   // Tail of the output of all running updates, interleaved
+	currentLog       string                       // Log message showing current command/action
+	progress         progress.Model               // Progress bar component
+	searchQuery      string                       // Current search query
+	filteredItems    []int                        // Indices of filtered items
+	splashFrame      int                          // Current animation frame for splash screen
+	notice           string                       // One-shot status line (e.g. export result), cleared on next key
+	transcripts      map[int][]updater.OutputLine // Update output per item index, kept until the next update session
+	sessionItems     []int                        // Items of the last update session, in queue order
+	logScroll        int                          // Lines scrolled up from the tail of the live output
+	transcriptPos    int                          // Position in sessionItems shown by stateTranscript
+	transcriptScroll int                          // First transcript line shown by stateTranscript
+	updateCtx        context.Context              // Parent of every update in the session
+	cancelSession    context.CancelFunc           // Cancels updateCtx
+	updateStream     <-chan tea.Msg               // Scheduler events, read one at a time by waitForUpdate
+	sessionAction    updater.Action               // Upgrade, install or uninstall, for the whole session
+	uninstallWarns   map[int][]string             // Why removing an item may break things (runtime, dependents)
+	dependentsLeft   int                          // Dependency checks still running for the uninstall confirmation
+	uninstallBatch   int                          // Counts uninstall confirmations, so late checks of a cancelled one are dropped
+	rollbackable     map[int]bool                 // Items of the session whose previous version was recorded
+	startedAt        map[int]time.Time            // When each running update started, for the history
+	historyEntries   []updater.HistoryEntry       // Shown by stateHistory, newest first
+	historyErr       error                        // Why the ledger could not be read
+	historyKey       string                       // Tool key stateHistory is filtered on, "" for all
+	historyPos       int                          // Selected entry in stateHistory
+	historyExpanded  bool                         // Show the selected entry's output
+	manifest         *updater.Manifest            // Team manifest (spark.lock), nil when there is none
+	driftPos         int                          // Selected tool in stateDrift
+	quitWhenIdle     bool                         // Ctrl+C during updates: quit once the child process is gone
+}
+
+// NewModel builds the dashboard. cache may be nil; otherwise its results are
+// shown right away, marked stale once older than its TTL, while they refresh.
+// Upgrades record their rollbacks in rollbacks, which may be nil, and every
+// attempt is appended to history. manifest, when not nil, backs the drift view.
+func NewModel(inv []core.Tool, settings config.Settings, pins updater.Pins, rollbacks *updater.Rollbacks, history *updater.History, manifest *updater.Manifest, cache *updater.Cache) Model {
 	states := make([]core.ToolState, len(inv))
 	for i, t := range inv {
 		states[i] = core.ToolState{
@@ -73,6 +171,7 @@ func NewModel() Model {
 			LocalVersion:  "...",
 			RemoteVersion: "...",
 			Message:       "",
+			Pin:           pins.Spec(t),
 		}
 	}
 
@@ -82,21 +181,39 @@ func NewModel() Model {
 		progress.WithWidth(50),
 	)
 
+	detector := updater.NewDetector()
+	detector.UseRegistries(updater.NewRegistries(updater.RegistryURLs(settings.Registries)))
+	if cache != nil {
+		detector.UseCache(cache)
+		detector.LoadStaleCache()
+	}
+	executor := updater.NewExecutor()
+	executor.UseRollbacks(rollbacks)
+
 	return Model{
-		state:    stateSplash,
-		items:    states,
-		detector: updater.NewDetector(),
-		executor: updater.NewExecutor(),
-		checked:  make(map[int]bool),
-		loading:  len(inv),
-		progress: prog,
+		state:       stateSplash,
+		items:       states,
+		detector:    detector,
+		pins:        pins,
+		executor:    executor,
+		rollbacks:   rollbacks,
+		history:     history,
+		manifest:    manifest,
+		checked:     make(map[int]bool),
+		loading:     len(inv),
+		progress:    prog,
+		transcripts: make(map[int][]updater.OutputLine),
+		running:     make(map[int]context.CancelFunc),
+		startedAt:   make(map[int]time.Time),
+		workers:     settings.Update.Workers,
 	}
 }
 
 func (m Model) checkLocalVersion(i int) tea.Cmd {
 	return func() tea.Msg {
 		t := m.items[i].Tool
-		local := m.detector.GetLocalVersion(t)
+		local, source := m.detector.DetectLocal(t)
+		p, shadowed := m.detector.DetectOwnership(t)
 
 		status := core.StatusInstalled
 		message := ""
@@ -105,33 +222,48 @@ func (m Model) checkLocalVersion(i int) tea.Cmd {
 			message = "Not installed"
 		}
 
-		return CheckResultMsg{
+		msg := CheckResultMsg{
 			Index:         i,
 			LocalVersion:  local,
 			RemoteVersion: "...", // Pending remote check
 			Status:        status,
 			Message:       message,
+			LocalSource:   source,
+			Owner:         string(p.Owner),
+			BinaryPath:    p.Resolved,
+			Shadowed:      shadowed,
 		}
+
+		// Remote data may already be loaded from the disk cache
+		remote, remoteSource, stale := m.detector.DetectRemoteCached(t, local)
+		if remoteSource != updater.SourceNone {
+			msg.RemoteVersion, msg.RemoteSource, msg.RemoteStale = remote, remoteSource, stale
+			if local != "MISSING" && updater.IsKnownVersion(remote) {
+				msg.Status, msg.Message = m.pins.Classify(t, local, remote)
+			}
+		}
+		return msg
 	}
 }
 
 func (m Model) checkRemoteVersion(i int) tea.Cmd {
+	local := m.items[i].LocalVersion
+	if local == "..." {
+		return nil // The local check picks up remote data itself when it lands
+	}
+
 	return func() tea.Msg {
 		t := m.items[i].Tool
-		local := m.items[i].LocalVersion
 
 		// If missing, we still might want to know latest version
-		remote := m.detector.GetRemoteVersion(t, local)
+		remote, source, stale := m.detector.DetectRemoteCached(t, local)
 
 		status := m.items[i].Status
 		message := m.items[i].Message
 
-		// If remote is different from local and not checking/unknown, assume update
-		if remote != "Unknown" && remote != "Checking..." && remote != local {
-			if local != "MISSING" {
-				status = core.StatusOutdated
-				message = "Update available"
-			}
+		// Only a real version comparison decides outdated vs up to date vs ahead
+		if local != "MISSING" && updater.IsKnownVersion(remote) {
+			status, message = m.pins.Classify(t, local, remote)
 		}
 
 		return CheckResultMsg{
@@ -140,84 +272,370 @@ func (m Model) checkRemoteVersion(i int) tea.Cmd {
 			RemoteVersion: remote,
 			Status:        status,
 			Message:       message,
+			LocalSource:   m.items[i].LocalSource,
+			RemoteSource:  source,
+			RemoteStale:   stale,
+			Owner:         m.items[i].Owner,
+			BinaryPath:    m.items[i].BinaryPath,
+			Shadowed:      m.items[i].Shadowed,
+		}
+	}
+}
+
+// runUpdates hands the jobs to a Scheduler in the background. Progress comes
+// back over one channel as UpdateStartedMsg, UpdateOutputMsg and
+// UpdateResultMsg per tool, and a final UpdatesFinishedMsg.
+func (m Model) runUpdates(ctx context.Context, jobs []updater.Job, stream chan<- tea.Msg) {
+	defer close(stream)
+
+	skipped := updater.NewScheduler(m.workers).Run(ctx, jobs, func(j updater.Job) {
+		jobCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stream <- UpdateStartedMsg{Index: j.Index, cancel: cancel}
+		stream <- m.performUpdate(jobCtx, j, stream)
+	})
+
+	finished := UpdatesFinishedMsg{}
+	for _, j := range skipped {
+		finished.Skipped = append(finished.Skipped, j.Index)
+	}
+	stream <- finished
+}
+
+// performUpdate updates one tool, streaming its output as UpdateOutputMsg
+func (m Model) performUpdate(ctx context.Context, j updater.Job, stream chan<- tea.Msg) UpdateResultMsg {
+	i, t := j.Index, j.Tool
+
+	// Capture the installed version first so the summary can offer a rollback
+	var pending *updater.PendingRollback
+	if j.Action == updater.ActionUpgrade {
+		var err error
+		if pending, err = m.executor.RecordRollback(ctx, t); err != nil && !errors.Is(err, updater.ErrNoRollback) {
+			stream <- UpdateOutputMsg{Index: i, Line: updater.OutputLine{Stream: updater.StreamStderr, Text: "spark: " + err.Error()}}
 		}
 	}
+
+	err := m.executor.ApplyStreaming(ctx, j.Action, t, func(line updater.OutputLine) {
+		stream <- UpdateOutputMsg{Index: i, Line: line}
+	})
+	if errors.Is(err, updater.ErrAborted) {
+		return UpdateResultMsg{Index: i, Aborted: true, Message: "Aborted by user"}
+	}
+	if err != nil {
+		return UpdateResultMsg{Index: i, Success: false, Message: err.Error()}
+	}
+
+	// Re-check version to confirm
+	newVer := m.detector.GetLocalVersion(t)
+	// Only an upgrade that changed something replaces the way back
+	recorded, err := pending.Keep(nil, m.items[i].LocalVersion, newVer)
+	if err != nil {
+		stream <- UpdateOutputMsg{Index: i, Line: updater.OutputLine{Stream: updater.StreamStderr, Text: "spark: could not save the rollback: " + err.Error()}}
+	}
+	message := "Updated to " + newVer
+	switch j.Action {
+	case updater.ActionInstall:
+		message = "Installed " + newVer
+	case updater.ActionUninstall:
+		message = "Uninstalled"
+		if newVer != "MISSING" {
+			message = "Uninstalled, another copy remains (" + newVer + ")"
+		}
+	case updater.ActionRollback:
+		message = "Rolled back to " + newVer
+	}
+	if j.Action == updater.ActionUpgrade || j.Action == updater.ActionInstall {
+		// The new version may have landed behind another copy on PATH
+		copies := m.detector.Installations(t)
+		if _, ok := updater.Shadowed(t, copies); ok {
+			message += ", but " + copies[0].Path + " runs first (shadowed)"
+		}
+	}
+	return UpdateResultMsg{
+		Index:      i,
+		Success:    true,
+		Message:    message,
+		NewVersion: newVer,
+		Recorded:   recorded,
+	}
 }
 
-func (m Model) performUpdate(i int) tea.Cmd {
+func waitForUpdate(stream <-chan tea.Msg) tea.Cmd {
 	return func() tea.Msg {
-		t := m.items[i].Tool
-		if err := m.executor.Update(t); err != nil {
-			return UpdateResultMsg{Index: i, Success: false, Message: err.Error()}
+		return <-stream
+	}
+}
+
+// plannedItems returns the selected items action applies to: upgrades skip
+// missing tools, pinned ones without an update inside their pin and those
+// whose binary belongs to an installer Spark cannot upgrade through, installs
+// only take missing tools and uninstalls installed ones, in both cases only
+// when Spark knows the command. Rollbacks take the tools the last session
+// recorded a previous version for.
+func (m Model) plannedItems(action updater.Action) []int {
+	var items []int
+	for i := range m.items {
+		if !m.checked[i] {
+			continue
+		}
+		missing := m.items[i].Status == core.StatusMissing
+		switch action {
+		case updater.ActionInstall:
+			if missing && updater.CanInstall(m.items[i].Tool) {
+				items = append(items, i)
+			}
+		case updater.ActionUninstall:
+			if !missing && updater.CanUninstall(m.items[i].Tool) {
+				items = append(items, i)
+			}
+		case updater.ActionRollback:
+			if m.rollbackable[i] {
+				items = append(items, i)
+			}
+		default:
+			if _, err := updater.Redirect(m.items[i]); !missing && !m.heldByPin(i) && err == nil {
+				items = append(items, i)
+			}
+		}
+	}
+	return items
+}
+
+// heldByPin reports whether item i is pinned and its pin excludes the latest release
+func (m Model) heldByPin(i int) bool {
+	return m.items[i].Pin != "" && m.items[i].Status != core.StatusOutdated
+}
+
+// heldItems returns the selected installed tools their pins keep from upgrading
+func (m Model) heldItems() []int {
+	var items []int
+	for i := range m.items {
+		if m.checked[i] && m.items[i].Status != core.StatusMissing && m.heldByPin(i) {
+			items = append(items, i)
+		}
+	}
+	return items
+}
+
+// foreignItems returns the selected installed tools whose upgrade is refused
+// because another installer owns the binary on PATH (see updater.Redirect)
+func (m Model) foreignItems() []int {
+	var items []int
+	for i := range m.items {
+		if _, err := updater.Redirect(m.items[i]); m.checked[i] && m.items[i].Status != core.StatusMissing && err != nil {
+			items = append(items, i)
+		}
+	}
+	return items
+}
+
+// jobTool is the tool an action on item i runs through: upgrades go to the
+// owner of the binary on PATH
+func (m Model) jobTool(i int, action updater.Action) core.Tool {
+	if action == updater.ActionUpgrade {
+		if t, err := updater.Redirect(m.items[i]); err == nil {
+			return t
+		}
+	}
+	return m.items[i].Tool
+}
+
+// manualItems returns the selected tools an install or uninstall would
+// apply to but that have no command for it
+func (m Model) manualItems(action updater.Action) []int {
+	var items []int
+	for i := range m.items {
+		if !m.checked[i] {
+			continue
+		}
+		t, missing := m.items[i].Tool, m.items[i].Status == core.StatusMissing
+		switch action {
+		case updater.ActionInstall:
+			if missing && !updater.CanInstall(t) {
+				items = append(items, i)
+			}
+		case updater.ActionUninstall:
+			if !missing && !updater.CanUninstall(t) {
+				items = append(items, i)
+			}
+		}
+	}
+	return items
+}
+
+// plannedCommand is the command shown for item i in the current session
+func (m Model) plannedCommand(i int) string {
+	t := m.items[i].Tool
+	if m.sessionAction == updater.ActionRollback {
+		if rb, ok := m.rollbacks.Get(t.Key); ok {
+			return rb.String()
+		}
+	}
+	return updater.PlannedCommand(m.sessionAction, m.jobTool(i, m.sessionAction))
+}
+
+// canRollBack reports whether the summary offers rolling back the session
+func (m Model) canRollBack() bool {
+	return m.sessionAction == updater.ActionUpgrade && len(m.plannedItems(updater.ActionRollback)) > 0
+}
+
+// beginUpgrades enters the runtime confirmation or starts upgrading right away
+func (m *Model) beginUpgrades() tea.Cmd {
+	if len(m.plannedItems(updater.ActionUpgrade)) == 0 {
+		m.state = stateMain
+		m.notice = "Nothing to update: the selected tools are not installed (press + to install)"
+		if n := len(m.heldItems()); n > 0 {
+			m.notice = fmt.Sprintf("Nothing to update: %d selected tool(s) held by their pin", n)
+		}
+		if n := len(m.foreignItems()); n > 0 {
+			m.notice = fmt.Sprintf("Nothing to update: %d selected tool(s) installed by another package manager (see the dry-run)", n)
+		}
+		return nil
+	}
+
+	for _, i := range m.plannedItems(updater.ActionUpgrade) {
+		if m.items[i].Tool.Category == core.CategoryRuntime {
+			m.state = stateConfirm
+			return nil
+		}
+	}
+
+	m.state = stateUpdating
+	return m.startUpdates(updater.ActionUpgrade)
+}
+
+// beginInstalls opens the install confirmation for the selected missing tools
+func (m *Model) beginInstalls() {
+	if len(m.plannedItems(updater.ActionInstall)) == 0 {
+		m.state = stateMain
+		m.notice = "Nothing to install: select tools marked MISSING"
+		if n := len(m.manualItems(updater.ActionInstall)); n > 0 {
+			m.notice = fmt.Sprintf("Nothing to install: %d selected tool(s) need a manual install", n)
 		}
+		return
+	}
+	m.state = stateInstallConfirm
+}
 
-		// Re-check version to confirm
-		newVer := m.detector.GetLocalVersion(t)
-		return UpdateResultMsg{
-			Index:      i,
-			Success:    true,
-			Message:    "Updated to " + newVer,
-			NewVersion: newVer,
+// beginUninstalls opens the uninstall confirmation and looks up what depends
+// on each tool; the confirmation waits for those checks
+func (m *Model) beginUninstalls() tea.Cmd {
+	planned := m.plannedItems(updater.ActionUninstall)
+	if len(planned) == 0 {
+		m.state = stateMain
+		m.notice = "Nothing to uninstall: select installed tools"
+		if n := len(m.manualItems(updater.ActionUninstall)); n > 0 {
+			m.notice = fmt.Sprintf("Nothing to uninstall: %d selected tool(s) need a manual uninstall", n)
 		}
+		return nil
+	}
+
+	m.state = stateUninstallConfirm
+	m.uninstallWarns = make(map[int][]string)
+	m.dependentsLeft = len(planned)
+	m.uninstallBatch++
+	var cmds []tea.Cmd
+	for _, i := range planned {
+		if m.items[i].Tool.Category == core.CategoryRuntime {
+			m.uninstallWarns[i] = append(m.uninstallWarns[i], "runtime: your projects and other tools may need it")
+		}
+		cmds = append(cmds, m.checkDependents(i))
+	}
+	return tea.Batch(cmds...)
+}
+
+// dependentsTimeout bounds one reverse dependency lookup
+const dependentsTimeout = 30 * time.Second
+
+func (m Model) checkDependents(i int) tea.Cmd {
+	t, batch := m.items[i].Tool, m.uninstallBatch
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), dependentsTimeout)
+		defer cancel()
+		deps, err := m.executor.Dependents(ctx, t)
+		return DependentsMsg{Batch: batch, Index: i, Dependents: deps, Err: err}
 	}
 }
 
-func (m *Model) startUpdates() tea.Cmd {
+func (m *Model) startUpdates(action updater.Action) tea.Cmd {
+	m.sessionAction = action
 	m.updating = 0
 	m.totalUpdate = 0
-	m.updateQueue = []int{}
-	m.currentUpdate = -1
+	m.running = make(map[int]context.CancelFunc)
+	m.startedAt = make(map[int]time.Time)
+	m.liveLog = nil
+	m.logScroll = 0
+	m.currentLog = ""
+	m.transcripts = make(map[int][]updater.OutputLine)
+	m.sessionItems = nil
+	m.updateCtx, m.cancelSession = context.WithCancel(context.Background())
 
 	// Build the queue
-	for i := range m.items {
-		if m.checked[i] {
-			m.items[i].Status = core.StatusUpdating // Mark all as pending update
-			m.updateQueue = append(m.updateQueue, i)
-			m.totalUpdate++
-			m.updating++ // We use updating as "remaining" count
-		}
+	var jobs []updater.Job
+	for _, i := range m.plannedItems(action) {
+		m.items[i].Status = core.StatusUpdating // Mark all as pending update
+		jobs = append(jobs, updater.Job{Index: i, Tool: m.jobTool(i, action), Action: action})
+		m.sessionItems = append(m.sessionItems, i)
+		m.totalUpdate++
+		m.updating++ // We use updating as "remaining" count
 	}
 
-	if len(m.updateQueue) == 0 {
-		m.state = stateSummary
+	m.rollbackable = make(map[int]bool) // Filled again by this session's upgrades
+
+	if len(jobs) == 0 {
+		m.finishUpdates()
 		return nil
 	}
 
-	// Start the first one
+	stream := make(chan tea.Msg, 64)
+	m.updateStream = stream
+	go m.runUpdates(m.updateCtx, jobs, stream)
+
 	return tea.Batch(
-		m.processNextUpdate(),
+		waitForUpdate(m.updateStream),
 		refreshTick(), // Start animation
 	)
 }
 
-func (m *Model) processNextUpdate() tea.Cmd {
-	if len(m.updateQueue) == 0 {
-		return nil
+// abortCurrent cancels the running updates; queued tools still start afterwards
+func (m *Model) abortCurrent() {
+	for i, cancel := range m.running {
+		cancel()
+		m.items[i].Message = "Aborting..."
+	}
+	if len(m.running) > 0 {
+		m.currentLog = "> Aborting running updates..."
 	}
+}
 
-	// Pop next item
-	index := m.updateQueue[0]
-	m.updateQueue = m.updateQueue[1:]
-	m.currentUpdate = index
+// abortQueue cancels the whole session: running updates stop and nothing
+// queued starts (the scheduler reports those as skipped)
+func (m *Model) abortQueue() {
+	if m.cancelSession != nil {
+		m.cancelSession()
+	}
+	m.currentLog = "> Aborting all updates..."
+}
 
-	// Set descriptive log message
-	tool := m.items[index].Tool
-	switch tool.Method {
-	case core.MethodBrew, core.MethodBrewPkg:
-		m.currentLog = "> brew upgrade " + tool.Package
-	case core.MethodNpmPkg, core.MethodNpmSys, core.MethodClaude:
-		m.currentLog = "> npm install -g " + tool.Package + "@latest"
-	case core.MethodOmz:
-		m.currentLog = "> $ZSH/tools/upgrade.sh"
-	case core.MethodToad:
-		m.currentLog = "> curl -fsSL batrachian.ai/install | sh"
-	case core.MethodMacApp:
-		m.currentLog = "> brew upgrade --cask " + tool.Package
-	default:
-		m.currentLog = "> Updating " + tool.Name + "..."
+// finishUpdates releases the session context and shows the summary
+func (m *Model) finishUpdates() {
+	if m.cancelSession != nil {
+		m.cancelSession()
+		m.cancelSession = nil
 	}
+	m.updateStream = nil
+	m.state = stateSummary
+}
 
-	return m.performUpdate(index)
+// runningItems returns the indices being updated right now, in dashboard order
+func (m Model) runningItems() []int {
+	var items []int
+	for _, i := range m.sessionItems {
+		if _, ok := m.running[i]; ok {
+			items = append(items, i)
+		}
+	}
+	return items
 }
 
 func (m Model) checkAllLocalVersions() tea.Cmd {
@@ -243,6 +661,19 @@ func (m Model) warmUpCache() tea.Cmd {
 	}
 }
 
+// saveCache persists detected versions in the background; failures only cost speed
+func (m Model) saveCache() tea.Cmd {
+	return func() tea.Msg {
+		m.detector.SaveCache()
+		return nil
+	}
+}
+
+// SaveCache writes the version cache, including versions detected after updates
+func (m Model) SaveCache() error {
+	return m.detector.SaveCache()
+}
+
 type TickMsg time.Time
 type AnimateMsg time.Time
 type RefreshMsg time.Time
@@ -281,62 +712,128 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case WarmUpFinishedMsg:
-		return m, m.checkAllRemoteVersions()
+		m.remoteReady = true
+		return m, tea.Batch(m.checkAllRemoteVersions(), m.saveCache())
 
 	case CheckResultMsg:
 		m.items[msg.Index].LocalVersion = msg.LocalVersion
 		m.items[msg.Index].RemoteVersion = msg.RemoteVersion
 		m.items[msg.Index].Status = msg.Status
 		m.items[msg.Index].Message = msg.Message
+		m.items[msg.Index].LocalSource = msg.LocalSource
+		m.items[msg.Index].RemoteSource = msg.RemoteSource
+		m.items[msg.Index].RemoteStale = msg.RemoteStale
+		m.items[msg.Index].Owner = msg.Owner
+		m.items[msg.Index].BinaryPath = msg.BinaryPath
+		m.items[msg.Index].Shadowed = msg.Shadowed
 		m.loading--
+
+		// A local result that raced the end of the warm-up still needs current remote data
+		if m.remoteReady && (msg.RemoteVersion == "..." || msg.RemoteStale) {
+			return m, m.checkRemoteVersion(msg.Index)
+		}
 		return m, nil
 
+	case UpdateStartedMsg:
+		m.running[msg.Index] = msg.cancel
+		m.startedAt[msg.Index] = time.Now()
+		m.currentLog = "> " + m.plannedCommand(msg.Index)
+		return m, waitForUpdate(m.updateStream)
+
+	case UpdateOutputMsg:
+		m.transcripts[msg.Index] = append(m.transcripts[msg.Index], msg.Line)
+		m.liveLog = append(m.liveLog, liveLine{Index: msg.Index, Line: msg.Line})
+		if len(m.liveLog) > maxLiveLines {
+			m.liveLog = m.liveLog[len(m.liveLog)-maxLiveLines:]
+		}
+		if m.logScroll > 0 {
+			m.logScroll = min(m.logScroll+1, max(len(m.liveLog)-liveLogLines, 0)) // Keep the scrolled-back view still
+		}
+		return m, waitForUpdate(m.updateStream)
+
 	case UpdateResultMsg:
-		if msg.Success {
+		delete(m.running, msg.Index)
+		if msg.Recorded {
+			m.rollbackable[msg.Index] = true
+		}
+		// Before the item takes its new version
+		record := m.recordHistory(m.historyEntry(msg))
+
+		if msg.Aborted {
+			m.items[msg.Index].Status = core.StatusAborted
+			m.items[msg.Index].Message = msg.Message
+		} else if msg.Success {
 			m.items[msg.Index].Status = core.StatusUpdated
 			m.items[msg.Index].Message = msg.Message
 			// Update the version in the model immediately
-			if msg.NewVersion != "" && msg.NewVersion != "MISSING" {
+			if m.sessionAction == updater.ActionUninstall {
+				// Usually MISSING, unless another copy is still on PATH
 				m.items[msg.Index].LocalVersion = msg.NewVersion
-				// Assuming successful update brings it to latest known remote
-				m.items[msg.Index].RemoteVersion = msg.NewVersion 
+			} else if msg.NewVersion != "" && msg.NewVersion != "MISSING" {
+				m.items[msg.Index].LocalVersion = msg.NewVersion
+				if m.sessionAction != updater.ActionRollback {
+					// Assuming successful update brings it to latest known remote
+					m.items[msg.Index].RemoteVersion = msg.NewVersion
+				}
 			}
 		} else {
 			m.items[msg.Index].Status = core.StatusFailed
 			m.items[msg.Index].Message = msg.Message
 		}
-		
+
 		m.updating-- // Decrease remaining count
-		m.currentUpdate = -1
+		return m, tea.Batch(waitForUpdate(m.updateStream), record)
 
-		if m.updating == 0 && len(m.updateQueue) == 0 {
-			m.state = stateSummary
-			return m, nil
+	case historyWrittenMsg:
+		if msg.Err != nil {
+			m.notice = "Could not write the update history: " + msg.Err.Error()
 		}
-		
-		// Trigger next update
-		return m, m.processNextUpdate()
+		return m, nil
+
+	case HistoryLoadedMsg:
+		m.historyEntries, m.historyErr = msg.Entries, msg.Err
+		m.historyPos = 0
+		m.historyExpanded = false
+		return m, nil
+
+	case DependentsMsg:
+		if m.state != stateUninstallConfirm || msg.Batch != m.uninstallBatch {
+			return m, nil // Cancelled, or from a confirmation cancelled before the check finished
+		}
+		m.dependentsLeft--
+		switch {
+		case msg.Err != nil:
+			m.uninstallWarns[msg.Index] = append(m.uninstallWarns[msg.Index], "could not check dependents: "+msg.Err.Error())
+		case len(msg.Dependents) > 0:
+			m.uninstallWarns[msg.Index] = append(m.uninstallWarns[msg.Index], "required by "+strings.Join(msg.Dependents, ", "))
+		}
+		return m, nil
+
+	case UpdatesFinishedMsg:
+		for _, i := range msg.Skipped {
+			m.items[i].Status = core.StatusSkipped
+			m.items[i].Message = "Skipped (queue aborted)"
+			m.updating--
+		}
+
+		m.finishUpdates()
+		if m.quitWhenIdle {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		return m, nil
 
 	case tea.KeyMsg:
 		if m.state == statePreview {
 			switch msg.String() {
 			case "enter":
 				// Proceed with updates - check for dangerous runtimes first
-				hasCritical := false
-				for i := range m.items {
-					if m.checked[i] && m.items[i].Tool.Category == core.CategoryRuntime {
-						hasCritical = true
-						break
-					}
-				}
-
-				if hasCritical {
-					m.state = stateConfirm
-					return m, nil
-				}
-
-				m.state = stateUpdating
-				return m, m.startUpdates()
+				return m, m.beginUpgrades()
+			case "+":
+				m.beginInstalls()
+				return m, nil
+			case "-":
+				return m, m.beginUninstalls()
 			case "esc", "q":
 				// Cancel and return to main
 				m.state = stateMain
@@ -349,7 +846,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch msg.String() {
 			case "y", "Y":
 				m.state = stateUpdating
-				return m, m.startUpdates()
+				return m, m.startUpdates(updater.ActionUpgrade)
 			case "n", "N", "esc", "q":
 				m.state = stateMain
 				return m, nil
@@ -357,46 +854,167 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if m.state == stateInstallConfirm {
+			switch msg.String() {
+			case "y", "Y":
+				m.state = stateUpdating
+				return m, m.startUpdates(updater.ActionInstall)
+			case "n", "N", "esc", "q":
+				m.state = stateMain
+				return m, nil
+			}
+			return m, nil
+		}
+
+		if m.state == stateUninstallConfirm {
+			switch msg.String() {
+			case "y", "Y":
+				if m.dependentsLeft > 0 {
+					return m, nil // Confirm only once every warning is known
+				}
+				m.state = stateUpdating
+				return m, m.startUpdates(updater.ActionUninstall)
+			case "n", "N", "esc", "q":
+				m.state = stateMain
+				return m, nil
+			}
+			return m, nil
+		}
+
+		if m.state == stateRollbackConfirm {
+			switch msg.String() {
+			case "y", "Y":
+				m.state = stateUpdating
+				return m, m.startUpdates(updater.ActionRollback)
+			case "n", "N", "esc", "q":
+				m.state = stateSummary
+				return m, nil
+			}
+			return m, nil
+		}
+
 		if m.state == stateSplash {
 			m.state = stateMain
 			return m, nil
 		}
 
+		if m.state == stateHistory {
+			switch msg.String() {
+			case "up", "k":
+				m.historyPos = max(m.historyPos-1, 0)
+			case "down", "j":
+				m.historyPos = min(m.historyPos+1, max(len(m.historyEntries)-1, 0))
+			case "pgup":
+				m.historyPos = max(m.historyPos-m.historyPageSize(), 0)
+			case "pgdown", " ":
+				m.historyPos = min(m.historyPos+m.historyPageSize(), max(len(m.historyEntries)-1, 0))
+			case "enter":
+				m.historyExpanded = !m.historyExpanded
+			case "t", "T":
+				// Toggle between every tool and the one under the dashboard cursor
+				if m.historyKey == "" {
+					m.historyKey = m.items[m.cursor].Tool.Key
+				} else {
+					m.historyKey = ""
+				}
+				return m, m.loadHistory()
+			case "esc", "q", "h", "H":
+				m.state = stateMain
+			}
+			return m, nil
+		}
+
+		if m.state == stateDrift {
+			switch msg.String() {
+			case "up", "k":
+				m.driftPos = max(m.driftPos-1, 0)
+			case "down", "j":
+				rows, _, _ := m.drift()
+				m.driftPos = min(m.driftPos+1, max(len(rows)-1, 0))
+			case "s", "S":
+				// Hand the fixable tools to the dashboard, where ENTER upgrades and + installs
+				if n := m.selectFixable(); n > 0 {
+					m.notice = fmt.Sprintf("Selected %d tool(s) from %s: [ENTER] upgrades, [+] installs", n, m.manifest.Path)
+				} else {
+					m.notice = "Nothing in the manifest drift can be installed or upgraded"
+				}
+				m.state = stateMain
+			case "esc", "q", "m", "M":
+				m.state = stateMain
+			}
+			return m, nil
+		}
+
 		if m.state == stateUpdating {
-			if msg.String() == "ctrl+c" {
-				m.quitting = true
-				return m, tea.Quit
+			switch msg.String() {
+			case "ctrl+c":
+				// Stop the child process group first, quit once its result arrives
+				m.quitWhenIdle = true
+				m.abortQueue()
+			case "x":
+				m.abortCurrent()
+			case "X":
+				m.abortQueue()
+			case "up", "k":
+				m.logScroll = min(m.logScroll+1, max(len(m.liveLog)-liveLogLines, 0))
+			case "down", "j":
+				m.logScroll = max(m.logScroll-1, 0)
+			case "end":
+				m.logScroll = 0
+			}
+			return m, nil
+		}
+
+		if m.state == stateTranscript {
+			switch msg.String() {
+			case "up", "k":
+				m.transcriptScroll = max(m.transcriptScroll-1, 0)
+			case "down", "j":
+				m.transcriptScroll = min(m.transcriptScroll+1, m.maxTranscriptScroll())
+			case "pgup":
+				m.transcriptScroll = max(m.transcriptScroll-m.transcriptPageSize(), 0)
+			case "pgdown", " ":
+				m.transcriptScroll = min(m.transcriptScroll+m.transcriptPageSize(), m.maxTranscriptScroll())
+			case "left", "h", "shift+tab":
+				m.transcriptPos = (m.transcriptPos + len(m.sessionItems) - 1) % len(m.sessionItems)
+				m.transcriptScroll = 0
+			case "right", "l", "tab":
+				m.transcriptPos = (m.transcriptPos + 1) % len(m.sessionItems)
+				m.transcriptScroll = 0
+			case "esc", "q", "enter":
+				m.state = stateSummary
 			}
 			return m, nil
 		}
 
 		if m.state == stateSummary {
+			if (msg.String() == "l" || msg.String() == "L") && len(m.sessionItems) > 0 {
+				m.state = stateTranscript
+				m.transcriptPos = 0
+				m.transcriptScroll = 0
+				return m, nil
+			}
+			if (msg.String() == "r" || msg.String() == "R") && m.canRollBack() {
+				m.state = stateRollbackConfirm
+				return m, nil
+			}
+
 			// Return to main dashboard instead of quitting
 			// Clear selections and reset state
 			m.state = stateMain
 			m.checked = make(map[int]bool)
 			m.totalUpdate = 0
 			m.updating = 0
-			
-			// Clean up statuses: Reset Updated/Failed items
+
+			// Clean up statuses: Reset items touched by the update session
 			for i := range m.items {
-				if m.items[i].Status == core.StatusUpdated || m.items[i].Status == core.StatusFailed {
-					m.items[i].Message = "" // Clear messages
-					
-					// Determine correct resting state based on versions
-					if m.items[i].LocalVersion == "MISSING" {
-						m.items[i].Status = core.StatusMissing
-					} else if m.items[i].RemoteVersion != "..." && 
-							  m.items[i].RemoteVersion != "Checking..." && 
-							  m.items[i].RemoteVersion != "Unknown" &&
-							  m.items[i].RemoteVersion != m.items[i].LocalVersion {
-						m.items[i].Status = core.StatusOutdated
-					} else {
-						m.items[i].Status = core.StatusInstalled
-					}
+				switch m.items[i].Status {
+				case core.StatusUpdated, core.StatusFailed, core.StatusAborted, core.StatusSkipped:
+					// Determine correct resting state (and message) based on versions
+					m.items[i].Status, m.items[i].Message = m.pins.Classify(m.items[i].Tool, m.items[i].LocalVersion, m.items[i].RemoteVersion)
 				}
 			}
-			
+
 			return m, nil
 		}
 
@@ -431,6 +1049,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		m.notice = ""
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			m.quitting = true
@@ -501,11 +1121,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "g", "G", "a", "A":
-			// Toggle selection for all items in current category
+			// Toggle selection for all items in current category; pinned
+			// tools are left alone and can only be selected one by one
 			currentCat := m.items[m.cursor].Tool.Category
 			allSelected := true
 			for i, item := range m.items {
-				if item.Tool.Category == currentCat {
+				if item.Tool.Category == currentCat && item.Pin == "" {
 					if !m.checked[i] {
 						allSelected = false
 						break
@@ -513,7 +1134,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			for i, item := range m.items {
-				if item.Tool.Category == currentCat {
+				if item.Tool.Category == currentCat && item.Pin == "" {
 					if allSelected {
 						delete(m.checked, i)
 					} else {
@@ -522,6 +1143,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+		case "h", "H":
+			// Browse past update attempts, newest first
+			m.state = stateHistory
+			m.historyKey = ""
+			m.historyEntries, m.historyErr = nil, nil
+			return m, m.loadHistory()
+
+		case "m", "M":
+			// Compare the tools with the team manifest
+			if m.manifest == nil {
+				m.notice = "No manifest: add a spark.lock (spark export) or pass --manifest"
+				return m, nil
+			}
+			m.state = stateDrift
+			m.driftPos = 0
+			return m, nil
+
+		case "e", "E":
+			// Export the current check results as a JSON report in the working directory
+			m.notice = m.exportReport()
+			return m, nil
+
 		case "d", "D":
 			// Dry-run preview mode - show what would be updated
 			if m.loading > 0 {
@@ -533,29 +1176,35 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.state = statePreview
 			return m, nil
 
-		case "enter":
+		case "+":
+			// Install missing tools, behind their own confirmation
 			if m.loading > 0 {
 				return m, nil
 			}
 			if len(m.checked) == 0 {
 				m.checked[m.cursor] = true
 			}
+			m.beginInstalls()
+			return m, nil
 
-			hasCritical := false
-			for i := range m.items {
-				if m.checked[i] && m.items[i].Tool.Category == core.CategoryRuntime {
-					hasCritical = true
-					break
-				}
+		case "-":
+			// Uninstall, behind a confirmation listing what may break
+			if m.loading > 0 {
+				return m, nil
 			}
+			if len(m.checked) == 0 {
+				m.checked[m.cursor] = true
+			}
+			return m, m.beginUninstalls()
 
-			if hasCritical {
-				m.state = stateConfirm
+		case "enter":
+			if m.loading > 0 {
 				return m, nil
 			}
-
-			m.state = stateUpdating
-			return m, m.startUpdates()
+			if len(m.checked) == 0 {
+				m.checked[m.cursor] = true
+			}
+			return m, m.beginUpgrades()
 		}
 
 	case TickMsg:
@@ -599,6 +1248,33 @@ func (m *Model) jumpToCategory(cat core.Category) {
 	}
 }
 
+// exportReport writes the dashboard state with the same serializer as `spark check --format json`
+func (m Model) exportReport() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "Export failed: " + err.Error()
+	}
+	path, err := report.WriteFile(dir, m.items)
+	if err != nil {
+		return "Export failed: " + err.Error()
+	}
+	if m.checksPending() {
+		return "Exported partial report (checks still running) to " + path
+	}
+	return "Exported report to " + path
+}
+
+// checksPending reports whether any item still waits for its local or remote
+// check. m.loading only counts local checks.
+func (m Model) checksPending() bool {
+	for _, item := range m.items {
+		if item.Status == core.StatusChecking || item.RemoteVersion == "..." {
+			return true
+		}
+	}
+	return false
+}
+
 // --- Search Functionality ---
 
 func (m *Model) updateFilter() {
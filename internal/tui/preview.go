@@ -6,6 +6,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/updater"
 )
 
 // ViewPreview renders the dry-run preview screen
@@ -21,28 +22,32 @@ func (m Model) ViewPreview() string {
 	// Introduction
 	intro := lipgloss.NewStyle().
 		Foreground(cGray).
-		Render("Review the tools that will be updated. No changes will be made yet.\n")
+		Render("Review the tools that will be updated and the exact commands that will run. No changes will be made yet.\n")
 
-	// Count selected tools by category
+	// Count planned upgrades by category; installs are listed on their own
 	selectedByCategory := make(map[core.Category][]core.ToolState)
 	totalSelected := 0
 	hasDangerous := false
 
-	for i, item := range m.items {
-		if m.checked[i] {
-			totalSelected++
-			selectedByCategory[item.Tool.Category] = append(selectedByCategory[item.Tool.Category], item)
-			if item.Tool.Category == core.CategoryRuntime {
-				hasDangerous = true
-			}
+	for _, i := range m.plannedItems(updater.ActionUpgrade) {
+		item := m.items[i]
+		totalSelected++
+		selectedByCategory[item.Tool.Category] = append(selectedByCategory[item.Tool.Category], item)
+		if item.Tool.Category == core.CategoryRuntime {
+			hasDangerous = true
 		}
 	}
+	installs := m.plannedItems(updater.ActionInstall)
+	manual := m.manualItems(updater.ActionInstall)
+	held := m.heldItems()
+	foreign := m.foreignItems()
 
 	// Summary box
 	summaryLines := []string{
 		lipgloss.NewStyle().Foreground(cPurple).Bold(true).Render("SUMMARY"),
 		"",
-		fmt.Sprintf("Total Tools Selected: %d", totalSelected),
+		fmt.Sprintf("Total Tools Selected: %d", totalSelected+len(installs)+len(manual)+len(held)+len(foreign)),
+		fmt.Sprintf("Upgrades: %d  •  Installs: %d", totalSelected, len(installs)),
 	}
 
 	// Add category breakdown
@@ -71,20 +76,55 @@ func (m Model) ViewPreview() string {
 
 		for _, tool := range tools {
 			statusIcon := "→"
-			versionInfo := ""
-
-			if tool.LocalVersion != "" && tool.LocalVersion != "..." && tool.LocalVersion != "MISSING" {
-				versionInfo = lipgloss.NewStyle().
-					Foreground(cGray).
-					Render(fmt.Sprintf(" (current: %s)", tool.LocalVersion))
-			} else if tool.Status == core.StatusMissing {
-				versionInfo = lipgloss.NewStyle().
-					Foreground(cYellow).
-					Render(" (will install)")
+			plan := m.executor.Plan(updater.ActionUpgrade, tool)
+			toolsList += fmt.Sprintf("  %s %s%s\n", statusIcon, tool.Tool.Name, m.renderPlan(plan))
+			if tool.Shadowed != "" && plan.Note == "" {
+				toolsList += "      " + lipgloss.NewStyle().Foreground(cYellow).Render(truncateLine("⇅ shadowed: upgrades "+tool.Shadowed+" but "+tool.BinaryPath+" runs first", m.transcriptWidth()-6)) + "\n"
 			}
+		}
+	}
+
+	// Pinned tools stay where they are unless the latest release fits the pin
+	if len(held) > 0 {
+		toolsList += lipgloss.NewStyle().
+			Foreground(cYellow).
+			Bold(true).
+			Render("\nPINNED (skipped)") + "\n"
+		for _, i := range held {
+			item := m.items[i]
+			toolsList += fmt.Sprintf("  🔒 %s%s\n", item.Tool.Name, lipgloss.NewStyle().
+				Foreground(cGray).
+				Render(fmt.Sprintf(" (%s, pin %s)", item.LocalVersion, item.Pin)))
+		}
+	}
 
-			line := fmt.Sprintf("  %s %s%s\n", statusIcon, tool.Tool.Name, versionInfo)
-			toolsList += line
+	// Upgrading through the tool's method would miss the binary actually in use
+	if len(foreign) > 0 {
+		toolsList += lipgloss.NewStyle().
+			Foreground(cYellow).
+			Bold(true).
+			Render("\nOTHER INSTALLER (skipped)") + "\n"
+		for _, i := range foreign {
+			_, err := updater.Redirect(m.items[i])
+			toolsList += fmt.Sprintf("  ⚠ %s%s\n", m.items[i].Tool.Name, lipgloss.NewStyle().
+				Foreground(cGray).
+				Render("\n      "+truncateLine(err.Error(), m.transcriptWidth()-6)))
+		}
+	}
+
+	// Missing tools are never upgraded; they need the separate install step
+	if len(installs)+len(manual) > 0 {
+		toolsList += lipgloss.NewStyle().
+			Foreground(cYellow).
+			Bold(true).
+			Render("\nINSTALLS (press + to confirm)") + "\n"
+		for _, i := range installs {
+			toolsList += fmt.Sprintf("  + %s%s\n", m.items[i].Tool.Name, m.renderPlan(m.executor.Plan(updater.ActionInstall, m.items[i])))
+		}
+		for _, i := range manual {
+			toolsList += fmt.Sprintf("  ✘ %s%s\n", m.items[i].Tool.Name, lipgloss.NewStyle().
+				Foreground(cGray).
+				Render(" (manual install required)"))
 		}
 	}
 
@@ -102,8 +142,53 @@ func (m Model) ViewPreview() string {
 	// Actions
 	actions := lipgloss.NewStyle().
 		Foreground(cGray).
-		Render("\n[ENTER] Proceed with Updates • [ESC] Cancel")
+		Render("\n[ENTER] Proceed with Updates • [+] Install Missing • [-] Uninstall • [ESC] Cancel")
 
 	content := title + "\n\n" + intro + "\n" + summaryBox + "\n" + toolsList + dangerWarning + actions
 	return appStyle.Render(content)
 }
+
+// renderPlan renders the version change of a plan after the tool name, then
+// the redirect to the binary's owner if any and its commands one per line:
+// probes marked ?, retries ↻, with sudo and remote scripts flagged
+func (m Model) renderPlan(p updater.Plan) string {
+	gray := lipgloss.NewStyle().Foreground(cGray)
+	versions := p.From
+	if p.Target != "" {
+		versions += " → " + p.Target
+	}
+	out := ""
+	if versions != "" {
+		out = gray.Render(" (" + versions + ")")
+	}
+
+	width := m.transcriptWidth() - 6
+	if p.Note != "" {
+		out += "\n      " + lipgloss.NewStyle().Foreground(cYellow).Render(truncateLine("↪ "+p.Note+", upgrading "+p.Tool.Package+" there", width))
+	}
+	for _, s := range p.Steps {
+		marker := "$"
+		note := ""
+		switch {
+		case s.Probe:
+			marker, note = "?", " if it fails: "+s.Note
+		case s.RetryOn != "":
+			marker, note = "↻", " on "+s.RetryOn
+		}
+		var flags []string
+		if s.Privileged {
+			flags = append(flags, lipgloss.NewStyle().Foreground(cYellow).Render("[sudo]"))
+		}
+		if s.Remote {
+			flags = append(flags, lipgloss.NewStyle().Foreground(cRed).Render("[remote script]"))
+		}
+		out += "\n      " + gray.Render(truncateLine(marker+" "+s.Shell(), width)+note)
+		if len(flags) > 0 {
+			out += " " + strings.Join(flags, " ")
+		}
+	}
+	if p.Manual != "" {
+		out += "\n      " + lipgloss.NewStyle().Foreground(cRed).Render("✘ "+p.Manual)
+	}
+	return out
+}
@@ -78,16 +78,25 @@ STATE DESCRIPTIONS:
    - Entry: From splash, search, preview, or confirm (cancel)
    - User Actions:
      * Navigation: ↑/↓, j/k, C/T/I/P/F/U/R/S (category jumps), TAB
-     * Selection: SPACE (toggle), G (group), A (all)
+     * Selection: SPACE (toggle), G (group), A (all); G/A skip pinned tools
      * Search: / (enter search mode)
      * Preview: D (dry-run preview)
+     * Export: E (write JSON report to the working directory)
+     * History: H (past update attempts)
+     * Manifest: M (drift from spark.lock, when one was loaded)
      * Update: ENTER (check for dangerous runtimes)
+     * Install: + (missing tools among the selection, or the cursor row)
+     * Uninstall: - (installed tools among the selection, or the cursor row)
      * Quit: Q, Ctrl+C, ESC (if no filter active)
    - Exit Paths:
      * -> stateSearch (/)
      * -> statePreview (D)
      * -> stateConfirm (ENTER + has runtimes)
      * -> stateUpdating (ENTER + no runtimes)
+     * -> stateInstallConfirm (+)
+     * -> stateUninstallConfirm (-)
+     * -> stateHistory (H)
+     * -> stateDrift (M)
      * -> EXIT (Q, Ctrl+C, ESC)
 
 3. stateSearch
@@ -110,14 +119,20 @@ STATE DESCRIPTIONS:
      * Total selected tools count
      * Breakdown by category
      * List of tools to be updated
+     * Pinned tools held back by their pin
+     * Missing tools to install, with their commands
      * Current versions
      * Warning if runtimes included
    - User Actions:
      * ENTER: Proceed with update (check for runtimes)
+     * +: Install the missing tools
+     * -: Uninstall the installed tools
      * ESC/Q: Cancel and return to main
    - Exit Paths:
      * -> stateConfirm (ENTER + has runtimes)
      * -> stateUpdating (ENTER + no runtimes)
+     * -> stateInstallConfirm (+)
+     * -> stateUninstallConfirm (-)
      * -> stateMain (ESC/Q)
 
 5. stateConfirm
@@ -138,15 +153,23 @@ STATE DESCRIPTIONS:
      * -> stateMain (N/ESC/Q)
 
 6. stateUpdating
-   - Entry: From stateMain, statePreview, or stateConfirm (confirmed)
+   - Entry: From stateMain, statePreview, stateConfirm, stateInstallConfirm,
+     stateUninstallConfirm or stateRollbackConfirm (confirmed)
    - Behavior:
-     * Execute updates in parallel via Goroutines
+     * Run up to update.workers tools at once via updater.Scheduler; tools
+       sharing a package manager lock (brew, apt, npm...) run one at a time
      * Display progress bar
      * Show live status updates
+     * Stream the running commands' stdout/stderr into the modal (tail),
+       prefixed with the tool's binary when more than one worker runs
+     * Keep each tool's full output in Model.transcripts
      * Dim non-selected items
      * Highlight currently updating items
    - User Actions:
-     * Ctrl+C: Emergency exit (kills program)
+     * ↑/↓, j/k: Scroll back through the live output, END: follow again
+     * X: Abort the running updates (their process groups get SIGTERM), continue the queue
+     * Shift+X: Abort the running updates and skip everything still queued
+     * Ctrl+C: Shift+X, then exit once the child processes are gone
      * All other keys: Ignored
    - Exit Paths:
      * -> stateSummary (when all updates complete)
@@ -155,18 +178,95 @@ STATE DESCRIPTIONS:
    - Entry: From stateUpdating (automatic when complete)
    - Display:
      * Success rate percentage
-     * Count of successful/failed/skipped
+     * Count of successful/failed/aborted/skipped
      * List of updated tools with versions
      * List of failed tools with error messages
    - User Actions:
-     * Any key: Exit application
+     * L: Review update logs
+     * R: Roll back the upgraded or failed tools whose previous version was
+       recorded before the upgrade (upgrade sessions only)
+     * Any other key: Return to dashboard
    - Exit Paths:
-     * -> EXIT (any key)
+     * -> stateTranscript (L)
+     * -> stateRollbackConfirm (R)
+     * -> stateMain (any other key)
+
+8. stateTranscript
+   - Entry: From stateSummary (L)
+   - Display: Full output of one tool from the last update session
+   - User Actions:
+     * ↑/↓, PGUP/PGDN: Scroll
+     * ←/→, TAB: Previous/next tool
+     * ESC/Q/ENTER: Back to summary
+   - Exit Paths:
+     * -> stateSummary (ESC/Q/ENTER)
+
+9. stateInstallConfirm
+   - Entry: From stateMain or statePreview (+)
+   - Display: Missing tools and the command installing each; tools without
+     an installer (manual, vendor downloads) are listed as skipped
+   - User Actions:
+     * Y: Install them (stateUpdating with updater.ActionInstall)
+     * N/ESC/Q: Cancel
+   - Exit Paths:
+     * -> stateUpdating (Y)
+     * -> stateMain (N/ESC/Q)
+
+10. stateUninstallConfirm
+   - Entry: From stateMain or statePreview (-)
+   - Display: Installed tools and the command removing each, with warnings
+     for RUNTIME tools and for reverse dependencies reported by the package
+     manager (brew uses --installed); tools without an uninstaller are skipped
+   - User Actions:
+     * Y: Uninstall them (ignored until every dependency check has answered)
+     * N/ESC/Q: Cancel
+   - Exit Paths:
+     * -> stateUpdating (Y)
+     * -> stateMain (N/ESC/Q)
+
+11. stateRollbackConfirm
+   - Entry: From stateSummary (R)
+   - Display: Tools going back to the version recorded before the upgrade,
+     with the recorded commands (updater.Rollbacks, rollbacks.json)
+   - User Actions:
+     * Y: Roll them back (stateUpdating with updater.ActionRollback)
+     * N/ESC/Q: Back to the summary
+   - Exit Paths:
+     * -> stateUpdating (Y)
+     * -> stateSummary (N/ESC/Q)
+
+12. stateHistory
+   - Entry: From stateMain (H)
+   - Display: Update attempts from the history ledger (history.jsonl), newest
+     first: time, tool, action, versions, duration and result. Every update,
+     install, uninstall and rollback is appended when its result arrives.
+   - User Actions:
+     * ↑/↓, PGUP/PGDN: Select an entry
+     * ENTER: Show or hide the selected entry's error and output tail
+     * T: Only the tool under the dashboard cursor / every tool
+     * ESC/Q/H: Back to the dashboard
+   - Exit Paths:
+     * -> stateMain (ESC/Q/H)
+
+13. stateDrift
+   - Entry: From stateMain (M), only when a manifest was loaded (./spark.lock
+     or --manifest)
+   - Display: Tools the manifest lists whose installed version does not meet
+     their requirement, with local, required and latest versions and the plan
+     step: install/upgrade (green, Spark can run it), downgrade (yellow) or
+     manual (red). The selected tool's reason is shown underneath.
+   - User Actions:
+     * ↑/↓: Select a tool
+     * S: Select every install and upgrade on the dashboard
+     * ESC/Q/M: Back to the dashboard
+   - Exit Paths:
+     * -> stateMain (S/ESC/Q/M)
 
 INVARIANTS:
 - Only ONE item can have cursor at a time
 - Cursor must always point to a valid item index
 - During stateUpdating, state cannot change except to stateSummary or EXIT
+- Every update runs under Model.updateCtx; no child process outlives an abort
 - Search filter persists across state transitions until cleared
 - Selected items (checked map) persist across all states
 */
@@ -179,20 +279,41 @@ func (m *Model) validateStateTransition(from, to sessionState) bool {
 			stateSearch,
 			statePreview,
 			stateConfirm,
+			stateInstallConfirm,
+			stateUninstallConfirm,
 			stateUpdating,
+			stateHistory,
+			stateDrift,
 		},
 		stateSearch: {stateMain},
 		statePreview: {
 			stateMain,
 			stateConfirm,
+			stateInstallConfirm,
+			stateUninstallConfirm,
 			stateUpdating,
 		},
 		stateConfirm: {
 			stateMain,
 			stateUpdating,
 		},
-		stateUpdating: {stateSummary},
-		stateSummary:  {}, // Terminal state (only exits)
+		stateInstallConfirm: {
+			stateMain,
+			stateUpdating,
+		},
+		stateUninstallConfirm: {
+			stateMain,
+			stateUpdating,
+		},
+		stateRollbackConfirm: {
+			stateSummary,
+			stateUpdating,
+		},
+		stateUpdating:   {stateSummary},
+		stateSummary:    {stateMain, stateTranscript, stateRollbackConfirm},
+		stateTranscript: {stateSummary},
+		stateHistory:    {stateMain},
+		stateDrift:      {stateMain},
 	}
 
 	allowed := validTransitions[from]
@@ -216,13 +337,19 @@ func (m *Model) safeTransition(to sessionState) bool {
 // getStateName returns human-readable state name for debugging
 func getStateName(s sessionState) string {
 	names := map[sessionState]string{
-		stateSplash:   "SPLASH",
-		stateMain:     "MAIN",
-		stateSearch:   "SEARCH",
-		statePreview:  "PREVIEW",
-		stateConfirm:  "CONFIRM",
-		stateUpdating: "UPDATING",
-		stateSummary:  "SUMMARY",
+		stateSplash:           "SPLASH",
+		stateMain:             "MAIN",
+		stateSearch:           "SEARCH",
+		statePreview:          "PREVIEW",
+		stateConfirm:          "CONFIRM",
+		stateUpdating:         "UPDATING",
+		stateSummary:          "SUMMARY",
+		stateTranscript:       "TRANSCRIPT",
+		stateInstallConfirm:   "INSTALL_CONFIRM",
+		stateUninstallConfirm: "UNINSTALL_CONFIRM",
+		stateRollbackConfirm:  "ROLLBACK_CONFIRM",
+		stateHistory:          "HISTORY",
+		stateDrift:            "DRIFT",
 	}
 	if name, ok := names[s]; ok {
 		return name
@@ -43,10 +43,11 @@ func (m Model) ViewSummary() string {
 
 // SummaryStats holds statistics about the update session
 type SummaryStats struct {
-	Total     int
-	Successful int
-	Failed    int
-	Skipped   int
+	Total        int
+	Successful   int
+	Failed       int
+	Skipped      int
+	Aborted      int
 	UpdatedTools []core.ToolState
 	FailedTools  []core.ToolState
 }
@@ -71,6 +72,8 @@ func (m Model) calculateSummaryStats() SummaryStats {
 		case core.StatusFailed:
 			stats.Failed++
 			stats.FailedTools = append(stats.FailedTools, item)
+		case core.StatusAborted:
+			stats.Aborted++
 		default:
 			stats.Skipped++
 		}
@@ -108,6 +111,12 @@ func (m Model) renderStatisticsBox(stats SummaryStats) string {
 			Render(fmt.Sprintf("✘ Failed:        %d", stats.Failed)))
 	}
 
+	if stats.Aborted > 0 {
+		lines = append(lines, lipgloss.NewStyle().
+			Foreground(cYellow).
+			Render(fmt.Sprintf("⊘ Aborted:       %d", stats.Aborted)))
+	}
+
 	if stats.Skipped > 0 {
 		lines = append(lines, lipgloss.NewStyle().
 			Foreground(cGray).
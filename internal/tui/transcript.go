@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// liveLogLines is the height of the output tail in the updating modal
+const liveLogLines = 8
+
+// renderLiveLog shows the tail of the running updates' output, scrolled back
+// by logScroll. With several workers each line is prefixed with its tool.
+func (m Model) renderLiveLog(width int) string {
+	end := max(len(m.liveLog)-m.logScroll, 0)
+	start := max(end-liveLogLines, 0)
+
+	var rendered []string
+	for _, l := range m.liveLog[start:end] {
+		line := l.Line
+		if m.workers > 1 {
+			line.Text = "[" + m.items[l.Index].Tool.Binary + "] " + line.Text
+		}
+		rendered = append(rendered, renderOutputLine(line, width))
+	}
+	// Pad so the modal keeps its height while output starts
+	for len(rendered) < liveLogLines {
+		rendered = append(rendered, "")
+	}
+
+	if m.logScroll > 0 {
+		rendered = append(rendered, lipgloss.NewStyle().Foreground(cYellow).
+			Render(fmt.Sprintf("↑ %d lines back • [END] Follow output", m.logScroll)))
+	}
+	return strings.Join(rendered, "\n")
+}
+
+// renderOutputLine colors a line by stream and cuts it to width so it never wraps
+func renderOutputLine(line updater.OutputLine, width int) string {
+	text := truncateLine(line.Text, width)
+	switch line.Stream {
+	case updater.StreamCommand:
+		return lipgloss.NewStyle().Foreground(cPurple).Bold(true).Render(text)
+	case updater.StreamStderr:
+		return lipgloss.NewStyle().Foreground(cYellow).Render(text)
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#A8A8A8")).Render(text)
+}
+
+func truncateLine(s string, width int) string {
+	s = strings.ReplaceAll(s, "\t", "    ")
+	runes := []rune(s)
+	if width <= 1 || len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// ViewTranscript renders the full output of one tool from the last update session
+func (m Model) ViewTranscript() string {
+	index := m.sessionItems[m.transcriptPos]
+	item := m.items[index]
+
+	title := lipgloss.NewStyle().
+		Background(cPurple).
+		Foreground(cWhite).
+		Bold(true).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" UPDATE LOG: %s (%d/%d) ", item.Tool.Name, m.transcriptPos+1, len(m.sessionItems)))
+
+	var status string
+	switch item.Status {
+	case core.StatusFailed:
+		status = lipgloss.NewStyle().Foreground(cRed).Render("✘ " + truncateLine(item.Message, m.transcriptWidth()))
+	case core.StatusAborted:
+		status = lipgloss.NewStyle().Foreground(cYellow).Render("⊘ " + item.Message)
+	case core.StatusSkipped:
+		status = lipgloss.NewStyle().Foreground(cGray).Render("○ " + item.Message)
+	default:
+		status = lipgloss.NewStyle().Foreground(cGreen).Render("✓ " + item.Message)
+	}
+
+	lines := m.transcripts[index]
+	var body []string
+	if len(lines) == 0 {
+		body = append(body, lipgloss.NewStyle().Foreground(cGray).Render("(no output)"))
+	}
+	end := min(m.transcriptScroll+m.transcriptPageSize(), len(lines))
+	for _, line := range lines[min(m.transcriptScroll, end):end] {
+		body = append(body, renderOutputLine(line, m.transcriptWidth()))
+	}
+
+	position := ""
+	if len(lines) > m.transcriptPageSize() {
+		position = fmt.Sprintf(" • lines %d-%d of %d", m.transcriptScroll+1, end, len(lines))
+	}
+
+	help := lipgloss.NewStyle().
+		Foreground(cGray).
+		Render("[↑/↓/PGUP/PGDN] Scroll • [←/→] Previous/Next tool • [ESC] Back to summary" + position)
+
+	content := title + "\n\n" + status + "\n\n" + strings.Join(body, "\n") + "\n\n" + help
+	return appStyle.Render(content)
+}
+
+// transcriptPageSize is how many output lines fit under the transcript header and help
+func (m Model) transcriptPageSize() int {
+	return max(m.height-10, 5)
+}
+
+func (m Model) transcriptWidth() int {
+	return max(m.width-6, 40)
+}
+
+func (m Model) maxTranscriptScroll() int {
+	if len(m.sessionItems) == 0 {
+		return 0
+	}
+	return max(len(m.transcripts[m.sessionItems[m.transcriptPos]])-m.transcriptPageSize(), 0)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dpeluche/spark/internal/core"
+	"github.com/dpeluche/spark/internal/updater"
 )
 
 // ViewMain renders the main dashboard view
@@ -40,6 +41,12 @@ func (m Model) View() string {
 		return m.ViewPreview()
 	case stateConfirm:
 		return m.overlayModal(bg)
+	case stateInstallConfirm:
+		return m.composite(bg, m.renderInstallConfirmContent(), cGreen)
+	case stateUninstallConfirm:
+		return m.composite(bg, m.renderUninstallConfirmContent(), cRed)
+	case stateRollbackConfirm:
+		return m.composite(bg, m.renderRollbackConfirmContent(), cYellow)
 	case stateUpdating:
 		// Render actual overlay
 		modal := m.renderUpdatingModalContent()
@@ -48,6 +55,12 @@ func (m Model) View() string {
 		// Render summary overlay
 		modal := m.renderSummaryModalContent()
 		return m.composite(bg, modal, cPurple)
+	case stateTranscript:
+		return m.ViewTranscript()
+	case stateHistory:
+		return m.ViewHistory()
+	case stateDrift:
+		return m.ViewDrift()
 	default:
 		return bg
 	}
@@ -60,7 +73,7 @@ func (m Model) composite(background, foreground string, borderColor lipgloss.Col
 	// This ensures we overwrite ANY previous content on the screen (fixing ghost bars)
 	bg := lipgloss.Place(m.width, m.height, lipgloss.Top, lipgloss.Left, background)
 	bgLines := strings.Split(bg, "\n")
-	
+
 	// 2. Render Modal Centered within a Full-Width Strip
 	// We use a dark background for the strip to make the modal pop
 	modalStrip := lipgloss.NewStyle().
@@ -68,12 +81,14 @@ func (m Model) composite(background, foreground string, borderColor lipgloss.Col
 		Align(lipgloss.Center).
 		Background(lipgloss.Color("#1A1B26")). // Matches modal background
 		Render(foreground)
-	
+
 	modalLines := strings.Split(modalStrip, "\n")
 
 	// 3. Calculate positioning
 	startLine := (len(bgLines) - len(modalLines)) / 2
-	if startLine < 0 { startLine = 0 }
+	if startLine < 0 {
+		startLine = 0
+	}
 
 	// 4. Replace lines (Overlay Strip)
 	for i, line := range modalLines {
@@ -91,51 +106,135 @@ func (m Model) renderUpdatingModalContent() string {
 	title := lipgloss.NewStyle().
 		Foreground(cBlue).
 		Bold(true).
-		Render("⟳ SYSTEM UPDATE IN PROGRESS")
+		Render("⟳ SYSTEM " + strings.ToUpper(m.sessionAction.String()) + " IN PROGRESS")
 
 	content := m.renderProgressBar()
 
 	return lipgloss.JoinVertical(lipgloss.Center, title, "\n", content)
 }
 
+// renderInstallConfirmContent lists the planned installs and their commands
+func (m Model) renderInstallConfirmContent() string {
+	title := lipgloss.NewStyle().Foreground(cGreen).Bold(true).Render("⬇ INSTALL MISSING TOOLS")
+
+	var lines []string
+	for _, i := range m.plannedItems(updater.ActionInstall) {
+		t := m.items[i].Tool
+		lines = append(lines, fmt.Sprintf("• %s  %s", t.Name,
+			lipgloss.NewStyle().Foreground(cGray).Render(updater.PlannedCommand(updater.ActionInstall, t))))
+	}
+	for _, i := range m.manualItems(updater.ActionInstall) {
+		lines = append(lines, lipgloss.NewStyle().Foreground(cYellow).
+			Render(fmt.Sprintf("• %s  (manual install required, skipped)", m.items[i].Tool.Name)))
+	}
+
+	prompt := lipgloss.NewStyle().Foreground(cWhite).Render("Install these tools? (y/N)")
+	return lipgloss.JoinVertical(lipgloss.Left, title, "", strings.Join(lines, "\n"), "", prompt)
+}
+
+// renderUninstallConfirmContent lists the planned uninstalls with their
+// commands and what each may break
+func (m Model) renderUninstallConfirmContent() string {
+	title := lipgloss.NewStyle().Foreground(cRed).Bold(true).Render("✖ UNINSTALL TOOLS")
+	warn := lipgloss.NewStyle().Foreground(cYellow)
+
+	var lines []string
+	for _, i := range m.plannedItems(updater.ActionUninstall) {
+		t := m.items[i].Tool
+		lines = append(lines, fmt.Sprintf("• %s  %s", t.Name,
+			lipgloss.NewStyle().Foreground(cGray).Render(updater.PlannedCommand(updater.ActionUninstall, t))))
+		for _, w := range m.uninstallWarns[i] {
+			lines = append(lines, warn.Render("    ⚠ "+w))
+		}
+	}
+	for _, i := range m.manualItems(updater.ActionUninstall) {
+		lines = append(lines, warn.Render(fmt.Sprintf("• %s  (manual uninstall required, skipped)", m.items[i].Tool.Name)))
+	}
+
+	prompt := lipgloss.NewStyle().Foreground(cWhite).Render("Uninstall these tools? (y/N)")
+	if m.dependentsLeft > 0 {
+		prompt = lipgloss.NewStyle().Foreground(cGray).Render("Checking what depends on them...")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, title, "", strings.Join(lines, "\n"), "", prompt)
+}
+
+// renderRollbackConfirmContent lists the tools of the last session that go
+// back to their previous version, with the recorded commands
+func (m Model) renderRollbackConfirmContent() string {
+	title := lipgloss.NewStyle().Foreground(cYellow).Bold(true).Render("↺ ROLL BACK LAST UPDATE")
+
+	var lines []string
+	for _, i := range m.plannedItems(updater.ActionRollback) {
+		rb, _ := m.rollbacks.Get(m.items[i].Tool.Key)
+		lines = append(lines, fmt.Sprintf("• %s  %s → %s  %s", m.items[i].Tool.Name, m.items[i].LocalVersion, rb.Version,
+			lipgloss.NewStyle().Foreground(cGray).Render(rb.String())))
+	}
+
+	prompt := lipgloss.NewStyle().Foreground(cWhite).Render("Restore these versions? (y/N)")
+	return lipgloss.JoinVertical(lipgloss.Left, title, "", strings.Join(lines, "\n"), "", prompt)
+}
+
 // renderSummaryModalContent returns just the inner part of the summary modal
 func (m Model) renderSummaryModalContent() string {
 	successCount := 0
 	failCount := 0
+	abortedCount := 0
+	skippedCount := 0
 	var failureDetails []string
 
 	for _, item := range m.items {
-		if item.Status == core.StatusUpdated {
+		switch item.Status {
+		case core.StatusUpdated:
 			successCount++
-		} else if item.Status == core.StatusFailed {
+		case core.StatusFailed:
 			failCount++
 			failureDetails = append(failureDetails, fmt.Sprintf("• %s: %s", item.Tool.Name, item.Message))
+		case core.StatusAborted:
+			abortedCount++
+		case core.StatusSkipped:
+			skippedCount++
 		}
 	}
 
+	heading := strings.ToUpper(m.sessionAction.String()) + " COMPLETE"
+	if abortedCount > 0 || skippedCount > 0 {
+		heading = strings.ToUpper(m.sessionAction.String()) + " STOPPED"
+	}
 	title := lipgloss.NewStyle().
 		Background(cPurple).
 		Foreground(cWhite).
 		Bold(true).
 		Padding(0, 2). // Symmetrical padding
-		Render("UPDATE COMPLETE")
+		Render(heading)
 
+	statsLine := fmt.Sprintf("Successful: %d  |  Failed: %d", successCount, failCount)
+	if abortedCount > 0 {
+		statsLine += fmt.Sprintf("  |  Aborted: %d", abortedCount)
+	}
+	if skippedCount > 0 {
+		statsLine += fmt.Sprintf("  |  Skipped: %d", skippedCount)
+	}
 	stats := lipgloss.NewStyle().
 		MarginTop(1).
-		Render(fmt.Sprintf("Successful: %d  |  Failed: %d", successCount, failCount))
-	
+		Render(statsLine)
+
 	errors := ""
 	if len(failureDetails) > 0 {
 		errors = "\n" + lipgloss.NewStyle().Foreground(cRed).Render(strings.Join(failureDetails, "\n")) + "\n"
 	}
-	
+
+	hintText := "[L] View update logs • [ENTER] Close"
+	if m.canRollBack() {
+		hintText = "[L] View update logs • [R] Roll back • [ENTER] Close"
+	}
 	hint := lipgloss.NewStyle().
 		Foreground(cGray).
 		MarginTop(1).
-		Render("[Press ENTER to close]")
+		Render(hintText)
 
 	return lipgloss.JoinVertical(lipgloss.Center, title, stats, errors, hint)
 }
+
 // overlayUpdatingModal is now deprecated by composite system, but kept for signature if needed
 func (m Model) overlayUpdatingModal(background string) string {
 	return m.View() // Recursive but switch handles it
@@ -165,12 +264,12 @@ func (m Model) overlaySummaryModal(background string) string {
 		Render(" UPDATE COMPLETE ")
 
 	stats := fmt.Sprintf("\n✔ Successful: %d\n✘ Failed:     %d\n", successCount, failCount)
-	
+
 	content := stats
 	if len(failureDetails) > 0 {
 		content += "\nErrors:\n" + lipgloss.NewStyle().Foreground(cRed).Render(strings.Join(failureDetails, "\n"))
 	}
-	
+
 	content += "\n\n" + lipgloss.NewStyle().Foreground(cGray).Render("[Press ENTER to close]")
 
 	box := lipgloss.NewStyle().
@@ -189,12 +288,12 @@ func (m Model) overlaySummaryModal(background string) string {
 func (m Model) ViewSplash() string {
 	// Animate logo color based on frame (cycles through colors)
 	colors := []lipgloss.Color{
-		cBlue,   // Frame 0-2
+		cBlue,                     // Frame 0-2
 		lipgloss.Color("#4EA7FF"), // Lighter blue
-		cPurple, // Purple
+		cPurple,                   // Purple
 		lipgloss.Color("#00D9FF"), // Cyan
-		cGreen,  // Green
-		cBlue,   // Back to blue
+		cGreen,                    // Green
+		cBlue,                     // Back to blue
 	}
 
 	frameIndex := m.splashFrame % len(colors)
@@ -272,14 +371,14 @@ func (m Model) renderIndeterminateBar(width int) string {
 	// Effective width for the moving part
 	trackWidth := width - 2 // Minus brackets
 	blockWidth := 10
-	
+
 	// Calculate position based on splashFrame
 	pos := m.splashFrame % (trackWidth + blockWidth)
-	
+
 	// Build the track
 	var sb strings.Builder
 	sb.WriteString("[")
-	
+
 	for i := 0; i < trackWidth; i++ {
 		effectivePos := pos - blockWidth
 		if i >= effectivePos && i < pos {
@@ -288,7 +387,7 @@ func (m Model) renderIndeterminateBar(width int) string {
 			sb.WriteString("░") // Empty track character
 		}
 	}
-	
+
 	sb.WriteString("]")
 	return lipgloss.NewStyle().Foreground(cBlue).Render(sb.String())
 }
@@ -299,26 +398,30 @@ func (m Model) renderProgressBar() string {
 	percent := float64(completed) / float64(m.totalUpdate)
 
 	// If we are working on something, we want movement.
-	// Standard progress bar is great for > 0%, but for 0% or between steps, 
+	// Standard progress bar is great for > 0%, but for 0% or between steps,
 	// we want to show activity on the current step.
 	var barView string
-	
+
 	// Use indeterminate animation if we are active but bar looks static (start or 1 item)
 	if m.totalUpdate <= 1 || percent == 0 {
-		barView = m.renderIndeterminateBar(50) 
+		barView = m.renderIndeterminateBar(50)
 	} else {
 		barView = m.progress.ViewAs(percent)
 	}
 
 	// Format: "Progress: 0/1 completed"
 	label := fmt.Sprintf("Progress: %d/%d completed", completed, m.totalUpdate)
-	
+
 	currentTool := ""
-	if m.currentUpdate >= 0 && m.currentUpdate < len(m.items) {
-		currentTool = fmt.Sprintf("• Processing: %s", m.items[m.currentUpdate].Tool.Name)
+	if running := m.runningItems(); len(running) > 0 {
+		names := make([]string, len(running))
+		for i, idx := range running {
+			names[i] = m.items[idx].Tool.Name
+		}
+		currentTool = fmt.Sprintf("• Processing: %s", strings.Join(names, ", "))
 	}
 
-	// Fake Terminal Output
+	// Live terminal output: planned command, then the tail of the real output
 	termContent := ""
 	if m.currentLog != "" {
 		termContent = lipgloss.NewStyle().
@@ -326,13 +429,13 @@ func (m Model) renderProgressBar() string {
 			Background(cDark).
 			Padding(0, 1).
 			Width(60).
-			Render(m.currentLog)
+			Render(m.currentLog) + "\n" + m.renderLiveLog(58)
 	} else {
 		termContent = lipgloss.NewStyle().
 			Foreground(cGray).
 			Render("Waiting for jobs...")
 	}
-	
+
 	termBox := lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder(), false, false, false, true). // Left border only
 		BorderForeground(cPurple).
@@ -340,8 +443,8 @@ func (m Model) renderProgressBar() string {
 		PaddingLeft(1).
 		Render(termContent)
 
-	return lipgloss.NewStyle().Foreground(cBlue).Render(label) + 
-		lipgloss.NewStyle().Foreground(cYellow).Bold(true).Render(" "+currentTool) + 
+	return lipgloss.NewStyle().Foreground(cBlue).Render(label) +
+		lipgloss.NewStyle().Foreground(cYellow).Bold(true).Render(" "+currentTool) +
 		"\n" + barView + "\n" + termBox
 }
 
@@ -350,13 +453,16 @@ func (m Model) renderProgressBar() string {
 func (m Model) getHeaderText() string {
 	switch m.state {
 	case stateUpdating:
-		return fmt.Sprintf(" UPDATING (%d remaining)... ", m.updating)
+		return fmt.Sprintf(" %s (%d remaining)... ", strings.ToUpper(m.sessionAction.Progressive()), m.updating)
 	case stateSummary:
 		return " UPDATE SUMMARY "
 	default:
 		if m.loading > 0 {
 			return fmt.Sprintf(" SPARK DASHBOARD (Scanning %d...)", m.loading)
 		}
+		if m.detector.IsStale() {
+			return " SPARK DASHBOARD (* cached, refreshing...) "
+		}
 		return " SPARK DASHBOARD "
 	}
 }
@@ -440,6 +546,15 @@ func (m Model) renderToolLine(index int, item core.ToolState) string {
 	checked := m.getCheckedIndicator(index)
 	status := m.renderItemStatus(index, item)
 	name := m.formatToolName(item.Tool.Name)
+	if item.Pin != "" {
+		status += lipgloss.NewStyle().Foreground(cYellow).Render(" 🔒")
+	}
+	if !updater.OwnerMatches(item.Tool, updater.Owner(item.Owner)) {
+		status += lipgloss.NewStyle().Foreground(cYellow).Render(" ⚠ " + item.Owner) // Upgrades are redirected or refused
+	}
+	if item.Shadowed != "" {
+		status += lipgloss.NewStyle().Foreground(cYellow).Render(" ⇅") // The managed copy is not the one on PATH
+	}
 
 	lineStr := fmt.Sprintf("%s %s %-18s %s", cursor, checked, name, status)
 
@@ -477,14 +592,21 @@ func (m Model) renderItemStatus(index int, item core.ToolState) string {
 	if m.state == stateUpdating || m.state == stateSummary {
 		switch item.Status {
 		case core.StatusUpdating:
+			if _, ok := m.running[index]; !ok {
+				break // Queued, waiting for a worker or a package manager lock
+			}
 			// Animated spinner: ⠋ ⠙ ⠹ ⠸ ⠼ ⠴ ⠦ ⠧ ⠇ ⠏
 			frames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 			frame := frames[m.splashFrame%len(frames)]
-			return lipgloss.NewStyle().Foreground(cBlue).Render(frame + " Updating...")
+			return lipgloss.NewStyle().Foreground(cBlue).Render(frame + " " + m.sessionAction.Progressive() + "...")
 		case core.StatusUpdated:
 			return lipgloss.NewStyle().Foreground(cGreen).Render("✔ " + item.LocalVersion)
 		case core.StatusFailed:
 			return statusFailed
+		case core.StatusAborted:
+			return lipgloss.NewStyle().Foreground(cYellow).Render("⊘ Aborted")
+		case core.StatusSkipped:
+			return lipgloss.NewStyle().Foreground(cGray).Render("○ Skipped")
 		}
 
 		// Not yet updated but selected
@@ -501,33 +623,49 @@ func (m Model) renderItemStatus(index int, item core.ToolState) string {
 		return statusChecking
 	}
 
-	// Format version display
-	versionStr := item.LocalVersion
-	if item.RemoteVersion != "..." && item.RemoteVersion != "Checking..." && item.RemoteVersion != "Unknown" {
-		if item.RemoteVersion != item.LocalVersion && item.LocalVersion != "MISSING" {
-			// Show update path: 1.0.0 -> 1.1.0
-			versionStr = fmt.Sprintf("%s %s %s",
-				lipgloss.NewStyle().Foreground(cGray).Render(item.LocalVersion),
-				lipgloss.NewStyle().Foreground(cYellow).Render("→"),
-				lipgloss.NewStyle().Foreground(cGreen).Bold(true).Render(item.RemoteVersion))
-		} else if item.RemoteVersion == item.LocalVersion {
-			versionStr = lipgloss.NewStyle().Foreground(cGray).Render(item.LocalVersion)
-		}
+	// Cached remote data older than the TTL is shown with a marker until it refreshes
+	if item.RemoteStale {
+		return renderVersionStatus(item) + lipgloss.NewStyle().Foreground(cDark).Render(" *")
 	}
+	return renderVersionStatus(item)
+}
+
+// renderVersionStatus formats the version column based on how local relates to remote
+func renderVersionStatus(item core.ToolState) string {
+	versionStr := item.LocalVersion
 
 	switch item.Status {
 	case core.StatusMissing:
+		// Registries know the latest release even before the tool is installed
+		if updater.IsKnownVersion(item.RemoteVersion) {
+			return statusMissing + lipgloss.NewStyle().Foreground(cGray).Render(" ("+item.RemoteVersion+")")
+		}
 		return statusMissing
 	case core.StatusOutdated:
-		return versionStr
+		// Show update path: 1.0.0 -> 1.1.0
+		return fmt.Sprintf("%s %s %s",
+			lipgloss.NewStyle().Foreground(cGray).Render(item.LocalVersion),
+			lipgloss.NewStyle().Foreground(cYellow).Render("→"),
+			lipgloss.NewStyle().Foreground(cGreen).Bold(true).Render(item.RemoteVersion))
+	case core.StatusAhead:
+		// Local build is newer than anything released: 1.2.0 ▲ 1.1.0
+		return fmt.Sprintf("%s %s %s",
+			lipgloss.NewStyle().Foreground(cWhite).Render(item.LocalVersion),
+			lipgloss.NewStyle().Foreground(cPurple).Render("▲"),
+			lipgloss.NewStyle().Foreground(cGray).Render(item.RemoteVersion))
 	case core.StatusInstalled:
 		if item.LocalVersion == "MISSING" {
 			return lipgloss.NewStyle().Foreground(cYellow).Render("MISSING")
 		}
 		// If we have remote info and they match, it's truly up to date
-		if item.RemoteVersion == item.LocalVersion && item.LocalVersion != "..." {
+		if updater.CompareVersions(item.LocalVersion, item.RemoteVersion) == updater.RelationUpToDate ||
+			(item.RemoteVersion == item.LocalVersion && item.LocalVersion != "...") {
 			return lipgloss.NewStyle().Foreground(cGray).Render(item.LocalVersion + " " + "✓")
 		}
+		// Remote known but in a different format (e.g. hash vs semver)
+		if updater.IsKnownVersion(item.RemoteVersion) {
+			return versionStr + lipgloss.NewStyle().Foreground(cGray).Render(" ?")
+		}
 		return versionStr
 	default:
 		if item.LocalVersion == "MISSING" {
@@ -544,11 +682,19 @@ func (m Model) getHelpText() string {
 	case stateSearch:
 		return "[Type to search] • [ESC] Cancel • [ENTER] Confirm"
 	case stateUpdating:
-		return "[UPDATING IN PROGRESS... PLEASE WAIT]"
+		return "[UPDATING IN PROGRESS] [↑/↓] Scroll output • [X] Abort current • [SHIFT+X] Abort all"
 	case stateSummary:
-		return "[UPDATE COMPLETE] Press any key to return to dashboard"
+		if m.canRollBack() {
+			return "[UPDATE COMPLETE] [L] View update logs • [R] Roll back • Any other key returns to dashboard"
+		}
+		return "[UPDATE COMPLETE] [L] View update logs • Any other key returns to dashboard"
+	case stateRollbackConfirm:
+		return "[Y] Roll back • [N/ESC] Back to summary"
 	default:
-		help := "[SPACE] Select • [G/A] Group • [/] Search • [D] Dry-Run • [ENTER] Update • [Q] Quit"
+		help := "[SPACE] Select • [G/A] Group • [/] Search • [D] Dry-Run • [E] Export • [H] History • [ENTER] Update • [+] Install • [-] Uninstall • [Q] Quit"
+		if m.manifest != nil {
+			help = strings.Replace(help, "[H] History", "[H] History • [M] Manifest drift", 1)
+		}
 		if m.searchQuery != "" {
 			help = "[Filter active] " + help + " • [ESC] Clear filter"
 		}
@@ -558,7 +704,11 @@ func (m Model) getHelpText() string {
 
 func (m Model) renderHelpBar() string {
 	help := m.getHelpText()
-	return lipgloss.NewStyle().Foreground(cGray).Render("\n\n" + help)
+	bar := lipgloss.NewStyle().Foreground(cGray).Render("\n\n" + help)
+	if m.notice != "" {
+		bar += "\n" + lipgloss.NewStyle().Foreground(cYellow).Render(m.notice)
+	}
+	return bar
 }
 
 // --- Utility Functions ---
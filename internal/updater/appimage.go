@@ -0,0 +1,100 @@
+package updater
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// appImageStrategy handles AppImages, Package being the file or a name
+// prefix (see locateAppImage). Upgrades go through appimageupdatetool, which
+// follows the update information embedded in the file.
+type appImageStrategy struct{}
+
+func init() {
+	Register(&appImageStrategy{}, core.MethodAppImage)
+}
+
+func (s *appImageStrategy) Name() string { return "appimage" }
+
+func (s *appImageStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	path, ok := locateAppImage(d.home, packageName(t))
+	if !ok {
+		return "MISSING", SourceNone
+	}
+	return appImageVersion(path), SourceAppImage
+}
+
+// WarmUp is a no-op: the latest release comes from the GitHub repository
+// named in each file's update information, see Registry
+func (s *appImageStrategy) WarmUp(d *Detector) {}
+
+// Resolve replaces Package with the AppImage file it names
+func (s *appImageStrategy) Resolve(home string, t core.Tool) (core.Tool, bool) {
+	path, ok := locateAppImage(home, packageName(t))
+	if !ok {
+		return t, false
+	}
+	t.Package = path
+	return t, true
+}
+
+// Plan needs Package resolved to the file, see Resolve; anything else is
+// left to the user rather than guessed at
+func (s *appImageStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	path := packageName(t)
+	if a == ActionInstall || !filepath.IsAbs(path) {
+		return nil // Installs are downloaded from the vendor's site
+	}
+	if a == ActionUninstall {
+		return []PlanStep{step("rm", "-f", path)}
+	}
+	// The new version usually has its own file name, found again by prefix
+	return []PlanStep{step("appimageupdatetool", "--remove-old", path)}
+}
+
+// LockKey is empty: every AppImage is a file of its own
+func (s *appImageStrategy) LockKey(t core.Tool) string { return "" }
+
+// Owns claims binaries that resolve to an .AppImage file
+func (s *appImageStrategy) Owns(o Owner) bool { return o == OwnerAppImage }
+
+// Registry names the GitHub releases the file's update information points
+// at, "" for other update channels. Each file is read once per run.
+func (s *appImageStrategy) Registry(d *Detector, t core.Tool) string {
+	path, ok := locateAppImage(d.home, packageName(t))
+	if !ok {
+		return ""
+	}
+	d.latestMutex.Lock()
+	defer d.latestMutex.Unlock()
+	ref, ok := d.fileRegistry[path]
+	if !ok {
+		if repo, found := appImageGitHubRepo(appImageUpdateInfo(path)); found {
+			ref = core.RegistryGitHub + ":" + repo
+		}
+		d.fileRegistry[path] = ref
+	}
+	return ref
+}
+
+func (s *appImageStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	plan := s.Plan(ActionUpgrade, t)
+	if len(plan) == 0 {
+		return errManual("update")
+	}
+	return e.runSteps(ctx, "appimageupdatetool", plan)
+}
+
+func (s *appImageStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return errManual("install")
+}
+
+func (s *appImageStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	plan := s.Plan(ActionUninstall, t)
+	if len(plan) == 0 {
+		return errManual("uninstall")
+	}
+	return e.runSteps(ctx, "appimage removal", plan)
+}
@@ -0,0 +1,103 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// aptStrategy handles Debian/Ubuntu packages (dpkg database, apt-get upgrades)
+type aptStrategy struct{}
+
+func init() {
+	Register(&aptStrategy{}, core.MethodApt)
+}
+
+func (s *aptStrategy) Name() string { return "apt" }
+
+func (s *aptStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	out := d.runOutput("dpkg-query", "-W", "-f=${Status}|${Version}", t.Package)
+	return d.detectDistroPackage(t, parseDpkgQuery(out), SourceDpkg)
+}
+
+func (s *aptStrategy) WarmUp(d *Detector) {
+	// apt list --upgradable (runOutput returns nothing when apt is absent)
+	out := d.runOutput("apt", "list", "--upgradable")
+	for pkg, latest := range parseAptUpgradable(out) {
+		d.setRemote(s.Name(), pkg, remoteInfo{latest, SourceAptUpgradable})
+	}
+}
+
+func (s *aptStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	switch a {
+	case ActionInstall:
+		return []PlanStep{privilegedStep("apt-get", "install", "-y", "-q", t.Package)}
+	case ActionUninstall:
+		return []PlanStep{privilegedStep("apt-get", "remove", "-y", "-q", t.Package)}
+	}
+	return []PlanStep{privilegedStep("apt-get", "install", "--only-upgrade", "-y", "-q", t.Package)}
+}
+
+// LockKey is per manager: dpkg allows a single writer
+func (s *aptStrategy) LockKey(t core.Tool) string { return "apt" }
+
+// Owns claims the system directories the package database installs into
+func (s *aptStrategy) Owns(o Owner) bool { return o == OwnerSystem }
+
+func (s *aptStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "apt upgrade", s.Plan(ActionUpgrade, t))
+}
+
+func (s *aptStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "apt install", s.Plan(ActionInstall, t))
+}
+
+func (s *aptStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "apt remove", s.Plan(ActionUninstall, t))
+}
+
+// parseDpkgQuery handles `dpkg-query -W -f='${Status}|${Version}'`.
+// Packages that were removed but not purged still have a record, so the
+// status must say "installed".
+func parseDpkgQuery(out string) string {
+	status, version, ok := strings.Cut(strings.TrimSpace(out), "|")
+	if !ok || !strings.HasSuffix(status, " installed") || version == "" {
+		return "MISSING"
+	}
+	return normalizeDistroVersion(version)
+}
+
+// parseAptUpgradable handles lines like:
+// jq/jammy-updates 1.6-2.1ubuntu3.1 amd64 [upgradable from: 1.6-2.1ubuntu3]
+func parseAptUpgradable(out string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.Contains(line, "[upgradable from:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name, _, _ := strings.Cut(fields[0], "/")
+		result[name] = normalizeDistroVersion(fields[1])
+	}
+	return result
+}
+
+// RollbackSteps reinstalls the exact installed version; apt-get keeps it
+// available as long as the archive or the local cache still has the .deb
+func (s *aptStrategy) RollbackSteps(ctx context.Context, e *Executor, t core.Tool) (string, []RollbackStep, error) {
+	// The raw version, epoch included: it is how apt-get names it
+	out, err := e.combined(ctx, "dpkg-query", "-W", "-f=${Version}", t.Package)
+	version := strings.TrimSpace(out)
+	if err != nil || version == "" {
+		return "", nil, fmt.Errorf("dpkg-query found no installed %s", t.Package)
+	}
+	return version, []RollbackStep{{
+		Args:       []string{"apt-get", "install", "-y", "-q", "--allow-downgrades", t.Package + "=" + version},
+		Privileged: true,
+	}}, nil
+}
@@ -0,0 +1,131 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// brewStrategy handles Homebrew formulae (brew, brew_pkg)
+type brewStrategy struct{}
+
+func init() {
+	Register(&brewStrategy{}, core.MethodBrew, core.MethodBrewPkg)
+}
+
+func (s *brewStrategy) Name() string { return "brew" }
+
+func (s *brewStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	if version, source := d.getCliToolVersion(t); version != "MISSING" {
+		return version, source
+	}
+
+	// Fallback: Check Homebrew explicitly
+	// brew list --versions <package>
+	// Output: "kubernetes-cli 1.28.2"
+	out, err := d.output("brew", "list", "--versions", t.Package)
+	if err == nil && len(out) > 0 {
+		fields := strings.Fields(out)
+		if len(fields) >= 2 {
+			// The version is usually the second field
+			return CleanVersionString(fields[len(fields)-1]), SourceBrewList
+		}
+	}
+	return "MISSING", SourceNone
+}
+
+type brewOutdatedItem struct {
+	Name           string `json:"name"`
+	CurrentVersion string `json:"current_version"` // This is actually the "latest" available in brew formulae usually?
+	// Brew JSON output for outdated:
+	// [{"name":"fzf","installed_versions":["0.45.0"],"current_version":"0.46.0",...}]
+}
+
+// WarmUp fills both formulae and casks, so the mac_app strategy needs no warm-up of its own
+func (s *brewStrategy) WarmUp(d *Detector) {
+	// brew outdated --json=v2
+	// Ignore errors, brew outdated returns non-zero if outdated items exist
+	out, _ := d.output("brew", "outdated", "--json=v2")
+
+	var data struct {
+		Formulae []brewOutdatedItem `json:"formulae"`
+		Casks    []brewOutdatedItem `json:"casks"`
+	}
+
+	if err := json.Unmarshal([]byte(out), &data); err == nil {
+		for _, item := range data.Formulae {
+			d.setRemote(s.Name(), item.Name, remoteInfo{item.CurrentVersion, SourceBrewOutdated})
+		}
+		for _, item := range data.Casks {
+			d.setRemote(caskCacheName, item.Name, remoteInfo{item.CurrentVersion, SourceBrewOutdated})
+		}
+	}
+}
+
+func (s *brewStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	switch a {
+	case ActionInstall:
+		return []PlanStep{step("brew", "install", t.Package)}
+	case ActionUninstall:
+		return []PlanStep{step("brew", "uninstall", t.Package)}
+	}
+	return []PlanStep{step("brew", "upgrade", t.Package)}
+}
+
+func (s *brewStrategy) LockKey(t core.Tool) string { return "brew" }
+
+// Owns claims binaries linked from the Cellar
+func (s *brewStrategy) Owns(o Owner) bool { return o == OwnerBrew }
+
+func (s *brewStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	// brew upgrade <package>
+	return e.runSteps(ctx, "brew upgrade", s.Plan(ActionUpgrade, t))
+}
+
+func (s *brewStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "brew install", s.Plan(ActionInstall, t))
+}
+
+func (s *brewStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "brew uninstall", s.Plan(ActionUninstall, t))
+}
+
+// Dependents lists the installed formulae that need t (brew refuses to uninstall it)
+func (s *brewStrategy) Dependents(ctx context.Context, e *Executor, t core.Tool) ([]string, error) {
+	// brew uses --installed <package>
+	out, err := e.combined(ctx, "brew", "uses", "--installed", t.Package)
+	if err != nil {
+		return nil, fmt.Errorf("brew uses failed: %s: %v", out, err)
+	}
+	return strings.Fields(out), nil
+}
+
+// rollbackTap is the local tap holding formulae extracted at older versions
+const rollbackTap = "spark/rollback"
+
+// RollbackSteps extracts the installed formula version into a local tap and
+// installs it from there: Homebrew itself only ever installs the latest one
+func (s *brewStrategy) RollbackSteps(ctx context.Context, e *Executor, t core.Tool) (string, []RollbackStep, error) {
+	// brew list --versions <package>
+	// Output: "jq 1.7.1_1"
+	out, err := e.combined(ctx, "brew", "list", "--versions", t.Package)
+	fields := strings.Fields(out)
+	if err != nil || len(fields) < 2 {
+		return "", nil, fmt.Errorf("brew list found no installed %s", t.Package)
+	}
+	version := fields[len(fields)-1]
+	formula := path.Base(t.Package) // Tap formulae are named "owner/tap/name"
+
+	// Revisions ("_1") are bottle rebuilds, brew extract wants the formula version
+	release, _, _ := strings.Cut(version, "_")
+	return version, []RollbackStep{
+		{Args: []string{"sh", "-c", "brew tap | grep -qx " + rollbackTap + " || brew tap-new --no-git " + rollbackTap}},
+		{Args: []string{"brew", "extract", "--force", "--version=" + release, t.Package, rollbackTap}},
+		{Args: []string{"brew", "unlink", t.Package}},
+		{Args: []string{"brew", "install", rollbackTap + "/" + formula + "@" + release}},
+	}, nil
+}
@@ -0,0 +1,191 @@
+package updater
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cacheSchemaVersion is bumped when the file layout changes; older files are discarded
+const cacheSchemaVersion = 1
+
+// Cache keeps detection results on disk between runs so the dashboard can
+// render immediately. Remote data (the outdated lists fetched by WarmUp)
+// expires after a TTL; local versions are keyed by binary path and stay
+// valid until the binary's mtime changes.
+type Cache struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu   sync.Mutex
+	data cacheData
+}
+
+type cacheData struct {
+	SchemaVersion   int                      `json:"schema_version"`
+	RemoteFetchedAt time.Time                `json:"remote_fetched_at"`
+	Remote          map[string]remoteInfo    `json:"remote"`
+	Local           map[string]localEntry    `json:"local"`    // Binary path -> version
+	Registry        map[string]registryEntry `json:"registry"` // "kind:name" -> latest release
+}
+
+// registryEntry is a registry lookup; each expires on its own after the TTL
+type registryEntry struct {
+	Version   string    `json:"version"`
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// localEntry is a local version detected from the binary at a path
+type localEntry struct {
+	Key     string    `json:"key"` // Tool key, binaries can be shared between tools
+	ModTime time.Time `json:"mtime"`
+	Version string    `json:"version"`
+	Source  string    `json:"source"`
+}
+
+// NewCache returns an empty cache that will be saved to path
+func NewCache(path string, ttl time.Duration) *Cache {
+	return &Cache{
+		path: path,
+		ttl:  ttl,
+		now:  time.Now,
+		data: cacheData{
+			SchemaVersion: cacheSchemaVersion,
+			Remote:        make(map[string]remoteInfo),
+			Local:         make(map[string]localEntry),
+			Registry:      make(map[string]registryEntry),
+		},
+	}
+}
+
+// OpenCache loads the cache at path. A missing, unreadable or outdated file
+// gives an empty cache; it is only a speed-up, so errors are not fatal.
+func OpenCache(path string, ttl time.Duration) *Cache {
+	c := NewCache(path, ttl)
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	var data cacheData
+	if err := json.Unmarshal(raw, &data); err != nil || data.SchemaVersion != cacheSchemaVersion {
+		return c
+	}
+	if data.Remote != nil {
+		c.data.Remote = data.Remote
+		c.data.RemoteFetchedAt = data.RemoteFetchedAt
+	}
+	if data.Local != nil {
+		c.data.Local = data.Local
+	}
+	if data.Registry != nil {
+		c.data.Registry = data.Registry
+	}
+	return c
+}
+
+// Clear drops everything loaded from disk; new results are still saved (--refresh)
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data.Remote = make(map[string]remoteInfo)
+	c.data.RemoteFetchedAt = time.Time{}
+	c.data.Local = make(map[string]localEntry)
+	c.data.Registry = make(map[string]registryEntry)
+}
+
+// remote returns a copy of the cached outdated lists and whether they are
+// younger than the TTL. ok is false when nothing was ever fetched.
+func (c *Cache) remote() (entries map[string]remoteInfo, fresh, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.data.RemoteFetchedAt.IsZero() {
+		return nil, false, false
+	}
+	entries = make(map[string]remoteInfo, len(c.data.Remote))
+	for k, v := range c.data.Remote {
+		entries[k] = v
+	}
+	fresh = c.now().Sub(c.data.RemoteFetchedAt) < c.ttl
+	return entries, fresh, true
+}
+
+// setRemote replaces the cached outdated lists with a fresh fetch
+func (c *Cache) setRemote(entries map[string]remoteInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data.Remote = make(map[string]remoteInfo, len(entries))
+	for k, v := range entries {
+		c.data.Remote[k] = v
+	}
+	c.data.RemoteFetchedAt = c.now()
+}
+
+// local returns the version cached for key's binary at path if the binary is unchanged
+func (c *Cache) local(key, path string, modTime time.Time) (version, source string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, found := c.data.Local[path]
+	if !found || e.Key != key || !e.ModTime.Equal(modTime) {
+		return "", "", false
+	}
+	return e.Version, e.Source, true
+}
+
+func (c *Cache) setLocal(key, path string, modTime time.Time, version, source string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data.Local[path] = localEntry{Key: key, ModTime: modTime, Version: version, Source: source}
+}
+
+// registry returns the latest version cached for ref if it is younger than the TTL
+func (c *Cache) registry(ref string) (version, source string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, found := c.data.Registry[ref]
+	if !found || c.now().Sub(e.FetchedAt) >= c.ttl {
+		return "", "", false
+	}
+	return e.Version, e.Source, true
+}
+
+func (c *Cache) setRegistry(ref, version, source string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data.Registry[ref] = registryEntry{Version: version, Source: source, FetchedAt: c.now()}
+}
+
+// Save writes the cache atomically, creating its directory if needed
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	raw, err := json.MarshalIndent(c.data, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".versions-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
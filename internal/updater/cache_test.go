@@ -0,0 +1,148 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func newTestCache(t *testing.T, ttl time.Duration) *Cache {
+	t.Helper()
+	return NewCache(filepath.Join(t.TempDir(), "spark", "versions.json"), ttl)
+}
+
+func TestCacheSaveAndOpen(t *testing.T) {
+	c := newTestCache(t, time.Hour)
+	mtime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	c.setRemote(map[string]remoteInfo{"brew:fzf": {Version: "0.46.0", Source: SourceBrewOutdated}})
+	c.setLocal("fzf", "/opt/homebrew/bin/fzf", mtime, "0.45.0", SourcePath)
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := OpenCache(c.path, time.Hour)
+	entries, fresh, ok := loaded.remote()
+	if !ok || !fresh {
+		t.Fatalf("remote() fresh=%v ok=%v, want both true", fresh, ok)
+	}
+	if want := (remoteInfo{Version: "0.46.0", Source: SourceBrewOutdated}); entries["brew:fzf"] != want {
+		t.Errorf("remote entry = %+v, want %+v", entries["brew:fzf"], want)
+	}
+	if ver, src, ok := loaded.local("fzf", "/opt/homebrew/bin/fzf", mtime); !ok || ver != "0.45.0" || src != SourcePath {
+		t.Errorf("local() = (%q, %q, %v), want (0.45.0, %q, true)", ver, src, ok, SourcePath)
+	}
+	if _, _, ok := loaded.local("fzf", "/opt/homebrew/bin/fzf", mtime.Add(time.Second)); ok {
+		t.Error("local() hit after the binary changed")
+	}
+	if _, _, ok := loaded.local("other", "/opt/homebrew/bin/fzf", mtime); ok {
+		t.Error("local() hit for another tool key")
+	}
+}
+
+func TestOpenCacheIgnoresBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"garbage.json":    "{not json",
+		"old-schema.json": `{"schema_version": 0, "remote_fetched_at": "2026-01-01T00:00:00Z"}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, ok := OpenCache(path, time.Hour).remote(); ok {
+			t.Errorf("%s: remote data loaded from an unusable file", name)
+		}
+	}
+	if _, _, ok := OpenCache(filepath.Join(dir, "missing.json"), time.Hour).remote(); ok {
+		t.Error("remote data loaded from a missing file")
+	}
+}
+
+func TestWarmUpCacheUsesFreshDiskCache(t *testing.T) {
+	c := newTestCache(t, time.Hour)
+	c.setRemote(map[string]remoteInfo{"brew:fzf": {Version: "0.46.0", Source: SourceBrewOutdated}})
+
+	r := NewFakeRunner()
+	d := newTestDetector(t, r)
+	d.UseCache(c)
+	if d.LoadStaleCache() {
+		t.Error("LoadStaleCache() loaded a fresh cache")
+	}
+	d.WarmUpCache()
+
+	if calls := r.Calls(); len(calls) != 0 {
+		t.Errorf("WarmUpCache ran %v with a fresh cache", calls)
+	}
+	ver, _, stale := d.DetectRemoteCached(core.Tool{Package: "fzf", Method: core.MethodBrew}, "0.45.0")
+	if ver != "0.46.0" || stale {
+		t.Errorf("DetectRemoteCached() = (%q, stale=%v), want (0.46.0, false)", ver, stale)
+	}
+}
+
+func TestStaleDiskCacheIsShownThenRefreshed(t *testing.T) {
+	c := newTestCache(t, time.Hour)
+	c.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+	c.setRemote(map[string]remoteInfo{"brew:fzf": {Version: "0.44.0", Source: SourceBrewOutdated}})
+	c.now = time.Now
+
+	r := NewFakeRunner().
+		On("brew outdated --json=v2", exitWithStdout(1, readFixture(t, "brew_outdated.json"))).
+		On("npm outdated -g --json", Stdout("{}"))
+	d := newTestDetector(t, r)
+	d.UseCache(c)
+	fzf := core.Tool{Package: "fzf", Method: core.MethodBrew}
+
+	if !d.LoadStaleCache() {
+		t.Fatal("LoadStaleCache() = false, want true")
+	}
+	if ver, _, stale := d.DetectRemoteCached(fzf, "0.43.0"); ver != "0.44.0" || !stale {
+		t.Errorf("before refresh: DetectRemoteCached() = (%q, stale=%v), want (0.44.0, true)", ver, stale)
+	}
+
+	d.WarmUpCache()
+	if ver, _, stale := d.DetectRemoteCached(fzf, "0.43.0"); ver != "0.46.0" || stale {
+		t.Errorf("after refresh: DetectRemoteCached() = (%q, stale=%v), want (0.46.0, false)", ver, stale)
+	}
+	if d.IsStale() {
+		t.Error("IsStale() = true after the refresh")
+	}
+
+	// The refresh is written back to disk
+	if entries, fresh, _ := OpenCache(c.path, time.Hour).remote(); !fresh || entries["brew:fzf"].Version != "0.46.0" {
+		t.Errorf("saved cache fresh=%v fzf=%+v, want fresh 0.46.0", fresh, entries["brew:fzf"])
+	}
+}
+
+func TestDetectLocalCachedByBinaryStamp(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "jq")
+	writeExecutable(t, bin)
+
+	r := NewFakeRunner().
+		OnPath("jq", bin).
+		On("jq --version", Stdout("jq-1.7.1\n"), Stdout("jq-1.8.0\n"))
+	d := newTestDetector(t, r)
+	d.UseCache(newTestCache(t, time.Hour))
+	jq := core.Tool{Key: "jq", Binary: "jq", Package: "jq", Method: core.MethodBrew}
+
+	for range 2 {
+		if ver, _ := d.DetectLocal(jq); ver != "1.7.1" {
+			t.Errorf("DetectLocal() = %q, want 1.7.1", ver)
+		}
+	}
+	if want := []string{"jq --version"}; !reflect.DeepEqual(r.Calls(), want) {
+		t.Errorf("calls = %v, want %v", r.Calls(), want)
+	}
+
+	// An upgrade rewrites the binary, which invalidates the entry
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(bin, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if ver, _ := d.DetectLocal(jq); ver != "1.8.0" {
+		t.Errorf("DetectLocal() after upgrade = %q, want 1.8.0", ver)
+	}
+}
@@ -0,0 +1,95 @@
+package updater
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// cargoStrategy handles Rust binaries installed with `cargo install`
+type cargoStrategy struct{}
+
+func init() {
+	Register(&cargoStrategy{}, core.MethodCargo)
+}
+
+func (s *cargoStrategy) Name() string { return "cargo" }
+
+func (s *cargoStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	return d.detectListed(t, parseCargoInstallList(d.runOutput("cargo", "install", "--list")), SourceCargoInstallList)
+}
+
+// WarmUp uses cargo-update's table when the subcommand is installed, and
+// asks crates.io about every installed crate otherwise
+func (s *cargoStrategy) WarmUp(d *Detector) {
+	// cargo install-update --list (exits non-zero when cargo-update is missing)
+	if out, err := d.output("cargo", "install-update", "--list"); err == nil {
+		for crate, latest := range parseCargoInstallUpdate(out) {
+			d.setRemote(s.Name(), crate, remoteInfo{latest, SourceCargoInstallUpdate})
+		}
+		return
+	}
+	installed := parseCargoInstallList(d.runOutput("cargo", "install", "--list"))
+	d.warmUpFromRegistry(s.Name(), core.RegistryCrates, sameNames(installed))
+}
+
+// Plan reinstalls for upgrades: `cargo install` replaces an older version
+// and does nothing when the latest one is already there
+func (s *cargoStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	if a == ActionUninstall {
+		return []PlanStep{step("cargo", "uninstall", packageName(t))}
+	}
+	return []PlanStep{step("cargo", "install", packageName(t))}
+}
+
+// LockKey is per manager: cargo locks its registry cache and ~/.cargo/bin
+func (s *cargoStrategy) LockKey(t core.Tool) string { return "cargo" }
+
+// Owns claims ~/.cargo/bin
+func (s *cargoStrategy) Owns(o Owner) bool { return o == OwnerCargo }
+
+func (s *cargoStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "cargo install", s.Plan(ActionUpgrade, t))
+}
+
+func (s *cargoStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "cargo install", s.Plan(ActionInstall, t))
+}
+
+func (s *cargoStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "cargo uninstall", s.Plan(ActionUninstall, t))
+}
+
+// parseCargoInstallList handles `cargo install --list`: a "<crate> v<version>:"
+// line per crate (git installs append their source), followed by indented
+// executable names
+func parseCargoInstallList(out string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		result[fields[0]] = strings.TrimSuffix(strings.TrimPrefix(fields[1], "v"), ":")
+	}
+	return result
+}
+
+// parseCargoInstallUpdate handles the table of `cargo install-update --list`:
+//
+//	Package       Installed  Latest   Needs update
+//	ripgrep       v13.0.0    v14.1.0  Yes
+//
+// Only crates that need an update are returned, like the other outdated lists.
+func parseCargoInstallUpdate(out string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[len(fields)-1] != "Yes" {
+			continue
+		}
+		result[fields[0]] = strings.TrimPrefix(fields[2], "v")
+	}
+	return result
+}
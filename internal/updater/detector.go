@@ -1,11 +1,8 @@
 package updater
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"os"
-	"os/exec"
 	"strings"
 	"sync"
 	"time"
@@ -13,288 +10,407 @@ import (
 	"github.com/dpeluche/spark/internal/core"
 )
 
+// Detection sources reported alongside each version
+const (
+	SourcePath         = "path"          // <binary> --version found on PATH
+	SourceLocalBin     = "local_bin"     // ~/.local/bin/<binary> --version
+	SourceNpmList      = "npm_list"      // npm list -g
+	SourceBrewList     = "brew_list"     // brew list --versions
+	SourceAppBundle    = "app_bundle"    // Info.plist of a macOS .app
+	SourceGit          = "git"           // Commit hash of a git checkout
+	SourceBrewOutdated = "brew_outdated" // brew outdated --json=v2
+	SourceNpmOutdated  = "npm_outdated"  // npm outdated -g --json
+	SourceNotOutdated  = "not_outdated"  // Not listed as outdated, assumed latest
+	SourceNone         = ""              // Nothing detected
+)
+
+// remoteInfo is a latest-version entry and where it came from
+type remoteInfo struct {
+	Version string
+	Source  string
+}
+
 // Detector handles version checking logic
 type Detector struct {
 	cacheMutex    sync.RWMutex
-	outdatedCache map[string]string // Package Name -> Latest Version
+	outdatedCache map[string]remoteInfo // Package Name -> Latest Version
+	fetching      map[string]remoteInfo // Filled by the warm-up fetchers, swapped in when done
+	fillMutex     sync.Mutex            // Serializes writes from the parallel warm-up fetchers
+	warmUpMutex   sync.Mutex            // One warm-up at a time
 	hasWarmedUp   bool
+	stale         bool   // outdatedCache came from an expired disk cache, a refresh is due
+	disk          *Cache // Optional on-disk cache, see UseCache
+	registries    *Registries
+	latestMutex   sync.Mutex
+	latest        map[string]remoteInfo // Registry lookups of this run, by "kind:name"
+	fileRegistry  map[string]string     // Registries named by installed files, by path (see registryNamer)
+	runner        CommandRunner
+	home          string // $HOME, for ~/.local/bin and dotfile installs
+	path          string // $PATH, searched for every copy of a binary (see Installations)
 }
 
 func NewDetector() *Detector {
+	return NewDetectorWithRunner(ExecRunner{})
+}
+
+// NewDetectorWithRunner returns a Detector that runs every command through r
+func NewDetectorWithRunner(r CommandRunner) *Detector {
 	return &Detector{
-		outdatedCache: make(map[string]string),
+		outdatedCache: make(map[string]remoteInfo),
+		latest:        make(map[string]remoteInfo),
+		fileRegistry:  make(map[string]string),
+		runner:        r,
+		home:          os.Getenv("HOME"),
+		path:          os.Getenv("PATH"),
 	}
 }
 
-// WarmUpCache fetches brew info once to speed up subsequent checks
-func (d *Detector) WarmUpCache() {
+// UseCache makes the Detector read and write detection results through c.
+// Call it before the first detection.
+func (d *Detector) UseCache(c *Cache) {
+	d.disk = c
+}
+
+// UseRegistries lets DetectRemote ask package registries for tools the
+// package managers cannot answer for: missing tools and tools whose
+// inventory entry names a registry (curl installs, manual downloads).
+func (d *Detector) UseRegistries(r *Registries) {
+	d.registries = r
+}
+
+// LoadStaleCache fills the remote data from an expired disk cache so results
+// can be shown while WarmUpCache fetches fresh ones. It reports whether
+// anything was loaded; a fresh cache is left to WarmUpCache.
+func (d *Detector) LoadStaleCache() bool {
+	if d.disk == nil {
+		return false
+	}
+	entries, fresh, ok := d.disk.remote()
+	if !ok || fresh {
+		return false
+	}
+
 	d.cacheMutex.Lock()
 	defer d.cacheMutex.Unlock()
-
 	if d.hasWarmedUp {
+		return false
+	}
+	d.outdatedCache = entries
+	d.hasWarmedUp = true
+	d.stale = true
+	return true
+}
+
+// IsStale reports whether remote versions come from an expired disk cache
+func (d *Detector) IsStale() bool {
+	d.cacheMutex.RLock()
+	defer d.cacheMutex.RUnlock()
+	return d.stale
+}
+
+// WarmUpCache asks every registered strategy for its outdated list once to speed up subsequent checks.
+// A disk cache younger than its TTL is used instead of running the package managers.
+func (d *Detector) WarmUpCache() {
+	d.warmUpMutex.Lock()
+	defer d.warmUpMutex.Unlock()
+
+	d.cacheMutex.RLock()
+	done := d.hasWarmedUp && !d.stale
+	d.cacheMutex.RUnlock()
+	if done {
 		return
 	}
 
+	if d.disk != nil {
+		if entries, fresh, ok := d.disk.remote(); ok && fresh {
+			d.swapRemote(entries)
+			return
+		}
+	}
+
+	// Fetch into a separate map so stale entries stay readable meanwhile
+	d.fetching = make(map[string]remoteInfo)
 	var wg sync.WaitGroup
-	wg.Add(2)
+	for _, s := range strategies() {
+		wg.Add(1)
+		go func(s Strategy) {
+			defer wg.Done()
+			s.WarmUp(d)
+		}(s)
+	}
+	wg.Wait()
 
-	// Fetch Brew Outdated
-	go func() {
-		defer wg.Done()
-		d.fetchBrewOutdated()
-	}()
+	entries := d.fetching
+	d.fetching = nil
+	d.swapRemote(entries)
 
-	// Fetch NPM Outdated
-	go func() {
-		defer wg.Done()
-		d.fetchNpmOutdated()
-	}()
+	if d.disk != nil {
+		d.disk.setRemote(entries)
+		d.disk.Save() // Best effort, the next run just fetches again
+	}
+}
 
-	wg.Wait()
+// swapRemote publishes a complete set of outdated lists
+func (d *Detector) swapRemote(entries map[string]remoteInfo) {
+	d.cacheMutex.Lock()
+	defer d.cacheMutex.Unlock()
+	d.outdatedCache = entries
 	d.hasWarmedUp = true
+	d.stale = false
 }
 
-type brewOutdatedItem struct {
-	Name           string `json:"name"`
-	CurrentVersion string `json:"current_version"` // This is actually the "latest" available in brew formulae usually?
-	// Brew JSON output for outdated:
-	// [{"name":"fzf","installed_versions":["0.45.0"],"current_version":"0.46.0",...}]
+// SaveCache writes the disk cache, if any, including local versions detected so far
+func (d *Detector) SaveCache() error {
+	if d.disk == nil {
+		return nil
+	}
+	return d.disk.Save()
 }
 
-func (d *Detector) fetchBrewOutdated() {
-	// brew outdated --json=v2
-	cmd := exec.Command("brew", "outdated", "--json=v2")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	// Ignore errors, brew outdated returns non-zero if outdated items exist
-	_ = cmd.Run()
+// setRemote records a latest version during warm-up; strategies run concurrently.
+// Entries are namespaced by strategy so "jq" from apt and "jq" from brew never collide.
+func (d *Detector) setRemote(namespace, pkg string, info remoteInfo) {
+	d.fillMutex.Lock()
+	defer d.fillMutex.Unlock()
+	d.fetching[remoteCacheKey(namespace, pkg)] = info
+}
 
-	var data struct {
-		Formulae []brewOutdatedItem `json:"formulae"`
-		Casks    []brewOutdatedItem `json:"casks"`
-	}
+func remoteCacheKey(namespace, pkg string) string {
+	return namespace + ":" + pkg
+}
+
+func (d *Detector) GetRemoteVersion(t core.Tool, localVersion string) string {
+	version, _ := d.DetectRemote(t, localVersion)
+	return version
+}
+
+// DetectRemote returns the latest known version of t and the source it came from
+func (d *Detector) DetectRemote(t core.Tool, localVersion string) (string, string) {
+	version, source, _ := d.DetectRemoteCached(t, localVersion)
+	return version, source
+}
+
+// DetectRemoteCached is DetectRemote that also reports whether the answer
+// comes from an expired disk cache (see LoadStaleCache)
+func (d *Detector) DetectRemoteCached(t core.Tool, localVersion string) (version, source string, stale bool) {
+	d.cacheMutex.RLock()
+	version, source = d.lookupRemote(t, localVersion)
+	stale = d.stale && source != SourceNone
+	d.cacheMutex.RUnlock()
 
-	if err := json.Unmarshal(out.Bytes(), &data); err == nil {
-		for _, item := range data.Formulae {
-			d.outdatedCache[item.Name] = item.CurrentVersion
+	// The package manager knows best; registries fill in where it cannot answer
+	if source != SourceNotOutdated && source != SourceNone {
+		return version, source, stale
+	}
+	if s, ok := StrategyFor(t.Method); ok {
+		if f, ok := s.(latestFinder); ok {
+			ref := s.Name() + ":" + packageName(t)
+			if v, src, ok := d.cachedLatest(ref, func() (string, string, error) {
+				v, err := f.Latest(d, t)
+				return v, SourceVersionManager, err
+			}); ok {
+				return v, src, false
+			}
+			return version, source, stale
+		}
+	}
+	ref, explicit := registryFor(t)
+	if s, ok := StrategyFor(t.Method); ok && ref == "" {
+		if n, ok := s.(registryNamer); ok {
+			ref, explicit = n.Registry(d, t), true // The tool itself says where it comes from
 		}
-		for _, item := range data.Casks {
-			d.outdatedCache[item.Name] = item.CurrentVersion
+	}
+	if ref != "" && (explicit || localVersion == "MISSING") {
+		if v, src, ok := d.latestFromRegistry(ref); ok {
+			return v, src, false
 		}
 	}
+	return version, source, stale
 }
 
-type npmOutdatedItem struct {
-	Current  string `json:"current"`
-	Wanted   string `json:"wanted"`
-	Latest   string `json:"latest"`
-	Location string `json:"location"`
+// latestFromRegistry looks ref up once per run, reusing the disk cache within its TTL
+func (d *Detector) latestFromRegistry(ref string) (string, string, bool) {
+	if d.registries == nil {
+		return "", "", false
+	}
+	return d.cachedLatest(ref, func() (string, string, error) {
+		return d.registries.Latest(context.Background(), ref)
+	})
 }
 
-func (d *Detector) fetchNpmOutdated() {
-	// npm outdated -g --json
-	cmd := exec.Command("npm", "outdated", "-g", "--json")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	// Ignore errors
-	_ = cmd.Run()
-
-	var data map[string]npmOutdatedItem
-	if err := json.Unmarshal(out.Bytes(), &data); err == nil {
-		for pkg, info := range data {
-			d.outdatedCache[pkg] = info.Latest
+// cachedLatest calls fetch once per run for ref, reusing the disk cache
+// within its TTL
+func (d *Detector) cachedLatest(ref string, fetch func() (string, string, error)) (string, string, bool) {
+	d.latestMutex.Lock()
+	info, ok := d.latest[ref]
+	d.latestMutex.Unlock()
+	if ok {
+		return info.Version, info.Source, info.Version != ""
+	}
+
+	if d.disk != nil {
+		if version, source, ok := d.disk.registry(ref); ok {
+			return version, source, true
 		}
 	}
+
+	version, source, err := fetch()
+	if err != nil {
+		version, source = "", SourceNone // Remember the failure for this run only
+	} else if d.disk != nil {
+		d.disk.setRegistry(ref, version, source)
+	}
+
+	d.latestMutex.Lock()
+	d.latest[ref] = remoteInfo{Version: version, Source: source}
+	d.latestMutex.Unlock()
+	return version, source, err == nil
 }
 
-func (d *Detector) GetRemoteVersion(t core.Tool, localVersion string) string {
-	d.cacheMutex.RLock()
-	defer d.cacheMutex.RUnlock()
+func (d *Detector) lookupRemote(t core.Tool, localVersion string) (string, string) {
 
 	// If we haven't warmed up or cache is empty, we might return "Unknown" or force a check.
 	// But assuming WarmUp runs first.
 
 	if localVersion == "MISSING" {
-		return "Unknown" // We don't check for uninstalled tools yet
+		return "Unknown", SourceNone // We don't check for uninstalled tools yet
 	}
 
 	// If checking a package
-	if latest, ok := d.outdatedCache[t.Package]; ok {
-		return latest
+	if s, ok := StrategyFor(t.Method); ok {
+		if latest, ok := d.outdatedCache[remoteCacheKey(s.Name(), t.Package)]; ok {
+			return latest.Version, latest.Source
+		}
 	}
 
 	// If not in outdated list, and we have a local version,
 	// it usually means Local is Latest.
 	if d.hasWarmedUp {
-		return localVersion
+		return localVersion, SourceNotOutdated
 	}
 
-	return "Checking..."
+	return "Checking...", SourceNone
 }
 
 func (d *Detector) GetLocalVersion(t core.Tool) string {
-	// Special handling for macOS applications
-	if t.Method == core.MethodMacApp {
-		return d.getMacAppVersion(t.Binary)
-	}
-
-	// Special handling for Oh My Zsh (git-based)
-	if t.Binary == "omz" {
-		return d.getOmzVersion()
-	}
-
-	// Special handling for Antigravity (multiple paths)
-	if t.Binary == "antigravity" {
-		return d.getAntigravityVersion()
-	}
-
-	// Generic CLI tool detection
-	return d.getCliToolVersion(t)
+	version, _ := d.DetectLocal(t)
+	return version
 }
 
-// getMacAppVersion detects version of macOS .app bundles
-func (d *Detector) getMacAppVersion(binary string) string {
-	appPaths := map[string]string{
-		"iterm":    "/Applications/iTerm.app",
-		"ghostty":  "/Applications/Ghostty.app",
-		"warp":     "/Applications/Warp.app",
-		"code":     "/Applications/Visual Studio Code.app",
-		"cursor":   "/Applications/Cursor.app",
-		"zed":      "/Applications/Zed.app",
-		"windsurf": "/Applications/Windsurf.app",
-		"docker":   "/Applications/Docker.app",
+// DetectLocal returns the installed version of t and the source it came from.
+// With a disk cache, the version of an unchanged binary is not detected again.
+func (d *Detector) DetectLocal(t core.Tool) (string, string) {
+	if d.disk == nil || managesVersions(t) {
+		return d.detectLocal(t) // Shims stay the same when the global version switches
 	}
 
-	appPath, ok := appPaths[binary]
+	path, modTime, ok := d.binaryStamp(t)
 	if !ok {
-		return "MISSING"
+		return d.detectLocal(t)
+	}
+	if version, source, hit := d.disk.local(t.Key, path, modTime); hit {
+		return version, source
 	}
 
-	return getAppVersion(appPath)
+	version, source := d.detectLocal(t)
+	if version != "MISSING" {
+		d.disk.setLocal(t.Key, path, modTime, version, source)
+	}
+	return version, source
 }
 
-// getOmzVersion gets Oh My Zsh git commit hash
-func (d *Detector) getOmzVersion() string {
-	omzPath := os.Getenv("HOME") + "/.oh-my-zsh"
-	if _, err := os.Stat(omzPath); err != nil {
-		return "MISSING"
+// binaryStamp resolves t's binary on PATH and returns its path and mtime.
+// Symlinks are followed, so a package manager switching versions changes the stamp.
+func (d *Detector) binaryStamp(t core.Tool) (string, time.Time, bool) {
+	if t.Binary == "" {
+		return "", time.Time{}, false
 	}
-
-	cmd := exec.Command("git", "--git-dir="+omzPath+"/.git", "--work-tree="+omzPath, "rev-parse", "--short", "HEAD")
-	out, err := cmd.Output()
+	path, err := d.runner.LookPath(t.Binary)
+	if err != nil || path == "" {
+		return "", time.Time{}, false
+	}
+	info, err := os.Stat(path)
 	if err != nil {
-		return "Installed"
+		return "", time.Time{}, false
 	}
-	return strings.TrimSpace(string(out))
+	return path, info.ModTime(), true
 }
 
-// getAntigravityVersion checks multiple possible installation paths
-func (d *Detector) getAntigravityVersion() string {
-	customPath := os.Getenv("HOME") + "/.antigravity/antigravity/bin/antigravity"
-	if _, err := os.Stat(customPath); err == nil {
-		output := runCmd(customPath, "--version")
-		return ParseToolSpecificVersion("antigravity", output)
+func (d *Detector) detectLocal(t core.Tool) (string, string) {
+	if s, ok := StrategyFor(t.Method); ok {
+		return s.DetectLocal(d, t)
 	}
-	output := runCmd("antigravity", "--version")
-	return ParseToolSpecificVersion("antigravity", output)
+
+	// Generic CLI tool detection
+	return d.getCliToolVersion(t)
 }
 
 // getCliToolVersion detects version for standard CLI tools
-func (d *Detector) getCliToolVersion(t core.Tool) string {
+func (d *Detector) getCliToolVersion(t core.Tool) (string, string) {
 	// 1. Try finding binary in PATH
-	path, err := exec.LookPath(t.Binary)
+	path, err := d.runner.LookPath(t.Binary)
 	if err == nil && path != "" {
 		// Try standard --version
-		output := runCmd(t.Binary, "--version")
+		output := d.runCmd(t.Binary, "--version")
 		if output != "" && output != "MISSING" && output != "Unknown" {
-			return ParseToolSpecificVersion(t.Binary, output)
+			return ParseToolSpecificVersion(t.Binary, output), SourcePath
 		}
-		
+
 		// ... (version / -v checks) ...
 	}
 
 	// 1.5 Fallback: Check ~/.local/bin explicitly (Common for Toad, Droid, Python tools)
-	home := os.Getenv("HOME")
-	localBin := home + "/.local/bin/" + t.Binary
+	localBin := d.home + "/.local/bin/" + t.Binary
 	if _, err := os.Stat(localBin); err == nil {
-		output := runCmd(localBin, "--version")
+		output := d.runCmd(localBin, "--version")
 		if output != "" && output != "MISSING" {
-			return ParseToolSpecificVersion(t.Binary, output)
+			return ParseToolSpecificVersion(t.Binary, output), SourceLocalBin
 		}
 	}
 
 	// 2. Fallback: Check NPM Global List (if it's an NPM tool)
 	if t.Method == core.MethodNpmPkg || t.Method == core.MethodNpmSys || t.Package != "" {
 		// ... existing npm logic ...
-		cmd := exec.Command("npm", "list", "-g", "--depth=0", "--json", t.Package)
-		out, err := cmd.Output()
+		out, err := d.output("npm", "list", "-g", "--depth=0", "--json", t.Package)
 		if err == nil {
-			outStr := string(out)
+			outStr := out
 			if strings.Contains(outStr, "\"version\":") {
 				parts := strings.Split(outStr, "\"version\":")
 				if len(parts) > 1 {
 					ver := strings.Split(parts[1], "\"")[1]
-					return CleanVersionString(ver)
+					return CleanVersionString(ver), SourceNpmList
 				}
 			}
 		}
 	}
 
-	// 3. Fallback: Check Homebrew explicitly (if it's a Brew tool)
-	if t.Method == core.MethodBrew || t.Method == core.MethodBrewPkg {
-		// brew list --versions <package>
-		// Output: "kubernetes-cli 1.28.2"
-		cmd := exec.Command("brew", "list", "--versions", t.Package)
-		out, err := cmd.Output()
-		if err == nil && len(out) > 0 {
-			fields := strings.Fields(string(out))
-			if len(fields) >= 2 {
-				// The version is usually the second field
-				return CleanVersionString(fields[len(fields)-1])
-			}
-		}
-	}
-
-	return "MISSING"
+	return "MISSING", SourceNone
 }
 
 // Helper functions
 
-func getAppVersion(appPath string) string {
-	plistPath := appPath + "/Contents/Info.plist"
-	if _, err := os.Stat(plistPath); os.IsNotExist(err) {
-		return "MISSING"
-	}
-	cmd := exec.Command("defaults", "read", plistPath, "CFBundleShortVersionString")
-	out, err := cmd.Output()
-	if err != nil {
-		return "Detected"
-	}
-	return strings.TrimSpace(string(out))
-}
-
-func runCmd(name string, args ...string) string {
+func (d *Detector) runCmd(name string, args ...string) string {
 	// Increase timeout to 5 seconds for slower tools (AI runtimes)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, name, args...)
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-	
-	err := cmd.Run()
-	if err != nil {
+	res := d.runner.Run(ctx, Command{Name: name, Args: args})
+	if res.Err != nil {
 		// If fails, return empty to signal caller to try fallback or return MISSING
 		return ""
 	}
-	
+
 	// Some tools print version to stderr (e.g. java sometimes, or python)
-	output := strings.TrimSpace(out.String())
+	output := strings.TrimSpace(res.Stdout)
 	if output == "" {
-		output = strings.TrimSpace(stderr.String())
+		output = strings.TrimSpace(res.Stderr)
 	}
-	
+
 	return CleanVersionString(output)
 }
+
+// output returns stdout of a command and fails like exec.Cmd.Output
+func (d *Detector) output(name string, args ...string) (string, error) {
+	res := d.runner.Run(context.Background(), Command{Name: name, Args: args})
+	return res.Stdout, res.Err
+}
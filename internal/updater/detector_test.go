@@ -0,0 +1,215 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func newTestDetector(t *testing.T, r *FakeRunner) *Detector {
+	t.Helper()
+	d := NewDetectorWithRunner(r)
+	d.home = t.TempDir()
+	return d
+}
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGetCliToolVersionFallbacks(t *testing.T) {
+	claude := core.Tool{Name: "Claude CLI", Binary: "claude", Package: "@anthropic-ai/claude-code", Method: core.MethodClaude}
+
+	tests := []struct {
+		name       string
+		tool       core.Tool
+		setup      func(r *FakeRunner, home string)
+		wantVer    string
+		wantSource string
+		wantCalls  []string
+	}{
+		{
+			name: "binary on PATH",
+			tool: core.Tool{Binary: "jq", Package: "jq", Method: core.MethodBrew},
+			setup: func(r *FakeRunner, home string) {
+				r.OnPath("jq", "/usr/bin/jq").On("jq --version", Stdout("jq-1.7.1\n"))
+			},
+			wantVer:    "1.7.1",
+			wantSource: SourcePath,
+			wantCalls:  []string{"jq --version"},
+		},
+		{
+			name: "tool specific parser",
+			tool: core.Tool{Binary: "go", Package: "go", Method: core.MethodBrew},
+			setup: func(r *FakeRunner, home string) {
+				r.OnPath("go", "/usr/local/go/bin/go").On("go --version", Stdout("go version go1.23.4 darwin/arm64\n"))
+			},
+			wantVer:    "1.23.4",
+			wantSource: SourcePath,
+		},
+		{
+			name: "version on stderr",
+			tool: core.Tool{Binary: "python3", Method: core.MethodBrew},
+			setup: func(r *FakeRunner, home string) {
+				r.OnPath("python3", "/usr/bin/python3").On("python3 --version", Result{Stderr: "Python 3.13.1\n"})
+			},
+			wantVer:    "3.13.1",
+			wantSource: SourcePath,
+		},
+		{
+			name: "PATH --version fails, ~/.local/bin works",
+			tool: core.Tool{Binary: "toad", Method: core.MethodToad},
+			setup: func(r *FakeRunner, home string) {
+				writeExecutable(t, filepath.Join(home, ".local", "bin", "toad"))
+				r.OnPath("toad", "/usr/bin/toad").
+					On("toad --version", Failure(1, "boom")).
+					On(home+"/.local/bin/toad --version", Stdout("toad 0.5.2\n"))
+			},
+			wantVer:    "0.5.2",
+			wantSource: SourceLocalBin,
+		},
+		{
+			name: "npm global list",
+			tool: claude,
+			setup: func(r *FakeRunner, home string) {
+				r.On("npm list -g --depth=0 --json @anthropic-ai/claude-code",
+					Stdout(`{"dependencies": {"@anthropic-ai/claude-code": {"version": "1.0.3", "overridden": false}}}`))
+			},
+			wantVer:    "1.0.3",
+			wantSource: SourceNpmList,
+			wantCalls:  []string{"npm list -g --depth=0 --json @anthropic-ai/claude-code"},
+		},
+		{
+			name: "npm list without the package",
+			tool: claude,
+			setup: func(r *FakeRunner, home string) {
+				r.On("npm list -g --depth=0 --json @anthropic-ai/claude-code", Stdout(`{}`))
+			},
+			wantVer:    "MISSING",
+			wantSource: SourceNone,
+		},
+		{
+			name:       "nothing found",
+			tool:       core.Tool{Binary: "droid", Method: core.MethodDroid},
+			setup:      func(r *FakeRunner, home string) {},
+			wantVer:    "MISSING",
+			wantSource: SourceNone,
+			wantCalls:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewFakeRunner()
+			d := newTestDetector(t, r)
+			tt.setup(r, d.home)
+
+			gotVer, gotSource := d.getCliToolVersion(tt.tool)
+			if gotVer != tt.wantVer || gotSource != tt.wantSource {
+				t.Errorf("getCliToolVersion() = (%q, %q), want (%q, %q)", gotVer, gotSource, tt.wantVer, tt.wantSource)
+			}
+			if tt.wantCalls != nil && !reflect.DeepEqual(r.Calls(), tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", r.Calls(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestBrewDetectLocalFallsBackToBrewList(t *testing.T) {
+	r := NewFakeRunner().On("brew list --versions kubernetes-cli", Stdout("kubernetes-cli 1.28.1 1.28.2\n"))
+	d := newTestDetector(t, r)
+
+	tool := core.Tool{Binary: "kubectl", Package: "kubernetes-cli", Method: core.MethodBrew}
+	ver, source := d.DetectLocal(tool)
+	if ver != "1.28.2" || source != SourceBrewList {
+		t.Errorf("DetectLocal() = (%q, %q), want (1.28.2, %q)", ver, source, SourceBrewList)
+	}
+
+	want := []string{
+		"npm list -g --depth=0 --json kubernetes-cli",
+		"brew list --versions kubernetes-cli",
+	}
+	if !reflect.DeepEqual(r.Calls(), want) {
+		t.Errorf("calls = %q, want %q", r.Calls(), want)
+	}
+}
+
+func TestLinuxDetectLocalFallsBackToCli(t *testing.T) {
+	r := NewFakeRunner().
+		OnPath("dpkg-query", "/usr/bin/dpkg-query").
+		On("dpkg-query -W -f=${Status}|${Version} jq", Stdout("deinstall ok config-files|1.6-2.1ubuntu3")).
+		OnPath("jq", "/usr/local/bin/jq").
+		On("jq --version", Stdout("jq-1.7.1"))
+	d := newTestDetector(t, r)
+
+	ver, source := d.DetectLocal(core.Tool{Binary: "jq", Package: "jq", Method: core.MethodApt})
+	if ver != "1.7.1" || source != SourcePath {
+		t.Errorf("DetectLocal() = (%q, %q), want (1.7.1, %q)", ver, source, SourcePath)
+	}
+}
+
+func TestWarmUpCacheAndDetectRemote(t *testing.T) {
+	r := NewFakeRunner().
+		// Both commands exit 1 when something is outdated
+		On("brew outdated --json=v2", exitWithStdout(1, readFixture(t, "brew_outdated.json"))).
+		On("npm outdated -g --json", exitWithStdout(1, readFixture(t, "npm_outdated.json"))).
+		OnPath("apt", "/usr/bin/apt").
+		On("apt list --upgradable", Stdout(readFixture(t, "apt_upgradable.txt")))
+	d := newTestDetector(t, r)
+	d.WarmUpCache()
+
+	tests := []struct {
+		name       string
+		tool       core.Tool
+		local      string
+		wantVer    string
+		wantSource string
+	}{
+		{"brew formula", core.Tool{Package: "fzf", Method: core.MethodBrew}, "0.45.0", "0.46.0", SourceBrewOutdated},
+		{"brew cask", core.Tool{Package: "ghostty", Method: core.MethodMacApp}, "1.0.0", "1.0.1", SourceBrewOutdated},
+		{"npm", core.Tool{Package: "@anthropic-ai/claude-code", Method: core.MethodClaude}, "1.0.3", "1.0.17", SourceNpmOutdated},
+		{"apt", core.Tool{Package: "jq", Method: core.MethodApt}, "1.6-r2.1ubuntu3", "1.6-r2.1ubuntu3.1", SourceAptUpgradable},
+		{"same name, other manager", core.Tool{Package: "jq", Method: core.MethodBrew}, "1.7.1", "1.7.1", SourceNotOutdated},
+		{"cask name is not a formula", core.Tool{Package: "ghostty", Method: core.MethodBrew}, "1.0.0", "1.0.0", SourceNotOutdated},
+		{"missing", core.Tool{Package: "fzf", Method: core.MethodBrew}, "MISSING", "Unknown", SourceNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ver, source := d.DetectRemote(tt.tool, tt.local)
+			if ver != tt.wantVer || source != tt.wantSource {
+				t.Errorf("DetectRemote() = (%q, %q), want (%q, %q)", ver, source, tt.wantVer, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestDetectRemoteBeforeWarmUp(t *testing.T) {
+	d := newTestDetector(t, NewFakeRunner())
+	if ver, _ := d.DetectRemote(core.Tool{Package: "fzf", Method: core.MethodBrew}, "0.45.0"); ver != "Checking..." {
+		t.Errorf("DetectRemote() = %q, want Checking...", ver)
+	}
+}
+
+func exitWithStdout(code int, stdout string) Result {
+	r := Failure(code, "")
+	r.Stdout = stdout
+	return r
+}
+
+func writeExecutable(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,106 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// dnfStrategy handles Fedora/RHEL packages (rpm database, dnf upgrades)
+type dnfStrategy struct{}
+
+func init() {
+	Register(&dnfStrategy{}, core.MethodDnf)
+}
+
+func (s *dnfStrategy) Name() string { return "dnf" }
+
+func (s *dnfStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	out := d.runOutput("rpm", "-q", "--qf", "%{VERSION}-%{RELEASE}", t.Package)
+	return d.detectDistroPackage(t, parseRpmQuery(out), SourceRpm)
+}
+
+func (s *dnfStrategy) WarmUp(d *Detector) {
+	// dnf check-update -q exits 100 when updates are available
+	out := d.runOutput("dnf", "check-update", "-q")
+	for pkg, latest := range parseDnfCheckUpdate(out) {
+		d.setRemote(s.Name(), pkg, remoteInfo{latest, SourceDnfCheckUpdate})
+	}
+}
+
+func (s *dnfStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	switch a {
+	case ActionInstall:
+		return []PlanStep{privilegedStep("dnf", "install", "-y", "-q", t.Package)}
+	case ActionUninstall:
+		return []PlanStep{privilegedStep("dnf", "remove", "-y", "-q", t.Package)}
+	}
+	return []PlanStep{privilegedStep("dnf", "upgrade", "-y", "-q", t.Package)}
+}
+
+// LockKey is per manager: dnf holds the rpm database lock
+func (s *dnfStrategy) LockKey(t core.Tool) string { return "dnf" }
+
+// Owns claims the system directories the package database installs into
+func (s *dnfStrategy) Owns(o Owner) bool { return o == OwnerSystem }
+
+func (s *dnfStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "dnf upgrade", s.Plan(ActionUpgrade, t))
+}
+
+func (s *dnfStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "dnf install", s.Plan(ActionInstall, t))
+}
+
+func (s *dnfStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "dnf remove", s.Plan(ActionUninstall, t))
+}
+
+// parseRpmQuery handles `rpm -q --qf '%{VERSION}-%{RELEASE}'`
+func parseRpmQuery(out string) string {
+	out = strings.TrimSpace(out)
+	if out == "" || strings.Contains(out, "is not installed") {
+		return "MISSING"
+	}
+	return normalizeDistroVersion(strings.Fields(out)[0])
+}
+
+// parseDnfCheckUpdate handles lines like:
+// jq.x86_64    1.7.1-1.fc39    updates
+func parseDnfCheckUpdate(out string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		// The obsoleting section and wrapped lines don't have the 3-column shape
+		if len(fields) != 3 || strings.HasPrefix(line, " ") {
+			continue
+		}
+		dot := strings.LastIndex(fields[0], ".")
+		if dot <= 0 {
+			continue
+		}
+		result[fields[0][:dot]] = normalizeDistroVersion(fields[1])
+	}
+	return result
+}
+
+// RollbackSteps downgrades to the installed build, named by its full NEVRA
+// ("jq-1.7.1-1.fc39.x86_64") so dnf cannot pick another one
+func (s *dnfStrategy) RollbackSteps(ctx context.Context, e *Executor, t core.Tool) (string, []RollbackStep, error) {
+	out, err := e.combined(ctx, "rpm", "-q", t.Package)
+	fields := strings.Fields(out)
+	if err != nil || len(fields) == 0 {
+		return "", nil, fmt.Errorf("rpm found no installed %s", t.Package)
+	}
+	nevra := fields[0]
+	version := strings.TrimPrefix(nevra, t.Package+"-")
+	if dot := strings.LastIndex(version, "."); dot > 0 {
+		version = version[:dot] // Drop the architecture
+	}
+	return version, []RollbackStep{{
+		Args:       []string{"dnf", "downgrade", "-y", nevra},
+		Privileged: true,
+	}}, nil
+}
@@ -2,126 +2,140 @@ package updater
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
-	"strings"
+	"os"
 	"time"
 
 	"github.com/dpeluche/spark/internal/core"
 )
 
 // Executor handles the actual update process for tools
-type Executor struct{}
+type Executor struct {
+	runner    CommandRunner
+	onLine    LineFunc   // Live output sink, set per call by UpdateStreaming
+	rollbacks *Rollbacks // Recipes for ActionRollback, see UseRollbacks
+	home      string     // $HOME, where toolResolver strategies look for files
+}
 
 func NewExecutor() *Executor {
-	return &Executor{}
+	return NewExecutorWithRunner(ExecRunner{})
 }
 
-// Update attempts to update the specified tool
-func (e *Executor) Update(t core.Tool) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute) // Updates can take time
-	defer cancel()
+// NewExecutorWithRunner returns an Executor that runs every command through r
+func NewExecutorWithRunner(r CommandRunner) *Executor {
+	return &Executor{runner: r, home: os.Getenv("HOME")}
+}
+
+// Action is what the Executor does to a tool
+type Action int
 
-	switch t.Method {
-	case core.MethodBrew, core.MethodBrewPkg:
-		return e.updateBrew(ctx, t)
-	case core.MethodMacApp:
-		return e.updateMacApp(ctx, t)
-	case core.MethodNpmSys, core.MethodNpmPkg:
-		return e.updateNpm(ctx, t)
-	case core.MethodClaude:
-		return e.updateNpm(ctx, t) // Claude is an NPM package
-	case core.MethodOmz:
-		return e.updateOmz(ctx)
-	case core.MethodToad:
-		return e.updateToad(ctx)
-	case core.MethodManual, core.MethodDroid, core.MethodOpencode:
-		return fmt.Errorf("manual update required (check vendor portal)")
+const (
+	ActionUpgrade   Action = iota // Move an installed tool to its latest version
+	ActionInstall                 // Install a missing tool
+	ActionUninstall               // Remove an installed tool
+	ActionRollback                // Restore the version recorded before the last upgrade
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionInstall:
+		return "install"
+	case ActionUninstall:
+		return "uninstall"
+	case ActionRollback:
+		return "rollback"
 	default:
-		return fmt.Errorf("update method %s not implemented", t.Method)
+		return "upgrade"
 	}
 }
 
-func (e *Executor) updateToad(ctx context.Context) error {
-	// Toad (by Batrachian AI) installs via script to ~/.local/bin
-	// curl -fsSL https://batrachian.ai/install | sh
-	
-	cmd := exec.CommandContext(ctx, "sh", "-c", "curl -fsSL https://batrachian.ai/install | sh")
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("toad update failed: %s: %v", string(output), err)
+// Progressive is the action as shown while it runs ("Updating", "Installing")
+func (a Action) Progressive() string {
+	switch a {
+	case ActionInstall:
+		return "Installing"
+	case ActionUninstall:
+		return "Uninstalling"
+	case ActionRollback:
+		return "Rolling back"
+	default:
+		return "Updating"
 	}
-	return nil
 }
 
-func (e *Executor) updateBrew(ctx context.Context, t core.Tool) error {
-	// brew upgrade <package>
-	cmd := exec.CommandContext(ctx, "brew", "upgrade", t.Package)
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("brew upgrade failed: %s: %v", string(output), err)
-	}
-	return nil
-}
+// ErrAborted is returned when an update is cancelled through its context
+var ErrAborted = errors.New("aborted")
 
-func (e *Executor) updateMacApp(ctx context.Context, t core.Tool) error {
-	// Try upgrading via brew cask first
-	// We assume if it's a MacApp it might be managed by brew cask
-	// Check if it is a cask
-	checkCmd := exec.CommandContext(ctx, "brew", "list", "--cask", t.Package)
-	if err := checkCmd.Run(); err == nil {
-		cmd := exec.CommandContext(ctx, "brew", "upgrade", "--cask", t.Package)
-		if output, err := cmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("brew cask upgrade failed: %s: %v", string(output), err)
-		}
-		return nil
-	}
+// updateTimeout bounds a single upgrade; brew can legitimately take minutes
+const updateTimeout = 10 * time.Minute
 
-	// If not a cask, we can't auto-update it easily
-	return fmt.Errorf("manual update required (not a brew cask)")
+// Update attempts to update the specified tool. Cancelling ctx stops the
+// running command's whole process group and returns ErrAborted.
+func (e *Executor) Update(ctx context.Context, t core.Tool) error {
+	return e.Apply(ctx, ActionUpgrade, t)
 }
 
-func (e *Executor) updateNpm(ctx context.Context, t core.Tool) error {
-	// npm install -g <package>@latest
-	pkg := t.Package
-	if pkg == "" {
-		pkg = t.Binary
-	}
+// Install installs a missing tool with its method's install command
+func (e *Executor) Install(ctx context.Context, t core.Tool) error {
+	return e.Apply(ctx, ActionInstall, t)
+}
 
-	cmd := exec.CommandContext(ctx, "npm", "install", "-g", pkg+"@latest")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// Auto-recovery for EEXIST (broken symlinks or permissions)
-		outputStr := string(output)
-		if strings.Contains(outputStr, "EEXIST") {
-			// Retry with --force
-			cmdForce := exec.CommandContext(ctx, "npm", "install", "-g", pkg+"@latest", "--force")
-			if outputForce, errForce := cmdForce.CombinedOutput(); errForce != nil {
-				return fmt.Errorf("npm install failed (even with --force): %s: %v", string(outputForce), errForce)
-			}
-			return nil // Success with force
-		}
-		return fmt.Errorf("npm install failed: %s: %v", outputStr, err)
-	}
-	return nil
+// Uninstall removes t with its method's uninstall command
+func (e *Executor) Uninstall(ctx context.Context, t core.Tool) error {
+	return e.Apply(ctx, ActionUninstall, t)
 }
 
-func (e *Executor) updateOmz(ctx context.Context) error {
-	// omz update usually runs interactively or via script.
-	// The standard way is running the upgrade script.
-	// Often available as `omz update` alias, but that might not be in the path for non-interactive shells.
-	// We can try calling the script directly if we find it.
+// Rollback restores the version t had before its last recorded upgrade
+func (e *Executor) Rollback(ctx context.Context, t core.Tool) error {
+	return e.Apply(ctx, ActionRollback, t)
+}
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", "$ZSH/tools/upgrade.sh")
-	// Set env var to avoid interactive prompt if supported
-	cmd.Env = append(cmd.Env, "ZSH="+getEnv("ZSH", "~/.oh-my-zsh"))
+// Apply runs action a on t through the tool's strategy, with the same
+// timeout and abort handling for every action
+func (e *Executor) Apply(ctx context.Context, a Action, t core.Tool) error {
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout) // Updates can take time
+	defer cancel()
+
+	s, ok := StrategyFor(t.Method)
+	if !ok {
+		return fmt.Errorf("update method %s not implemented", t.Method)
+	}
+	t, err := e.resolve(s, a, t)
+	if err != nil {
+		return err
+	}
 
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("omz update failed: %s: %v", string(output), err)
+	switch a {
+	case ActionInstall:
+		err = s.Install(ctx, e, t)
+	case ActionUninstall:
+		err = s.Uninstall(ctx, e, t)
+	case ActionRollback:
+		err = e.rollback(ctx, t)
+	default:
+		err = s.Upgrade(ctx, e, t)
 	}
-	return nil
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(ctx.Err(), context.Canceled):
+		return ErrAborted
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("timed out after %s: %w", updateTimeout, err)
+	}
+	return err
+}
+
+// UpdateStreaming is Update with every command line and output line of the
+// upgrade passed to onLine as it happens
+func (e *Executor) UpdateStreaming(ctx context.Context, t core.Tool, onLine LineFunc) error {
+	return e.ApplyStreaming(ctx, ActionUpgrade, t, onLine)
 }
 
-// Helper to get env with fallback (simplified)
-func getEnv(key, fallback string) string {
-	// In a real scenario, we'd read actual os.Environ
-	return fallback
+// ApplyStreaming is Apply with live output, see UpdateStreaming
+func (e *Executor) ApplyStreaming(ctx context.Context, a Action, t core.Tool, onLine LineFunc) error {
+	streaming := *e
+	streaming.onLine = onLine
+	return streaming.Apply(ctx, a, t)
 }
@@ -0,0 +1,279 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestUpdateNpmRetriesWithForceOnEEXIST(t *testing.T) {
+	tool := core.Tool{Name: "Gemini CLI", Binary: "gemini", Package: "@google/gemini-cli", Method: core.MethodNpmPkg}
+	install := "npm install -g @google/gemini-cli@latest"
+	eexist := Failure(1, "npm ERR! code EEXIST\nnpm ERR! path /usr/local/bin/gemini\n")
+
+	tests := []struct {
+		name      string
+		results   map[string]Result
+		wantErr   string
+		wantCalls []string
+	}{
+		{
+			name:      "plain success",
+			results:   map[string]Result{install: Stdout("changed 1 package")},
+			wantCalls: []string{install},
+		},
+		{
+			name: "EEXIST then force succeeds",
+			results: map[string]Result{
+				install:              eexist,
+				install + " --force": Stdout("changed 1 package"),
+			},
+			wantCalls: []string{install, install + " --force"},
+		},
+		{
+			name: "EEXIST and force fails",
+			results: map[string]Result{
+				install:              eexist,
+				install + " --force": Failure(1, "npm ERR! code EACCES"),
+			},
+			wantErr:   "npm install failed (even with --force): npm ERR! code EACCES",
+			wantCalls: []string{install, install + " --force"},
+		},
+		{
+			name:      "other errors are not retried",
+			results:   map[string]Result{install: Failure(1, "npm ERR! code E404")},
+			wantErr:   "npm install failed: npm ERR! code E404",
+			wantCalls: []string{install},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewFakeRunner()
+			for cmd, res := range tt.results {
+				r.On(cmd, res)
+			}
+
+			err := NewExecutorWithRunner(r).Update(context.Background(), tool)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("Update() error = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("Update() error = %v, want %q", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(r.Calls(), tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", r.Calls(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestUpdateNpmUsesBinaryWithoutPackage(t *testing.T) {
+	r := NewFakeRunner().On("npm install -g opencode@latest", Stdout(""))
+	tool := core.Tool{Binary: "opencode", Method: core.MethodNpmSys}
+	if err := NewExecutorWithRunner(r).Update(context.Background(), tool); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+}
+
+func TestUpdateMacAppRequiresCask(t *testing.T) {
+	tool := core.Tool{Binary: "zed", Package: "zed", Method: core.MethodMacApp}
+
+	r := NewFakeRunner()
+	if err := NewExecutorWithRunner(r).Update(context.Background(), tool); err == nil || !strings.Contains(err.Error(), "not a brew cask") {
+		t.Errorf("Update() error = %v, want manual update", err)
+	}
+
+	r = NewFakeRunner().On("brew list --cask zed", Stdout("zed")).On("brew upgrade --cask zed", Stdout(""))
+	if err := NewExecutorWithRunner(r).Update(context.Background(), tool); err != nil {
+		t.Errorf("Update() error = %v", err)
+	}
+	if want := []string{"brew list --cask zed", "brew upgrade --cask zed"}; !reflect.DeepEqual(r.Calls(), want) {
+		t.Errorf("calls = %q, want %q", r.Calls(), want)
+	}
+}
+
+func TestUpgradeOmzRunsPlan(t *testing.T) {
+	omz := core.ToolState{Tool: core.Tool{Key: "omz", Name: "Oh My Zsh", Package: "oh-my-zsh", Method: core.MethodOmz}, LocalVersion: "abc123f"}
+	for _, custom := range []bool{false, true} {
+		home := t.TempDir()
+		dir := filepath.Join(home, ".oh-my-zsh")
+		t.Setenv("ZSH", "")
+		if custom {
+			dir = filepath.Join(t.TempDir(), "my zsh")
+			t.Setenv("ZSH", dir)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		script := filepath.Join(dir, "tools", "upgrade.sh")
+		r := NewFakeRunner().On(script, Stdout("Hooray! Oh My Zsh has been updated!"))
+		e := NewExecutorWithRunner(r)
+		e.home = home
+
+		// The dry run shows what the upgrade runs: the checkout's own script, no shell
+		p := e.Plan(ActionUpgrade, omz)
+		if want := "env " + shellQuote("ZSH="+dir) + " " + shellQuote(script); len(p.Steps) != 1 || p.Steps[0].Shell() != want {
+			t.Errorf("plan = %+v, want %s", p, want)
+		}
+		if err := e.Update(context.Background(), omz.Tool); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if calls := r.Calls(); len(calls) != 1 || calls[0] != script {
+			t.Errorf("calls = %q, want %s", calls, script)
+		}
+	}
+
+	// Nothing runs for a checkout that is not there
+	e := NewExecutorWithRunner(NewFakeRunner())
+	e.home = t.TempDir()
+	if err := e.Update(context.Background(), omz.Tool); err == nil {
+		t.Error("Update() without a checkout succeeded")
+	}
+}
+
+func TestUpdateUnknownMethod(t *testing.T) {
+	err := NewExecutorWithRunner(NewFakeRunner()).Update(context.Background(), core.Tool{Method: "custom"})
+	if err == nil || err.Error() != "update method custom not implemented" {
+		t.Errorf("Update() error = %v", err)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	script := "curl -fsSL https://batrachian.ai/install | sh"
+	tests := []struct {
+		name     string
+		tool     core.Tool
+		wantPlan string // "" when the install is manual
+		wantCall string
+	}{
+		{"brew formula", core.Tool{Package: "jq", Method: core.MethodBrew}, "brew install jq", "brew install jq"},
+		{"brew cask", core.Tool{Package: "zed", Method: core.MethodMacApp}, "brew install --cask zed", "brew install --cask zed"},
+		{"npm package", core.Tool{Package: "@openai/codex", Method: core.MethodNpmPkg}, "npm install -g @openai/codex", "npm install -g @openai/codex"},
+		{"vendor script", core.Tool{Method: core.MethodToad}, script, "sh -c " + script},
+		{"script without installer", core.Tool{Method: core.MethodDroid}, "", ""},
+		{"manual", core.Tool{Name: "Antigravity", Method: core.MethodManual}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanInstall(tt.tool); got != (tt.wantPlan != "") {
+				t.Errorf("CanInstall() = %v, want %v", got, tt.wantPlan != "")
+			}
+
+			r := NewFakeRunner()
+			wantCalls := []string{}
+			if tt.wantPlan != "" {
+				if got := PlannedCommand(ActionInstall, tt.tool); got != tt.wantPlan {
+					t.Errorf("PlannedCommand() = %q, want %q", got, tt.wantPlan)
+				}
+				r.On(tt.wantCall, Stdout(""))
+				wantCalls = []string{tt.wantCall}
+			}
+
+			err := NewExecutorWithRunner(r).Install(context.Background(), tt.tool)
+			switch {
+			case tt.wantPlan != "" && err != nil:
+				t.Fatalf("Install() error = %v", err)
+			case tt.wantPlan == "" && err == nil:
+				t.Fatal("Install() succeeded for a tool without an installer")
+			}
+			if calls := r.Calls(); !reflect.DeepEqual(calls, wantCalls) {
+				t.Errorf("calls = %q, want %q", calls, wantCalls)
+			}
+		})
+	}
+}
+
+func TestUninstall(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     core.Tool
+		wantPlan string // "" when the uninstall is manual
+		wantCall string
+	}{
+		{"brew formula", core.Tool{Package: "jq", Method: core.MethodBrew}, "brew uninstall jq", "brew uninstall jq"},
+		{"brew cask", core.Tool{Package: "zed", Method: core.MethodMacApp}, "brew uninstall --cask zed", "brew uninstall --cask zed"},
+		{"npm package", core.Tool{Package: "@charmland/crush", Method: core.MethodNpmPkg}, "npm uninstall -g @charmland/crush", "npm uninstall -g @charmland/crush"},
+		{"vendor script", core.Tool{Method: core.MethodDroid}, "rm -f ~/.local/bin/droid", "sh -c rm -f ~/.local/bin/droid"},
+		{"oh my zsh", core.Tool{Method: core.MethodOmz}, "", ""},
+		{"manual", core.Tool{Name: "Antigravity", Method: core.MethodManual}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanUninstall(tt.tool); got != (tt.wantPlan != "") {
+				t.Errorf("CanUninstall() = %v, want %v", got, tt.wantPlan != "")
+			}
+
+			r := NewFakeRunner()
+			wantCalls := []string{}
+			if tt.wantPlan != "" {
+				if got := PlannedCommand(ActionUninstall, tt.tool); got != tt.wantPlan {
+					t.Errorf("PlannedCommand() = %q, want %q", got, tt.wantPlan)
+				}
+				r.On(tt.wantCall, Stdout(""))
+				wantCalls = []string{tt.wantCall}
+			}
+
+			err := NewExecutorWithRunner(r).Uninstall(context.Background(), tt.tool)
+			switch {
+			case tt.wantPlan != "" && err != nil:
+				t.Fatalf("Uninstall() error = %v", err)
+			case tt.wantPlan == "" && err == nil:
+				t.Fatal("Uninstall() succeeded for a tool without an uninstaller")
+			}
+			if calls := r.Calls(); !reflect.DeepEqual(calls, wantCalls) {
+				t.Errorf("calls = %q, want %q", calls, wantCalls)
+			}
+		})
+	}
+}
+
+func TestDependents(t *testing.T) {
+	r := NewFakeRunner().
+		On("brew uses --installed node", Stdout("gemini-cli\nyarn\n")).
+		On("brew uses --installed jq", Stdout(""))
+	e := NewExecutorWithRunner(r)
+
+	tests := []struct {
+		tool core.Tool
+		want []string
+	}{
+		{core.Tool{Package: "node", Method: core.MethodBrew}, []string{"gemini-cli", "yarn"}},
+		{core.Tool{Package: "jq", Method: core.MethodBrew}, []string{}},
+		{core.Tool{Package: "@openai/codex", Method: core.MethodNpmPkg}, nil}, // npm globals have no reverse dependencies
+	}
+	for _, tt := range tests {
+		got, err := e.Dependents(context.Background(), tt.tool)
+		if err != nil {
+			t.Fatalf("Dependents(%s) error = %v", tt.tool.Package, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Dependents(%s) = %q, want %q", tt.tool.Package, got, tt.want)
+		}
+	}
+}
+
+func TestEveryMethodHasStrategy(t *testing.T) {
+	for _, m := range core.Methods {
+		if _, ok := StrategyFor(m); !ok {
+			t.Errorf("no strategy registered for method %q", m)
+		}
+	}
+}
+
+func TestUpdateAborted(t *testing.T) {
+	r := NewFakeRunner().On("brew upgrade jq", Stdout(""))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := NewExecutorWithRunner(r).Update(ctx, core.Tool{Package: "jq", Method: core.MethodBrew})
+	if !errors.Is(err, ErrAborted) {
+		t.Errorf("Update() error = %v, want ErrAborted", err)
+	}
+}
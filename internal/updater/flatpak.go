@@ -0,0 +1,66 @@
+package updater
+
+import (
+	"context"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// flatpakStrategy handles Flatpak applications, Package being the
+// application ID. Updates run unprivileged: polkit lets the active user
+// update system-wide apps, and user installations need nothing more.
+type flatpakStrategy struct{}
+
+func init() {
+	Register(&flatpakStrategy{}, core.MethodFlatpak)
+}
+
+func (s *flatpakStrategy) Name() string { return "flatpak" }
+
+func (s *flatpakStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	out := d.runOutput("flatpak", "list", "--app", "--columns=application,version")
+	return d.detectListed(t, parseFlatpakColumns(out), SourceFlatpakList)
+}
+
+// WarmUp also fills the cask entries of mac_app tools installed as a
+// Flatpak, the way brewStrategy fills casks
+func (s *flatpakStrategy) WarmUp(d *Detector) {
+	out := d.runOutput("flatpak", "remote-ls", "--updates", "--app", "--columns=application,version")
+	for id, latest := range parseFlatpakColumns(out) {
+		info := remoteInfo{latest, SourceFlatpakUpdates}
+		d.setRemote(s.Name(), id, info)
+		for _, app := range desktopApps {
+			if app.flatpak == id {
+				d.setRemote(caskCacheName, app.cask, info)
+			}
+		}
+	}
+}
+
+func (s *flatpakStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	switch a {
+	case ActionInstall:
+		return []PlanStep{step("flatpak", "install", "-y", "--noninteractive", "flathub", packageName(t))}
+	case ActionUninstall:
+		return []PlanStep{step("flatpak", "uninstall", "-y", "--noninteractive", packageName(t))}
+	}
+	return []PlanStep{step("flatpak", "update", "-y", "--noninteractive", packageName(t))}
+}
+
+// LockKey is per manager: apps share runtimes and the OSTree repository
+func (s *flatpakStrategy) LockKey(t core.Tool) string { return "flatpak" }
+
+// Owns claims the exported launchers and app directories of Flatpak installations
+func (s *flatpakStrategy) Owns(o Owner) bool { return o == OwnerFlatpak }
+
+func (s *flatpakStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "flatpak update", s.Plan(ActionUpgrade, t))
+}
+
+func (s *flatpakStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "flatpak install", s.Plan(ActionInstall, t))
+}
+
+func (s *flatpakStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "flatpak uninstall", s.Plan(ActionUninstall, t))
+}
@@ -0,0 +1,90 @@
+package updater
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// gemStrategy handles Ruby gems that ship executables. Gems install
+// wherever the active Ruby keeps them, so gem does not claim binaries.
+type gemStrategy struct{}
+
+func init() {
+	Register(&gemStrategy{}, core.MethodGem)
+}
+
+func (s *gemStrategy) Name() string { return "gem" }
+
+func (s *gemStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	out := d.runOutput("gem", "list", "--local", "--exact", packageName(t))
+	return d.detectListed(t, parseGemList(out), SourceGemList)
+}
+
+func (s *gemStrategy) WarmUp(d *Detector) {
+	// gem outdated (runOutput returns nothing when gem is absent)
+	for gem, latest := range parseGemOutdated(d.runOutput("gem", "outdated")) {
+		d.setRemote(s.Name(), gem, remoteInfo{latest, SourceGemOutdated})
+	}
+}
+
+func (s *gemStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	switch a {
+	case ActionInstall:
+		return []PlanStep{step("gem", "install", packageName(t))}
+	case ActionUninstall:
+		// Every installed version, executables included, without prompting
+		return []PlanStep{step("gem", "uninstall", "--all", "--executables", packageName(t))}
+	}
+	return []PlanStep{step("gem", "update", packageName(t))}
+}
+
+// LockKey is per manager: gems of one Ruby share its gem directory
+func (s *gemStrategy) LockKey(t core.Tool) string { return "gem" }
+
+func (s *gemStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "gem update", s.Plan(ActionUpgrade, t))
+}
+
+func (s *gemStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "gem install", s.Plan(ActionInstall, t))
+}
+
+func (s *gemStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "gem uninstall", s.Plan(ActionUninstall, t))
+}
+
+// parseGemList handles `gem list` lines such as "rake (13.2.1, default: 13.0.6)".
+// Versions are listed newest first.
+func parseGemList(out string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		name, versions, ok := strings.Cut(strings.TrimSpace(line), " (")
+		if !ok {
+			continue
+		}
+		newest, _, _ := strings.Cut(strings.TrimSuffix(versions, ")"), ",")
+		newest = strings.TrimPrefix(strings.TrimSpace(newest), "default: ")
+		if newest, _, _ = strings.Cut(newest, " "); newest != "" {
+			result[name] = newest // Platform gems add one: "1.16.0 x86_64-linux"
+		}
+	}
+	return result
+}
+
+// parseGemOutdated handles `gem outdated` lines such as "rake (13.0.6 < 13.2.1)"
+func parseGemOutdated(out string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		name, versions, ok := strings.Cut(strings.TrimSpace(line), " (")
+		if !ok {
+			continue
+		}
+		_, latest, ok := strings.Cut(strings.TrimSuffix(versions, ")"), " < ")
+		if ok && latest != "" {
+			result[name] = latest
+		}
+	}
+	return result
+}
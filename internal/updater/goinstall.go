@@ -0,0 +1,117 @@
+package updater
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// goInstallStrategy handles binaries built with `go install <package>@latest`.
+// Package is the package path; the module providing it is read from the
+// binary's build info.
+type goInstallStrategy struct{}
+
+func init() {
+	Register(&goInstallStrategy{}, core.MethodGoInstall)
+}
+
+func (s *goInstallStrategy) Name() string { return "go" }
+
+func (s *goInstallStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	path, err := d.runner.LookPath(t.Binary)
+	if err != nil || path == "" {
+		return "MISSING", SourceNone
+	}
+	installed := make(map[string]string)
+	for pkg, info := range parseGoVersionM(d.runOutput("go", "version", "-m", path)) {
+		installed[pkg] = info.version
+	}
+	return d.detectListed(t, installed, SourceGoBuildInfo)
+}
+
+// WarmUp reads the build info of every binary in GOBIN in one go and asks
+// the module proxy about their modules
+func (s *goInstallStrategy) WarmUp(d *Detector) {
+	dir := goBinDir(d.runOutput("go", "env", "GOBIN", "GOPATH"))
+	if dir == "" {
+		return
+	}
+	modules := make(map[string]string)
+	for pkg, info := range parseGoVersionM(d.runOutput("go", "version", "-m", dir)) {
+		modules[pkg] = info.module
+	}
+	d.warmUpFromRegistry(s.Name(), core.RegistryGo, modules)
+}
+
+func (s *goInstallStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	if a == ActionUninstall {
+		return nil // go has no uninstall; the binary is a single file in GOBIN
+	}
+	return []PlanStep{step("go", "install", packageName(t)+"@latest")}
+}
+
+// LockKey is empty: the module cache supports concurrent go commands
+func (s *goInstallStrategy) LockKey(t core.Tool) string { return "" }
+
+// Owns claims ~/go/bin, the default GOBIN
+func (s *goInstallStrategy) Owns(o Owner) bool { return o == OwnerGo }
+
+func (s *goInstallStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "go install", s.Plan(ActionUpgrade, t))
+}
+
+func (s *goInstallStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "go install", s.Plan(ActionInstall, t))
+}
+
+func (s *goInstallStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return errManual("uninstall")
+}
+
+// goBuildInfo is what `go version -m` says about one binary
+type goBuildInfo struct {
+	module  string
+	version string // Without the leading v
+}
+
+// goBinDir picks where `go install` puts binaries from `go env GOBIN GOPATH`:
+// GOBIN when set, otherwise bin in the first GOPATH entry
+func goBinDir(out string) string {
+	lines := strings.Split(out, "\n")
+	if len(lines) < 2 {
+		return ""
+	}
+	if gobin := strings.TrimSpace(lines[0]); gobin != "" {
+		return gobin
+	}
+	if gopath := filepath.SplitList(strings.TrimSpace(lines[1])); len(gopath) > 0 && gopath[0] != "" {
+		return filepath.Join(gopath[0], "bin")
+	}
+	return ""
+}
+
+// parseGoVersionM handles `go version -m` on a binary or a directory,
+// keyed by package path:
+//
+//	/home/dev/go/bin/gopls: go1.22.1
+//		path	golang.org/x/tools/gopls
+//		mod	golang.org/x/tools/gopls	v0.15.2	h1:...
+//
+// Binaries built from a checkout report "(devel)" and are skipped.
+func parseGoVersionM(out string) map[string]goBuildInfo {
+	result := make(map[string]goBuildInfo)
+	var pkg string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 2 && fields[0] == "path":
+			pkg = fields[1]
+		case len(fields) >= 3 && fields[0] == "mod" && pkg != "" && fields[2] != "(devel)":
+			result[pkg] = goBuildInfo{module: fields[1], version: strings.TrimPrefix(fields[2], "v")}
+			pkg = ""
+		}
+	}
+	return result
+}
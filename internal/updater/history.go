@@ -0,0 +1,155 @@
+package updater
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bounds on the output kept per history entry; the session transcript has it all
+const (
+	historyOutputLines = 40
+	historyLineLength  = 240
+)
+
+// HistoryEntry is one update attempt, successful or not
+type HistoryEntry struct {
+	Time       time.Time `json:"time"` // When the attempt started
+	ToolID     string    `json:"tool_id"`
+	Key        string    `json:"key"`
+	Name       string    `json:"name"`
+	Method     string    `json:"method"`
+	Action     string    `json:"action"` // upgrade, install, uninstall or rollback
+	From       string    `json:"from"`
+	To         string    `json:"to,omitempty"` // Detected afterwards, empty on failure
+	DurationMS int64     `json:"duration_ms"`
+	Success    bool      `json:"success"`
+	Aborted    bool      `json:"aborted,omitempty"`
+	Message    string    `json:"message,omitempty"` // Result or error shown to the user
+	Output     string    `json:"output,omitempty"`  // Tail of the command output, see TailOutput
+}
+
+// Duration is how long the attempt ran
+func (e HistoryEntry) Duration() time.Duration {
+	return time.Duration(e.DurationMS) * time.Millisecond
+}
+
+// Outcome is the entry's result in one word
+func (e HistoryEntry) Outcome() string {
+	switch {
+	case e.Aborted:
+		return "aborted"
+	case e.Success:
+		return "success"
+	}
+	return "failed"
+}
+
+// TailOutput keeps the last lines of an update's output for its history entry
+func TailOutput(lines []OutputLine) string {
+	lines = lines[max(len(lines)-historyOutputLines, 0):]
+	kept := make([]string, len(lines))
+	for i, l := range lines {
+		text := []rune(l.Text)
+		if len(text) > historyLineLength {
+			text = append(text[:historyLineLength-1], '…')
+		}
+		kept[i] = string(text)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// History is the append-only ledger of update attempts, one JSON object per
+// line. Entries are never rewritten, so the file can be audited as is.
+type History struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewHistory returns the ledger stored at path; the file is created on first append
+func NewHistory(path string) *History {
+	return &History{path: path}
+}
+
+// Append adds e at the end of the ledger
+func (h *History) Append(e HistoryEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// HistoryFilter selects ledger entries. Zero fields match everything.
+type HistoryFilter struct {
+	Keys  []string  // Tool keys
+	Since time.Time // Inclusive
+	Until time.Time // Exclusive
+}
+
+func (f HistoryFilter) matches(e HistoryEntry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	if len(f.Keys) == 0 {
+		return true
+	}
+	for _, key := range f.Keys {
+		if e.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Read returns the entries matching f, oldest first. A missing ledger is
+// empty; lines that do not parse (a write cut short) are skipped.
+func (h *History) Read(f HistoryFilter) ([]HistoryEntry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	file, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e HistoryEntry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		if f.matches(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,80 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryAppendAndRead(t *testing.T) {
+	h := NewHistory(filepath.Join(t.TempDir(), "spark", "history.jsonl"))
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, e := range []HistoryEntry{
+		{Time: day, Key: "jq", Action: "upgrade", From: "1.6", To: "1.7.1", Success: true},
+		{Time: day.Add(24 * time.Hour), Key: "node", Action: "upgrade", From: "20.1.0", Message: "brew upgrade failed"},
+		{Time: day.Add(48 * time.Hour), Key: "jq", Action: "rollback", From: "1.7.1", To: "1.6", Success: true},
+	} {
+		if err := h.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []string // From of each entry, in order
+	}{
+		{"everything", HistoryFilter{}, []string{"1.6", "20.1.0", "1.7.1"}},
+		{"by tool", HistoryFilter{Keys: []string{"jq"}}, []string{"1.6", "1.7.1"}},
+		{"since", HistoryFilter{Since: day.Add(24 * time.Hour)}, []string{"20.1.0", "1.7.1"}},
+		{"until", HistoryFilter{Until: day.Add(24 * time.Hour)}, []string{"1.6"}},
+		{"nothing", HistoryFilter{Keys: []string{"git"}}, nil},
+	}
+	for _, tt := range tests {
+		entries, err := h.Read(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.From)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: Read() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHistorySkipsTornLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	content := `{"key":"jq","success":true}` + "\n" + `{"key":"no` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := NewHistory(path).Read(HistoryFilter{})
+	if err != nil || len(entries) != 1 || entries[0].Outcome() != "success" {
+		t.Errorf("Read() = %+v, %v; want the jq entry only", entries, err)
+	}
+
+	if entries, err := NewHistory(filepath.Join(t.TempDir(), "missing.jsonl")).Read(HistoryFilter{}); err != nil || entries != nil {
+		t.Errorf("Read(missing) = %v, %v; want empty", entries, err)
+	}
+}
+
+func TestTailOutput(t *testing.T) {
+	var lines []OutputLine
+	for i := 0; i < historyOutputLines+5; i++ {
+		lines = append(lines, OutputLine{Text: "line"})
+	}
+	lines = append(lines, OutputLine{Stream: StreamStderr, Text: strings.Repeat("x", 1000)})
+
+	got := strings.Split(TailOutput(lines), "\n")
+	if len(got) != historyOutputLines {
+		t.Errorf("kept %d lines, want %d", len(got), historyOutputLines)
+	}
+	if last := []rune(got[len(got)-1]); len(last) != historyLineLength || last[len(last)-1] != '…' {
+		t.Errorf("long line kept as %d runes, want %d ending in …", len(last), historyLineLength)
+	}
+}
@@ -0,0 +1,65 @@
+package updater
+
+import (
+	"sync"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// Support for language-level tool installers shared by pipx.go, uv.go,
+// cargo.go, goinstall.go and gem.go. Each keeps a list of what it installed;
+// the installed version comes from that list, and the latest one from the
+// installer's own outdated check where it has one, or from the package
+// registry otherwise.
+
+// Detection sources for language-level tool installers
+const (
+	SourcePipxList           = "pipx_list"            // pipx list --json
+	SourceUvToolList         = "uv_tool_list"         // uv tool list
+	SourceCargoInstallList   = "cargo_install_list"   // cargo install --list
+	SourceCargoInstallUpdate = "cargo_install_update" // cargo install-update --list (cargo-update)
+	SourceGoBuildInfo        = "go_build_info"        // go version -m <binary>
+	SourceGemList            = "gem_list"             // gem list --exact
+	SourceGemOutdated        = "gem_outdated"         // gem outdated
+)
+
+// detectListed returns the version of t in an installer's list, falling back
+// to generic detection when the tool was installed some other way
+func (d *Detector) detectListed(t core.Tool, installed map[string]string, source string) (string, string) {
+	if v, ok := installed[packageName(t)]; ok && v != "" {
+		return v, source
+	}
+	return d.getCliToolVersion(t)
+}
+
+// warmUpFromRegistry looks up every installed package in its registry
+// (kind) at once and stores the answers under namespace, so installers
+// without an outdated command still warm up in bulk. names maps each
+// package to its name in the registry. Lookups share the registry cache,
+// which keeps them to one per TTL.
+func (d *Detector) warmUpFromRegistry(namespace, kind string, names map[string]string) {
+	if d.registries == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	for pkg, name := range names {
+		wg.Add(1)
+		go func(pkg, name string) {
+			defer wg.Done()
+			if latest, source, ok := d.latestFromRegistry(kind + ":" + name); ok {
+				d.setRemote(namespace, pkg, remoteInfo{latest, source})
+			}
+		}(pkg, name)
+	}
+	wg.Wait()
+}
+
+// sameNames maps the packages of an installed list to themselves, for
+// installers whose names are the registry's
+func sameNames(installed map[string]string) map[string]string {
+	names := make(map[string]string, len(installed))
+	for pkg := range installed {
+		names[pkg] = pkg
+	}
+	return names
+}
@@ -0,0 +1,137 @@
+package updater
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestParseInstalledLists(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) map[string]string
+		in    string
+		want  map[string]string
+	}{
+		{"pipx list", parsePipxList, readFixture(t, "pipx_list.json"), map[string]string{"black": "24.1.0", "poetry": "1.8.2"}},
+		{"uv tool list", parseUvToolList, readFixture(t, "uv_tool_list.txt"), map[string]string{"batrachian-toad": "0.5.2", "ruff": "0.3.4"}},
+		{"cargo install", parseCargoInstallList, readFixture(t, "cargo_install_list.txt"), map[string]string{"cargo-update": "13.4.0", "ripgrep": "13.0.0", "zellij": "0.39.2"}},
+		{"cargo install-update", parseCargoInstallUpdate, readFixture(t, "cargo_install_update.txt"), map[string]string{"ripgrep": "14.1.0"}},
+		{"gem list", parseGemList, "rake (13.2.1, default: 13.0.6)\nnokogiri (1.16.3 x86_64-linux)\n", map[string]string{"rake": "13.2.1", "nokogiri": "1.16.3"}},
+		{"gem list default only", parseGemList, "bundler (default: 2.5.6)\n", map[string]string{"bundler": "2.5.6"}},
+		{"gem outdated", parseGemOutdated, readFixture(t, "gem_outdated.txt"), map[string]string{"bundler": "2.5.7", "rake": "13.2.1"}},
+		{"not installed", parsePipxList, "", map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.parse(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGoVersionM(t *testing.T) {
+	got := parseGoVersionM(readFixture(t, "go_version_m.txt"))
+	want := map[string]goBuildInfo{
+		"golang.org/x/tools/gopls":           {module: "golang.org/x/tools/gopls", version: "0.15.2"},
+		"honnef.co/go/tools/cmd/staticcheck": {module: "honnef.co/go/tools", version: "0.4.6"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGoVersionM() = %v, want %v", got, want)
+	}
+
+	if dir := goBinDir("\n/home/dev/go:/opt/go\n"); dir != "/home/dev/go/bin" {
+		t.Errorf("goBinDir() without GOBIN = %q, want the first GOPATH's bin", dir)
+	}
+	if dir := goBinDir("/home/dev/bin\n/home/dev/go\n"); dir != "/home/dev/bin" {
+		t.Errorf("goBinDir() = %q, want GOBIN", dir)
+	}
+}
+
+func TestLanguageInstallersWarmUp(t *testing.T) {
+	reg, _ := newRegistryStub(t, map[string]string{
+		"/pypi-mirror/pypi/black/json":              `{"info": {"version": "24.3.0"}}`,
+		"/pypi-mirror/pypi/poetry/json":             `{"info": {"version": "1.8.2"}}`,
+		"/pypi-mirror/pypi/ruff/json":               `{"info": {"version": "0.4.1"}}`,
+		"/api/v1/crates/ripgrep":                    `{"crate": {"max_stable_version": "14.1.0"}}`,
+		"/goproxy/golang.org/x/tools/gopls/@latest": `{"Version": "v0.16.0"}`,
+		"/goproxy/honnef.co/go/tools/@latest":       `{"Version": "v0.4.7"}`,
+	})
+	r := NewFakeRunner().
+		OnPath("pipx", "/usr/bin/pipx").
+		On("pipx list --json", Stdout(readFixture(t, "pipx_list.json"))).
+		OnPath("uv", "/usr/bin/uv").
+		On("uv tool list", Stdout("ruff v0.3.4\n- ruff\n")).
+		OnPath("cargo", "/home/dev/.cargo/bin/cargo").
+		On("cargo install-update --list", Failure(101, "error: no such command: `install-update`"), Stdout(readFixture(t, "cargo_install_update.txt"))).
+		On("cargo install --list", Stdout("ripgrep v13.0.0:\n    rg\n")).
+		OnPath("go", "/usr/local/go/bin/go").
+		On("go env GOBIN GOPATH", Stdout("\n/home/dev/go\n")).
+		On("go version -m /home/dev/go/bin", Stdout(readFixture(t, "go_version_m.txt"))).
+		OnPath("gem", "/usr/bin/gem").
+		On("gem outdated", Stdout(readFixture(t, "gem_outdated.txt")))
+	d := newTestDetector(t, r)
+	d.UseRegistries(reg)
+	d.WarmUpCache()
+
+	tests := []struct {
+		tool       core.Tool
+		local      string
+		wantVer    string
+		wantSource string
+	}{
+		{core.Tool{Package: "black", Method: core.MethodPipx}, "24.1.0", "24.3.0", SourcePyPI},
+		{core.Tool{Package: "ruff", Method: core.MethodUv}, "0.3.4", "0.4.1", SourcePyPI},
+		{core.Tool{Package: "ripgrep", Method: core.MethodCargo}, "13.0.0", "14.1.0", SourceCratesIO},
+		{core.Tool{Package: "honnef.co/go/tools/cmd/staticcheck", Method: core.MethodGoInstall}, "0.4.6", "0.4.7", SourceGoProxy},
+		{core.Tool{Package: "rake", Method: core.MethodGem}, "13.0.6", "13.2.1", SourceGemOutdated},
+		{core.Tool{Package: "rails", Method: core.MethodGem}, "7.1.3", "7.1.3", SourceNotOutdated},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.tool.Method), func(t *testing.T) {
+			ver, source := d.DetectRemote(tt.tool, tt.local)
+			if ver != tt.wantVer || source != tt.wantSource {
+				t.Errorf("DetectRemote() = (%q, %q), want (%q, %q)", ver, source, tt.wantVer, tt.wantSource)
+			}
+		})
+	}
+
+	// cargo-update's table is used once the subcommand is installed
+	d = newTestDetector(t, r)
+	d.WarmUpCache()
+	if ver, source := d.DetectRemote(core.Tool{Package: "ripgrep", Method: core.MethodCargo}, "13.0.0"); ver != "14.1.0" || source != SourceCargoInstallUpdate {
+		t.Errorf("DetectRemote() with cargo-update = (%q, %q), want (14.1.0, %q)", ver, source, SourceCargoInstallUpdate)
+	}
+}
+
+func TestLanguageInstallersDetectLocal(t *testing.T) {
+	r := NewFakeRunner().
+		OnPath("pipx", "/usr/bin/pipx").
+		On("pipx list --json", Stdout(readFixture(t, "pipx_list.json"))).
+		OnPath("go", "/usr/local/go/bin/go").
+		OnPath("gopls", "/home/dev/go/bin/gopls").
+		On("go version -m /home/dev/go/bin/gopls", Stdout(readFixture(t, "go_version_m.txt"))).
+		OnPath("gem", "/usr/bin/gem").
+		On("gem list --local --exact rake", Stdout("rake (13.2.1, default: 13.0.6)\n"))
+	d := newTestDetector(t, r)
+
+	tests := []struct {
+		tool       core.Tool
+		wantVer    string
+		wantSource string
+	}{
+		{core.Tool{Binary: "black", Package: "black", Method: core.MethodPipx}, "24.1.0", SourcePipxList},
+		{core.Tool{Binary: "gopls", Package: "golang.org/x/tools/gopls", Method: core.MethodGoInstall}, "0.15.2", SourceGoBuildInfo},
+		{core.Tool{Binary: "rake", Package: "rake", Method: core.MethodGem}, "13.2.1", SourceGemList},
+		{core.Tool{Binary: "httpie", Package: "httpie", Method: core.MethodPipx}, "MISSING", SourceNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool.Package, func(t *testing.T) {
+			if ver, source := d.DetectLocal(tt.tool); ver != tt.wantVer || source != tt.wantSource {
+				t.Errorf("DetectLocal() = (%q, %q), want (%q, %q)", ver, source, tt.wantVer, tt.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// Linux package manager support shared by apt.go, dnf.go and pacman.go.
+// Versions keep the distro revision (1.6-r2.1ubuntu3) so that revision-only
+// security updates still compare as outdated; only the epoch is dropped.
+
+// Detection sources for Linux package managers
+const (
+	SourceDpkg           = "dpkg"             // dpkg-query -W
+	SourceRpm            = "rpm"              // rpm -q
+	SourcePacman         = "pacman"           // pacman -Q
+	SourceAptUpgradable  = "apt_upgradable"   // apt list --upgradable
+	SourceDnfCheckUpdate = "dnf_check_update" // dnf check-update
+	SourcePacmanUpgrades = "pacman_upgrades"  // pacman -Qu
+)
+
+// detectDistroPackage returns the version from the package database, falling
+// back to generic detection when the tool was installed some other way
+func (d *Detector) detectDistroPackage(t core.Tool, version, source string) (string, string) {
+	if version == "MISSING" {
+		return d.getCliToolVersion(t)
+	}
+	return version, source
+}
+
+// normalizeDistroVersion drops the epoch and marks the package revision
+// ("1:2.34.1-1ubuntu1" -> "2.34.1-r1ubuntu1"), so that ParseVersion keeps it
+// apart from the upstream version: 1.7.1-r1 is upstream 1.7.1, not a
+// pre-release of it. Debian security suffixes use "+" (1.6-2.1+deb12u1),
+// which semver would treat as ignorable build metadata, so they become part
+// of the revision; Debian's "~" (1.0~rc1) is a pre-release.
+func normalizeDistroVersion(v string) string {
+	v = strings.TrimSpace(v)
+	if _, rest, ok := strings.Cut(v, ":"); ok {
+		v = rest
+	}
+	if i := strings.LastIndex(v, "-"); i > 0 {
+		v = v[:i] + "-r" + v[i+1:]
+	}
+	return strings.NewReplacer("+", ".", "~", "-").Replace(v)
+}
+
+// runOutput returns stdout of a command even when it exits non-zero
+// (dnf check-update uses exit code 100 to mean "updates available").
+func (d *Detector) runOutput(name string, args ...string) string {
+	if _, err := d.runner.LookPath(name); err != nil {
+		return ""
+	}
+	return d.runner.Run(context.Background(), Command{Name: name, Args: args}).Stdout
+}
+
+// runPrivileged runs a package manager command through privileged and turns
+// sudo's password prompt refusal into an actionable error
+func (e *Executor) runPrivileged(ctx context.Context, label string, args ...string) error {
+	args = privileged(args...)
+	if output, err := e.combined(ctx, args[0], args[1:]...); err != nil {
+		if strings.Contains(output, "a password is required") {
+			return fmt.Errorf("%s needs sudo: run `sudo -v` before Spark or configure passwordless sudo", label)
+		}
+		return fmt.Errorf("%s failed: %s: %v", label, output, err)
+	}
+	return nil
+}
+
+// privileged prefixes args with non-interactive sudo unless Spark already runs as root.
+// A password prompt would hang the TUI, so sudo must fail fast instead.
+func privileged(args ...string) []string {
+	if os.Geteuid() == 0 {
+		return args
+	}
+	return append([]string{"sudo", "-n"}, args...)
+}
+
+// sudoPrefix is the privileged prefix as shown in planned commands
+func sudoPrefix() string {
+	if os.Geteuid() == 0 {
+		return ""
+	}
+	return "sudo "
+}
@@ -0,0 +1,138 @@
+package updater
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAptUpgradable(t *testing.T) {
+	got := parseAptUpgradable(readFixture(t, "apt_upgradable.txt"))
+	want := map[string]string{
+		"jq":     "1.6-r2.1ubuntu3.1",
+		"libjq1": "1.6-r2.1ubuntu3.1",
+		"git":    "2.34.1-r1ubuntu1.11",
+		"curl":   "7.88.1-r10.deb12u5",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseAptUpgradable() = %v, want %v", got, want)
+	}
+}
+
+func TestParseDnfCheckUpdate(t *testing.T) {
+	got := parseDnfCheckUpdate(readFixture(t, "dnf_check_update.txt"))
+	want := map[string]string{
+		"jq":          "1.7.1-r1.fc39",
+		"git":         "2.44.0-r1.fc39",
+		"kernel-core": "6.7.9-r200.fc39",
+		"grub2-tools": "2.06-r116.fc39",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDnfCheckUpdate() = %v, want %v", got, want)
+	}
+}
+
+func TestParsePacmanUpgrades(t *testing.T) {
+	got := parsePacmanUpgrades(readFixture(t, "pacman_qu.txt"))
+	want := map[string]string{
+		"jq":    "1.7.1-r1",
+		"git":   "2.44.0-r1",
+		"linux": "6.7.9.arch1-r1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePacmanUpgrades() = %v, want %v", got, want)
+	}
+}
+
+func TestParseInstalledQueries(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) string
+		in    string
+		want  string
+	}{
+		{"dpkg installed", parseDpkgQuery, "install ok installed|1:2.34.1-1ubuntu1.10", "2.34.1-r1ubuntu1.10"},
+		{"dpkg removed", parseDpkgQuery, "deinstall ok config-files|1.6-2.1ubuntu3", "MISSING"},
+		{"dpkg unknown", parseDpkgQuery, "", "MISSING"},
+		{"rpm installed", parseRpmQuery, "1.7.1-1.fc39", "1.7.1-r1.fc39"},
+		{"rpm missing", parseRpmQuery, "package jq is not installed", "MISSING"},
+		{"pacman installed", parsePacmanQuery, "jq 1.7.1-1\n", "1.7.1-r1"},
+		{"pacman missing", parsePacmanQuery, "error: package 'jq' was not found", "MISSING"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.parse(tt.in); got != tt.want {
+				t.Errorf("parse(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistroRevisionOrdering(t *testing.T) {
+	tests := []struct {
+		local, remote string
+		want          VersionRelation
+	}{
+		{"1.6-2.1ubuntu3", "1.6-2.1ubuntu3.1", RelationOutdated},
+		{"1.6-2.1+deb12u1", "1.6-2.1+deb12u2", RelationOutdated},
+		{"2.34.1-1ubuntu3", "2.34.1-1ubuntu10", RelationOutdated},
+		{"1.7.1-1", "1.7.1-1", RelationUpToDate},
+		{"1.7.1-2", "1.7.1-1", RelationAhead},
+		{"1.0~rc1-1", "1.0-1", RelationOutdated},
+	}
+	for _, tt := range tests {
+		local, remote := normalizeDistroVersion(tt.local), normalizeDistroVersion(tt.remote)
+		if got := CompareVersions(local, remote); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %v, want %v", local, remote, got, tt.want)
+		}
+	}
+}
+
+// Registries, pins and manifests name upstream versions: the revision of a
+// distro package must not make it older or newer than the release it packages
+func TestDistroAgainstUpstream(t *testing.T) {
+	tests := []struct {
+		distro, upstream string
+		want             VersionRelation
+	}{
+		{"1.7.1-1", "1.7.1", RelationUpToDate},
+		{"1:2.0-3", "2.0", RelationUpToDate},
+		{"2.34.1-1ubuntu1.10", "v2.34.1", RelationUpToDate},
+		{"1.7.1-1.fc39", "1.7.2", RelationOutdated},
+		{"1.7.1-1", "1.7.0", RelationAhead},
+		{"1.0~rc1-1", "1.0", RelationOutdated},
+	}
+	for _, tt := range tests {
+		local := normalizeDistroVersion(tt.distro)
+		if got := CompareVersions(local, tt.upstream); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %v, want %v", local, tt.upstream, got, tt.want)
+		}
+		if got := CompareVersions(tt.upstream, local); got != inverse(tt.want) {
+			t.Errorf("CompareVersions(%q, %q) = %v, want %v", tt.upstream, local, got, inverse(tt.want))
+		}
+	}
+
+	pin, err := ParsePin("~1.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pin.Constraint.Allows(normalizeDistroVersion("1.7.1-1")) {
+		t.Error("pin ~1.7 rejects the 1.7.1-1 package")
+	}
+	req, err := ParseRequirement("1.7.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !req.Allows(normalizeDistroVersion("1.7.1-2")) {
+		t.Error("manifest requirement 1.7.1 rejects the 1.7.1-2 package")
+	}
+}
+
+func inverse(r VersionRelation) VersionRelation {
+	switch r {
+	case RelationOutdated:
+		return RelationAhead
+	case RelationAhead:
+		return RelationOutdated
+	}
+	return r
+}
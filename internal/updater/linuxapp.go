@@ -0,0 +1,336 @@
+package updater
+
+import (
+	"bufio"
+	"debug/elf"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// Linux desktop application support shared by snap.go, flatpak.go,
+// appimage.go and macapp.go. The inventory names desktop apps after their
+// macOS bundle and Homebrew cask; on Linux the same tools come from snap,
+// Flatpak, an AppImage or a distro package with a .desktop entry.
+
+// Detection sources for Linux desktop applications
+const (
+	SourceSnapList       = "snap_list"       // snap list <snap>
+	SourceSnapRefresh    = "snap_refresh"    // snap refresh --list
+	SourceFlatpakList    = "flatpak_list"    // flatpak list --app
+	SourceFlatpakUpdates = "flatpak_updates" // flatpak remote-ls --updates
+	SourceAppImage       = "appimage"        // Version in the AppImage file name
+	SourceDesktopEntry   = "desktop_entry"   // .desktop entry in an applications directory
+)
+
+// desktopApp names a desktop application in each Linux packaging format.
+// Empty fields mean the app is not published that way.
+type desktopApp struct {
+	cask     string // Package of the mac_app tool, the key its latest version is stored under
+	snap     string
+	flatpak  string // Application ID
+	appImage string // File name prefix, "Cursor" for Cursor-0.42.3-x86_64.AppImage
+	desktop  string // .desktop entry installed by distro packages
+}
+
+// desktopApps maps the binary of mac_app tools to their Linux packages,
+// like appPaths does for macOS bundles
+var desktopApps = map[string]desktopApp{
+	"ghostty":  {cask: "ghostty", snap: "ghostty", flatpak: "com.mitchellh.ghostty", desktop: "com.mitchellh.ghostty.desktop"},
+	"warp":     {cask: "warp", desktop: "dev.warp.Warp.desktop"},
+	"code":     {cask: "visual-studio-code", snap: "code", flatpak: "com.visualstudio.code", desktop: "code.desktop"},
+	"cursor":   {cask: "cursor", appImage: "Cursor", desktop: "cursor.desktop"},
+	"zed":      {cask: "zed", flatpak: "dev.zed.Zed", desktop: "dev.zed.Zed.desktop"},
+	"windsurf": {cask: "windsurf", desktop: "windsurf.desktop"},
+	"docker":   {cask: "docker", desktop: "docker-desktop.desktop"},
+}
+
+// desktopInstall is a desktop application found on Linux
+type desktopInstall struct {
+	Provenance
+	Version string
+	Source  string
+}
+
+// locateDesktopApp looks for t's application as a snap, a Flatpak, an
+// AppImage and finally a .desktop entry, in that order
+func (d *Detector) locateDesktopApp(t core.Tool) (desktopInstall, bool) {
+	app, ok := desktopApps[t.Binary]
+	if !ok {
+		return desktopInstall{}, false
+	}
+
+	if app.snap != "" {
+		if v, ok := parseSnapList(d.runOutput("snap", "list", app.snap))[app.snap]; ok {
+			path := filepath.Join("/snap/bin", app.snap)
+			return desktopInstall{Provenance{Path: path, Resolved: path, Owner: OwnerSnap}, v, SourceSnapList}, true
+		}
+	}
+	if app.flatpak != "" {
+		if v, ok := parseFlatpakColumns(d.runOutput("flatpak", "list", "--app", "--columns=application,version"))[app.flatpak]; ok {
+			return desktopInstall{d.flatpakProvenance(app.flatpak), v, SourceFlatpakList}, true
+		}
+	}
+	if app.appImage != "" {
+		if path, ok := findAppImage(app.appImage, appImageDirs(d.home)); ok {
+			return desktopInstall{Provenance{Path: path, Resolved: path, Owner: OwnerAppImage}, appImageVersion(path), SourceAppImage}, true
+		}
+	}
+	if app.desktop != "" {
+		for _, dir := range d.applicationDirs() {
+			entry := filepath.Join(dir, app.desktop)
+			exec, version, ok := parseDesktopEntry(entry)
+			if !ok {
+				continue
+			}
+			if !filepath.IsAbs(exec) {
+				if path, err := d.runner.LookPath(exec); err == nil {
+					exec = path
+				}
+			}
+			resolved, err := filepath.EvalSymlinks(exec)
+			if err != nil {
+				resolved = exec
+			}
+			p := Provenance{Path: exec, Resolved: resolved, Owner: classifyBinary(exec, resolved, d.home)}
+			if version == "" {
+				version = "Detected" // Distro packages keep the version in their database, not the entry
+			}
+			return desktopInstall{p, version, SourceDesktopEntry}, true
+		}
+	}
+	return desktopInstall{}, false
+}
+
+// flatpakProvenance points at the exported launcher of a Flatpak, system
+// or user installation, which links into the app's own directory
+func (d *Detector) flatpakProvenance(id string) Provenance {
+	for _, root := range d.flatpakRoots() {
+		path := filepath.Join(root, "exports/bin", id)
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return Provenance{Path: path, Resolved: resolved, Owner: OwnerFlatpak}
+		}
+	}
+	path := filepath.Join("/var/lib/flatpak/app", id)
+	return Provenance{Path: path, Resolved: path, Owner: OwnerFlatpak}
+}
+
+// flatpakRoots are the system and user Flatpak installations
+func (d *Detector) flatpakRoots() []string {
+	roots := []string{"/var/lib/flatpak"}
+	if d.home != "" {
+		roots = append(roots, filepath.Join(d.home, ".local/share/flatpak"))
+	}
+	return roots
+}
+
+// appImageDirs are where AppImages are usually kept
+func appImageDirs(home string) []string {
+	dirs := []string{"/opt"}
+	if home != "" {
+		dirs = append([]string{
+			filepath.Join(home, "Applications"),
+			filepath.Join(home, "AppImages"),
+			filepath.Join(home, ".local/bin"),
+		}, dirs...)
+	}
+	return dirs
+}
+
+// findAppImage returns the first AppImage in dirs whose name starts with
+// prefix, ignoring case ("cursor-0.42.3-x86_64.AppImage" matches "Cursor")
+func findAppImage(prefix string, dirs []string) (string, bool) {
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := strings.ToLower(e.Name())
+			if !e.IsDir() && strings.HasPrefix(name, strings.ToLower(prefix)) && strings.HasSuffix(name, ".appimage") {
+				return filepath.Join(dir, e.Name()), true
+			}
+		}
+	}
+	return "", false
+}
+
+// locateAppImage finds the file of an appimage tool. Package is either a
+// path, "~/" allowed, or a name prefix looked up in appImageDirs. Updates
+// usually put the new version in its file name, so a path that is gone is
+// looked up again by prefix in its own directory.
+func locateAppImage(home, pkg string) (string, bool) {
+	if !strings.Contains(pkg, "/") {
+		return findAppImage(pkg, appImageDirs(home))
+	}
+	if rest, ok := strings.CutPrefix(pkg, "~/"); ok && home != "" {
+		pkg = filepath.Join(home, rest)
+	}
+	if _, err := os.Stat(pkg); err == nil {
+		return pkg, true
+	}
+	return findAppImage(appImagePrefix(filepath.Base(pkg)), []string{filepath.Dir(pkg)})
+}
+
+// appImagePrefix is the application part of an AppImage file name:
+// "Cursor" for both Cursor-0.42.3-x86_64.AppImage and Cursor.AppImage
+func appImagePrefix(name string) string {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if i := strings.IndexAny(name, "-_"); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// appImageVersion reads the version from an AppImage file name, the first
+// field starting with a digit: "0.42.3" for Cursor-0.42.3-x86_64.AppImage,
+// "Detected" when the name carries none (Cursor.AppImage)
+func appImageVersion(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for _, field := range strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' }) {
+		if v := strings.TrimPrefix(field, "v"); v != "" && v[0] >= '0' && v[0] <= '9' {
+			return v
+		}
+	}
+	return "Detected"
+}
+
+// appImageUpdateInfo reads the update information embedded in the
+// .upd_info section of an AppImage, such as
+// "gh-releases-zsync|owner|repo|latest|App-*x86_64.AppImage.zsync"
+func appImageUpdateInfo(path string) string {
+	f, err := elf.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	section := f.Section(".upd_info")
+	if section == nil {
+		return ""
+	}
+	data, err := section.Data()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(data), "\x00"))
+}
+
+// appImageGitHubRepo returns "owner/repo" for update information pointing
+// at GitHub releases, which the github registry can ask about
+func appImageGitHubRepo(info string) (string, bool) {
+	fields := strings.Split(info, "|")
+	if len(fields) < 3 || fields[0] != "gh-releases-zsync" || fields[1] == "" || fields[2] == "" {
+		return "", false
+	}
+	return fields[1] + "/" + fields[2], true
+}
+
+// applicationDirs are where .desktop entries are installed
+func (d *Detector) applicationDirs() []string {
+	var dirs []string
+	if d.home != "" {
+		dirs = append(dirs, filepath.Join(d.home, ".local/share/applications"))
+	}
+	return append(dirs,
+		"/usr/local/share/applications",
+		"/usr/share/applications",
+		"/var/lib/flatpak/exports/share/applications",
+		"/var/lib/snapd/desktop/applications",
+	)
+}
+
+// parseDesktopEntry reads the program a .desktop entry launches and the
+// version AppImage integration tools record in it, if any
+func parseDesktopEntry(path string) (exec, version string, ok bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", false
+	}
+	defer f.Close()
+
+	inEntry := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inEntry = line == "[Desktop Entry]" // Actions have their own Exec
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !inEntry || !found {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Exec":
+			if exec == "" {
+				exec = desktopExecProgram(value)
+			}
+		case "X-AppImage-Version":
+			version = strings.TrimSpace(value)
+		}
+	}
+	return exec, version, exec != ""
+}
+
+// desktopExecProgram returns the program of an Exec line, skipping an env
+// prefix: `env FOO=1 "/opt/My App/app" %U` gives "/opt/My App/app"
+func desktopExecProgram(line string) string {
+	var fields []string
+	for rest := strings.TrimSpace(line); rest != ""; rest = strings.TrimSpace(rest) {
+		var field string
+		if rest[0] == '"' {
+			field, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			field, rest, _ = strings.Cut(rest, " ")
+		}
+		fields = append(fields, field)
+	}
+	for i, f := range fields {
+		if f == "env" || (i > 0 && fields[0] == "env" && strings.Contains(f, "=")) {
+			continue
+		}
+		return f
+	}
+	return ""
+}
+
+// parseSnapList handles the table of `snap list` and `snap refresh --list`:
+//
+//	Name  Version  Rev  Tracking       Publisher  Notes
+//	code  1.89.1   159  latest/stable  vscode✓    classic
+//
+// Anything before the header, such as "All snaps up to date.", is ignored.
+func parseSnapList(out string) map[string]string {
+	result := make(map[string]string)
+	header := false
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) > 0 && fields[0] == "Name":
+			header = true
+		case header && len(fields) >= 2:
+			result[fields[0]] = fields[1]
+		}
+	}
+	return result
+}
+
+// parseFlatpakColumns handles `flatpak list --columns=application,version`
+// and `flatpak remote-ls --updates` with the same columns, tab separated.
+// Apps that publish no version count as installed all the same.
+func parseFlatpakColumns(out string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		id, version, _ := strings.Cut(strings.TrimSpace(line), "\t")
+		if id == "" || id == "Application ID" {
+			continue
+		}
+		if version = strings.TrimSpace(version); version == "" {
+			version = "Detected"
+		}
+		result[id] = version
+	}
+	return result
+}
@@ -0,0 +1,232 @@
+package updater
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestParseLinuxAppLists(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) map[string]string
+		in    string
+		want  map[string]string
+	}{
+		{"snap refresh --list", parseSnapList, readFixture(t, "snap_refresh_list.txt"), map[string]string{"code": "1.90.0", "ghostty": "1.1.3", "firefox": "127.0-2"}},
+		{"snap list", parseSnapList, "Name  Version  Rev  Tracking       Publisher  Notes\ncode  1.89.1   159  latest/stable  vscode✓    classic\n", map[string]string{"code": "1.89.1"}},
+		{"snaps up to date", parseSnapList, "All snaps up to date.\n", map[string]string{}},
+		{"flatpak list", parseFlatpakColumns, readFixture(t, "flatpak_list.txt"), map[string]string{"dev.zed.Zed": "0.140.5", "com.spotify.Client": "1.2.31.1205.g4d59ad7c", "org.example.Unversioned": "Detected"}},
+		{"flatpak header", parseFlatpakColumns, "Application ID\tVersion\ndev.zed.Zed\t0.141.2\n", map[string]string{"dev.zed.Zed": "0.141.2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.parse(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDesktopEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cursor.desktop")
+	entry := "[Desktop Entry]\nName=Cursor\nExec=env DESKTOPINTEGRATION=1 \"/opt/My Apps/cursor\" --no-sandbox %U\nX-AppImage-Version=0.42.3\n\n[Desktop Action new-window]\nExec=/usr/bin/other --new-window\n"
+	if err := os.WriteFile(path, []byte(entry), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	exec, version, ok := parseDesktopEntry(path)
+	if !ok || exec != "/opt/My Apps/cursor" || version != "0.42.3" {
+		t.Errorf("parseDesktopEntry() = (%q, %q, %v), want the program and version of the main entry", exec, version, ok)
+	}
+	if _, _, ok := parseDesktopEntry(filepath.Join(t.TempDir(), "missing.desktop")); ok {
+		t.Error("parseDesktopEntry() of a missing file reported an entry")
+	}
+
+	for line, want := range map[string]string{
+		"code --unity-launch %F":       "code",
+		"/usr/share/zed/bin/zed %U":    "/usr/share/zed/bin/zed",
+		`env BAMF=1 "/opt/A B/app" %U`: "/opt/A B/app",
+	} {
+		if got := desktopExecProgram(line); got != want {
+			t.Errorf("desktopExecProgram(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestAppImageFiles(t *testing.T) {
+	home := t.TempDir()
+	dir := filepath.Join(home, "Applications")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "Cursor-0.42.3-x86_64.AppImage")
+	if err := os.WriteFile(file, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pkg := range []string{"cursor", file, "~/Applications/Cursor-0.42.3-x86_64.AppImage", "~/Applications/Cursor-0.41.0-x86_64.AppImage"} {
+		if got, ok := locateAppImage(home, pkg); !ok || got != file {
+			t.Errorf("locateAppImage(%q) = (%q, %v), want %s", pkg, got, ok, file)
+		}
+	}
+	if got, ok := locateAppImage(home, "Obsidian"); ok {
+		t.Errorf("locateAppImage() of a missing app = %q", got)
+	}
+
+	if v := appImageVersion(file); v != "0.42.3" {
+		t.Errorf("appImageVersion() = %q, want 0.42.3", v)
+	}
+	if v := appImageVersion("/opt/Cursor.AppImage"); v != "Detected" {
+		t.Errorf("appImageVersion() without a version = %q, want Detected", v)
+	}
+	if info := appImageUpdateInfo(file); info != "" {
+		t.Errorf("appImageUpdateInfo() of a file that is not ELF = %q", info)
+	}
+	if repo, ok := appImageGitHubRepo("gh-releases-zsync|obsidianmd|obsidian-releases|latest|Obsidian-*.AppImage.zsync"); !ok || repo != "obsidianmd/obsidian-releases" {
+		t.Errorf("appImageGitHubRepo() = (%q, %v), want obsidianmd/obsidian-releases", repo, ok)
+	}
+	if _, ok := appImageGitHubRepo("zsync|https://example.com/App-latest.AppImage.zsync"); ok {
+		t.Error("appImageGitHubRepo() accepted a plain zsync URL")
+	}
+}
+
+func TestLocateDesktopApp(t *testing.T) {
+	r := NewFakeRunner().
+		OnPath("snap", "/usr/bin/snap").
+		On("snap list code", Stdout("Name  Version  Rev  Tracking       Publisher  Notes\ncode  1.89.1   159  latest/stable  vscode✓    classic\n")).
+		On("snap list ghostty", Failure(1, "error: no matching snaps installed")).
+		OnPath("flatpak", "/usr/bin/flatpak").
+		On("flatpak list --app --columns=application,version", Stdout(readFixture(t, "flatpak_list.txt")))
+	d := newTestDetector(t, r)
+
+	appImage := filepath.Join(d.home, "Applications", "Cursor-0.42.3-x86_64.AppImage")
+	entry := filepath.Join(d.home, ".local/share/applications", "windsurf.desktop")
+	for _, dir := range []string{filepath.Dir(appImage), filepath.Dir(entry)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(appImage, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(entry, []byte("[Desktop Entry]\nExec=/opt/windsurf/windsurf %F\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		binary     string
+		wantVer    string
+		wantSource string
+		wantOwner  Owner
+	}{
+		{"code", "1.89.1", SourceSnapList, OwnerSnap},
+		{"zed", "0.140.5", SourceFlatpakList, OwnerFlatpak},
+		{"cursor", "0.42.3", SourceAppImage, OwnerAppImage},
+		{"windsurf", "Detected", SourceDesktopEntry, OwnerUnknown},
+		{"ghostty", "MISSING", SourceNone, OwnerUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.binary, func(t *testing.T) {
+			tool := core.Tool{Binary: tt.binary, Package: tt.binary, Method: core.MethodMacApp}
+			if ver, source := d.DetectLocal(tool); ver != tt.wantVer || source != tt.wantSource {
+				t.Errorf("DetectLocal() = (%q, %q), want (%q, %q)", ver, source, tt.wantVer, tt.wantSource)
+			}
+			if p := d.DetectProvenance(tool); p.Owner != tt.wantOwner {
+				t.Errorf("DetectProvenance() = %+v, want owner %q", p, tt.wantOwner)
+			}
+		})
+	}
+}
+
+func TestLinuxAppsWarmUp(t *testing.T) {
+	r := NewFakeRunner().
+		OnPath("snap", "/usr/bin/snap").
+		On("snap refresh --list", Stdout(readFixture(t, "snap_refresh_list.txt"))).
+		OnPath("flatpak", "/usr/bin/flatpak").
+		On("flatpak remote-ls --updates --app --columns=application,version", Stdout("dev.zed.Zed\t0.141.2\n"))
+	d := newTestDetector(t, r)
+	d.WarmUpCache()
+
+	tests := []struct {
+		tool       core.Tool
+		local      string
+		wantVer    string
+		wantSource string
+	}{
+		{core.Tool{Package: "firefox", Method: core.MethodSnap}, "126.0-1", "127.0-2", SourceSnapRefresh},
+		{core.Tool{Package: "visual-studio-code", Method: core.MethodMacApp}, "1.89.1", "1.90.0", SourceSnapRefresh},
+		{core.Tool{Package: "dev.zed.Zed", Method: core.MethodFlatpak}, "0.140.5", "0.141.2", SourceFlatpakUpdates},
+		{core.Tool{Package: "zed", Method: core.MethodMacApp}, "0.140.5", "0.141.2", SourceFlatpakUpdates},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool.Package, func(t *testing.T) {
+			ver, source := d.DetectRemote(tt.tool, tt.local)
+			if ver != tt.wantVer || source != tt.wantSource {
+				t.Errorf("DetectRemote() = (%q, %q), want (%q, %q)", ver, source, tt.wantVer, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestInstallSnapRetriesClassic(t *testing.T) {
+	install := sudoPrefix() + "snap install code"
+	r := NewFakeRunner().
+		On(install, Failure(1, `error: This revision of snap "code" was published using classic confinement`)).
+		On(sudoPrefix()+"snap install --classic code", Stdout("code 1.90.0 from Visual Studio Code (vscode✓) installed"))
+
+	tool := core.Tool{Binary: "code", Package: "code", Method: core.MethodSnap}
+	if err := NewExecutorWithRunner(r).Install(context.Background(), tool); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if calls := r.Calls(); len(calls) != 2 || !strings.HasSuffix(calls[1], "--classic code") {
+		t.Errorf("calls = %q, want a retry with --classic", calls)
+	}
+}
+
+func TestAppImagePlansNameFoundFiles(t *testing.T) {
+	r := NewFakeRunner()
+	e := NewExecutorWithRunner(r)
+	e.home = t.TempDir()
+	file := filepath.Join(e.home, "AppImages", "Cursor-0.42.3-x86_64.AppImage")
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cursor := core.ToolState{Tool: core.Tool{Key: "cursor", Binary: "cursor", Package: "Cursor", Method: core.MethodAppImage}}
+	if p := e.Plan(ActionUninstall, cursor); len(p.Steps) != 1 || p.Steps[0].Shell() != "rm -f "+shellQuote(file) {
+		t.Errorf("uninstall plan = %+v, want the file found in ~/AppImages removed", p)
+	}
+
+	// Nothing is planned, let alone run, for a file that is not there
+	obsidian := core.ToolState{Tool: core.Tool{Key: "obsidian", Binary: "obsidian", Package: "Obsidian", Method: core.MethodAppImage}}
+	for _, a := range []Action{ActionUpgrade, ActionUninstall} {
+		if p := e.Plan(a, obsidian); len(p.Steps) != 0 || p.Manual == "" {
+			t.Errorf("%s plan of a missing AppImage = %+v, want none", a, p)
+		}
+		if err := e.Apply(context.Background(), a, obsidian.Tool); err == nil {
+			t.Errorf("%s of a missing AppImage succeeded", a)
+		}
+	}
+	if calls := r.Calls(); len(calls) != 0 {
+		t.Errorf("calls = %q, want none for a missing AppImage", calls)
+	}
+
+	// Update information is read from the file found in the detector's home, once
+	d := newTestDetector(t, NewFakeRunner())
+	d.home = e.home
+	s := &appImageStrategy{}
+	if ref := s.Registry(d, cursor.Tool); ref != "" {
+		t.Errorf("Registry() of a file without update information = %q", ref)
+	}
+	if _, ok := d.fileRegistry[file]; !ok {
+		t.Errorf("Registry() did not remember %s", file)
+	}
+}
@@ -0,0 +1,119 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// caskCacheName namespaces Homebrew casks in the remote cache (filled by
+// brewStrategy, and by snapStrategy and flatpakStrategy for the same apps on Linux)
+const caskCacheName = "brew_cask"
+
+// macAppStrategy handles macOS .app bundles, upgraded through Homebrew casks when possible.
+// On Linux the same desktop apps are found as a snap, a Flatpak, an AppImage or
+// a .desktop entry (see linuxapp.go); upgrades are redirected to their method.
+type macAppStrategy struct{}
+
+func init() {
+	Register(&macAppStrategy{}, core.MethodMacApp)
+}
+
+func (s *macAppStrategy) Name() string { return caskCacheName }
+
+func (s *macAppStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	if v := d.getMacAppVersion(t.Binary); v != "MISSING" {
+		return v, SourceAppBundle
+	}
+	if app, ok := d.locateDesktopApp(t); ok {
+		return app.Version, app.Source
+	}
+	return "MISSING", SourceNone
+}
+
+// WarmUp is a no-op: `brew outdated --json=v2` already lists casks, and
+// snap and Flatpak warm-ups list the Linux packages of the same apps
+func (s *macAppStrategy) WarmUp(d *Detector) {}
+
+func (s *macAppStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	switch a {
+	case ActionInstall:
+		return []PlanStep{step("brew", "install", "--cask", t.Package)}
+	case ActionUninstall:
+		return []PlanStep{step("brew", "uninstall", "--cask", t.Package)}
+	}
+	// Only apps installed as a cask can be upgraded, others are manual
+	isCask := step("brew", "list", "--cask", t.Package)
+	isCask.Probe = true
+	isCask.Note = "not a brew cask: manual update"
+	return []PlanStep{isCask, step("brew", "upgrade", "--cask", t.Package)}
+}
+
+// LockKey is shared with brewStrategy: casks and formulae use the same Homebrew lock
+func (s *macAppStrategy) LockKey(t core.Tool) string { return "brew" }
+
+// Owns claims casks only, so Linux packages of the app are redirected to their method
+func (s *macAppStrategy) Owns(o Owner) bool { return o == OwnerBrew }
+
+// Locate finds the Linux package of apps that are not on PATH
+func (s *macAppStrategy) Locate(d *Detector, t core.Tool) (Provenance, bool) {
+	app, ok := d.locateDesktopApp(t)
+	return app.Provenance, ok
+}
+
+func (s *macAppStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	// Try upgrading via brew cask first
+	// We assume if it's a MacApp it might be managed by brew cask
+	// Check if it is a cask
+	plan := s.Plan(ActionUpgrade, t)
+	if _, err := e.stepOutput(ctx, plan[0]); err == nil {
+		return e.runStep(ctx, "brew cask upgrade", plan[1])
+	}
+
+	// If not a cask, we can't auto-update it easily
+	return fmt.Errorf("manual update required (not a brew cask)")
+}
+
+func (s *macAppStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "brew cask install", s.Plan(ActionInstall, t))
+}
+
+func (s *macAppStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "brew cask uninstall", s.Plan(ActionUninstall, t))
+}
+
+// getMacAppVersion detects version of macOS .app bundles
+func (d *Detector) getMacAppVersion(binary string) string {
+	appPaths := map[string]string{
+		"iterm":    "/Applications/iTerm.app",
+		"ghostty":  "/Applications/Ghostty.app",
+		"warp":     "/Applications/Warp.app",
+		"code":     "/Applications/Visual Studio Code.app",
+		"cursor":   "/Applications/Cursor.app",
+		"zed":      "/Applications/Zed.app",
+		"windsurf": "/Applications/Windsurf.app",
+		"docker":   "/Applications/Docker.app",
+	}
+
+	appPath, ok := appPaths[binary]
+	if !ok {
+		return "MISSING"
+	}
+
+	return d.getAppVersion(appPath)
+}
+
+func (d *Detector) getAppVersion(appPath string) string {
+	plistPath := appPath + "/Contents/Info.plist"
+	if _, err := os.Stat(plistPath); os.IsNotExist(err) {
+		return "MISSING"
+	}
+	out, err := d.output("defaults", "read", plistPath, "CFBundleShortVersionString")
+	if err != nil {
+		return "Detected"
+	}
+	return strings.TrimSpace(out)
+}
@@ -0,0 +1,269 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// ManifestFile is the default name of a team manifest, looked up in the working directory
+const ManifestFile = "spark.lock"
+
+// manifestSchemaVersion is the only manifest layout Spark reads
+const manifestSchemaVersion = 1
+
+// ErrNoManifest is returned by LoadManifest when the file does not exist
+var ErrNoManifest = errors.New("no manifest")
+
+// Requirement is what a manifest asks of one tool:
+//
+//	"*"         installed, any version
+//	"1.7.1"     a Constraint, here 1.7.1 or any 1.7.1.x
+//	"abc123f"   anything that is not a version (git hashes) must match as written
+type Requirement struct {
+	Spec       string
+	Any        bool
+	Exact      string
+	Constraint Constraint
+}
+
+// ParseRequirement parses a manifest entry
+func ParseRequirement(spec string) (Requirement, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "":
+		return Requirement{}, fmt.Errorf("empty requirement")
+	case "*":
+		return Requirement{Spec: spec, Any: true}, nil
+	}
+
+	c, err := ParseConstraint(spec)
+	if err == nil {
+		return Requirement{Spec: spec, Constraint: c}, nil
+	}
+	if strings.ContainsAny(spec, "<>=~^, ") {
+		return Requirement{}, err // Meant as a constraint
+	}
+	return Requirement{Spec: spec, Exact: spec}, nil
+}
+
+// Allows reports whether an installed version meets the requirement
+func (r Requirement) Allows(version string) bool {
+	switch {
+	case !IsKnownVersion(version):
+		return false
+	case r.Any:
+		return true
+	case r.Exact != "":
+		return version == r.Exact
+	}
+	return r.Constraint.Allows(version)
+}
+
+// Manifest lists the tools a team expects on every machine
+type Manifest struct {
+	Path  string
+	Tools map[string]Requirement // Tool key -> requirement
+}
+
+// LoadManifest reads a manifest. Entries are keyed by tool key or ID and must
+// name a tool of the inventory.
+func LoadManifest(path string, tools []core.Tool) (Manifest, error) {
+	var doc struct {
+		Version int               `toml:"version"`
+		Tools   map[string]string `toml:"tools"`
+	}
+	md, err := toml.DecodeFile(path, &doc)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Manifest{}, fmt.Errorf("%s: %w", path, ErrNoManifest)
+	}
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return Manifest{}, fmt.Errorf("%s: unknown key %q (expected version and [tools])", path, undecoded[0].String())
+	}
+	if doc.Version != manifestSchemaVersion {
+		return Manifest{}, fmt.Errorf("%s: unsupported manifest version %d (want %d)", path, doc.Version, manifestSchemaVersion)
+	}
+
+	reqs, err := parseRequirements(doc.Tools, tools)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return Manifest{Path: path, Tools: reqs}, nil
+}
+
+// parseRequirements parses a [tools] table, keyed by tool key or ID, into
+// requirements keyed by tool key
+func parseRequirements(specs map[string]string, tools []core.Tool) (map[string]Requirement, error) {
+	keys := make([]string, 0, len(specs))
+	for name := range specs {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys) // Report the first bad entry deterministically
+
+	reqs := make(map[string]Requirement, len(specs))
+	for _, name := range keys {
+		t, ok := findTool(tools, name)
+		if !ok {
+			return nil, fmt.Errorf("tools.%s: no tool with that key or ID", name)
+		}
+		if _, dup := reqs[t.Key]; dup {
+			return nil, fmt.Errorf("tools.%s: %s is listed twice", name, t.Name)
+		}
+		req, err := ParseRequirement(specs[name])
+		if err != nil {
+			return nil, fmt.Errorf("tools.%s: %w", name, err)
+		}
+		reqs[t.Key] = req
+	}
+	return reqs, nil
+}
+
+func findTool(tools []core.Tool, name string) (core.Tool, bool) {
+	for _, t := range tools {
+		if t.Key == name || strings.EqualFold(t.ID, name) {
+			return t, true
+		}
+	}
+	return core.Tool{}, false
+}
+
+// Lists reports whether the manifest has a requirement for t
+func (m Manifest) Lists(t core.Tool) bool {
+	_, ok := m.Tools[t.Key]
+	return ok
+}
+
+// SyncStep is what bringing one tool in line with a manifest takes
+type SyncStep int
+
+const (
+	SyncOK        SyncStep = iota // Already meets its requirement
+	SyncInstall                   // Missing, the install command gets a matching version
+	SyncUpgrade                   // Too old, the latest release matches
+	SyncDowngrade                 // Too new; Spark only moves forward, so this is manual
+	SyncManual                    // No command reaches a matching version
+)
+
+func (s SyncStep) String() string {
+	switch s {
+	case SyncInstall:
+		return "install"
+	case SyncUpgrade:
+		return "upgrade"
+	case SyncDowngrade:
+		return "downgrade"
+	case SyncManual:
+		return "manual"
+	default:
+		return "ok"
+	}
+}
+
+// Drift is how one tool deviates from the manifest
+type Drift struct {
+	Tool   core.Tool
+	Spec   string // The requirement as written
+	Local  string
+	Remote string
+	Step   SyncStep
+	Reason string // Why manual work is needed, "" otherwise
+}
+
+// Check compares a detected tool with its requirement; t must be listed
+func (m Manifest) Check(s core.ToolState) Drift {
+	req := m.Tools[s.Tool.Key]
+	d := Drift{Tool: s.Tool, Spec: req.Spec, Local: s.LocalVersion, Remote: s.RemoteVersion}
+	latestOK := !IsKnownVersion(s.RemoteVersion) || req.Any || req.Allows(s.RemoteVersion)
+
+	switch {
+	case s.LocalVersion == "MISSING":
+		switch {
+		case !CanInstall(s.Tool):
+			d.Step, d.Reason = SyncManual, "no install command, install it by hand"
+		case !latestOK:
+			d.Step, d.Reason = SyncManual, "latest release "+s.RemoteVersion+" does not match, install "+req.Spec+" by hand"
+		default:
+			d.Step = SyncInstall
+		}
+	case req.Allows(s.LocalVersion):
+		d.Step = SyncOK
+	case !IsKnownVersion(s.LocalVersion) || req.Exact != "":
+		d.Step, d.Reason = SyncManual, "installed version cannot be compared"
+	default:
+		switch req.Constraint.Side(s.LocalVersion) {
+		case -1:
+			if IsKnownVersion(s.RemoteVersion) && req.Allows(s.RemoteVersion) {
+				d.Step = SyncUpgrade
+			} else {
+				d.Step, d.Reason = SyncManual, "latest release "+s.RemoteVersion+" does not match, install "+req.Spec+" by hand"
+			}
+		case 1:
+			d.Step, d.Reason = SyncDowngrade, "newer than the manifest, install "+req.Spec+" by hand"
+		default:
+			d.Step, d.Reason = SyncManual, "installed version cannot be compared"
+		}
+	}
+	return d
+}
+
+// Plan checks every listed tool among states, keeping their order
+func (m Manifest) Plan(states []core.ToolState) []Drift {
+	var plan []Drift
+	for _, s := range states {
+		if m.Lists(s.Tool) {
+			plan = append(plan, m.Check(s))
+		}
+	}
+	return plan
+}
+
+// bareKey matches the TOML keys that need no quotes
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// WriteManifest writes a manifest requiring the installed version of every
+// installed tool in states; versions Spark cannot read are written as "*"
+func WriteManifest(w io.Writer, states []core.ToolState) error {
+	type line struct{ entry, comment string }
+	var lines []line
+	width := 0
+	for _, s := range states {
+		if s.LocalVersion == "MISSING" {
+			continue
+		}
+		version := s.LocalVersion
+		if !IsKnownVersion(version) || version == "Installed" {
+			version = "*"
+		}
+		key := s.Tool.Key
+		if !bareKey.MatchString(key) {
+			key = fmt.Sprintf("%q", key)
+		}
+		l := line{
+			entry:   fmt.Sprintf("%s = %q", key, version),
+			comment: fmt.Sprintf("# %s %s (%s)", s.Tool.ID, s.Tool.Name, s.Tool.Method),
+		}
+		width = max(width, len(l.entry))
+		lines = append(lines, l)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Tool manifest written by `spark export`. Check a machine against it with\n")
+	b.WriteString("# `spark sync --manifest spark.lock`. Values are exact versions or constraints\n")
+	b.WriteString("# such as \"~1.5\" or \">=20, <22\"; \"*\" accepts any installed version.\n")
+	fmt.Fprintf(&b, "version = %d\n\n[tools]\n", manifestSchemaVersion)
+	for _, l := range lines {
+		fmt.Fprintf(&b, "%-*s  %s\n", width, l.entry, l.comment)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,139 @@
+package updater
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestParseRequirement(t *testing.T) {
+	tests := []struct {
+		spec, version string
+		want          bool
+	}{
+		{"*", "1.2.3", true},
+		{"*", "MISSING", false},
+		{"1.7.1", "1.7.1", true},
+		{"1.7.1", "1.7.2", false},
+		{"~1.5", "1.5.9", true},
+		{">=20, <22", "22.0.0", false},
+		{"abc123f", "abc123f", true},
+		{"abc123f", "def4567", false},
+	}
+	for _, tt := range tests {
+		req, err := ParseRequirement(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseRequirement(%q) error = %v", tt.spec, err)
+		}
+		if got := req.Allows(tt.version); got != tt.want {
+			t.Errorf("%q.Allows(%q) = %v, want %v", tt.spec, tt.version, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"", ">=", "~abc123f", "latest please"} {
+		if _, err := ParseRequirement(spec); err == nil {
+			t.Errorf("ParseRequirement(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestManifestCheck(t *testing.T) {
+	tool := core.Tool{Key: "jq", Name: "jq", Method: core.MethodBrewPkg, Package: "jq"}
+	manual := core.Tool{Key: "app", Name: "App", Method: core.MethodManual}
+
+	tests := []struct {
+		spec          string
+		tool          core.Tool
+		local, remote string
+		want          SyncStep
+	}{
+		{"1.7", tool, "1.7.1", "1.7.1", SyncOK},
+		{"*", tool, "1.6", "1.7.1", SyncOK},
+		{"*", tool, "MISSING", "1.7.1", SyncInstall},
+		{"1.7", tool, "MISSING", "1.8.0", SyncManual},
+		{"*", manual, "MISSING", "2.0", SyncManual},
+		{"~1.7", tool, "1.6", "1.7.1", SyncUpgrade},
+		{"~1.7", tool, "1.6", "1.8.0", SyncManual},
+		{"1.6", tool, "1.7.1", "1.7.1", SyncDowngrade},
+		{"abc123f", tool, "def4567", "def4567", SyncManual},
+		{"~1.7", tool, "Unknown", "1.7.1", SyncManual},
+	}
+	for _, tt := range tests {
+		req, err := ParseRequirement(tt.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := Manifest{Tools: map[string]Requirement{tt.tool.Key: req}}
+		d := m.Check(core.ToolState{Tool: tt.tool, LocalVersion: tt.local, RemoteVersion: tt.remote})
+		if d.Step != tt.want {
+			t.Errorf("%q with %s %s (latest %s): step = %v, want %v", tt.spec, tt.tool.Key, tt.local, tt.remote, d.Step, tt.want)
+		}
+		if (d.Step == SyncManual || d.Step == SyncDowngrade) != (d.Reason != "") {
+			t.Errorf("%q with %s %s: reason = %q for step %v", tt.spec, tt.tool.Key, tt.local, d.Reason, d.Step)
+		}
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	tools := []core.Tool{
+		{ID: "U-01", Key: "jq", Name: "jq", Method: core.MethodBrewPkg},
+		{ID: "U-02", Key: "tldr", Name: "tldr", Method: core.MethodNpmPkg},
+		{ID: "S-01", Key: "omz", Name: "Oh My Zsh", Method: core.MethodOmz},
+	}
+	states := []core.ToolState{
+		{Tool: tools[0], LocalVersion: "1.7.1"},
+		{Tool: tools[1], LocalVersion: "MISSING"},
+		{Tool: tools[2], LocalVersion: "Installed"},
+	}
+
+	path := filepath.Join(t.TempDir(), ManifestFile)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteManifest(f, states); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	m, err := LoadManifest(path, tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Tools) != 2 || m.Tools["jq"].Spec != "1.7.1" || !m.Tools["omz"].Any {
+		t.Errorf("LoadManifest() = %+v, want jq 1.7.1 and omz *", m.Tools)
+	}
+	if m.Lists(tools[1]) {
+		t.Error("missing tool was exported")
+	}
+}
+
+func TestLoadManifestErrors(t *testing.T) {
+	tools := []core.Tool{{ID: "U-01", Key: "jq"}}
+
+	if _, err := LoadManifest(filepath.Join(t.TempDir(), "absent.lock"), tools); !errors.Is(err, ErrNoManifest) {
+		t.Errorf("missing file: error = %v, want ErrNoManifest", err)
+	}
+
+	for _, tt := range []struct {
+		content, wantErr string
+	}{
+		{"version = 2\n", "unsupported manifest version 2"},
+		{"version = 1\n[tools]\njqq = \"1.7\"\n", "tools.jqq: no tool"},
+		{"version = 1\n[tools]\njq = \">=\"\n", "tools.jq:"},
+		{"version = 1\n[tools]\njq = \"1.7\"\nu-01 = \"1.7\"\n", "listed twice"},
+		{"version = 1\n[pins]\njq = \"1.7\"\n", "unknown key"},
+	} {
+		path := filepath.Join(t.TempDir(), ManifestFile)
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadManifest(path, tools); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("LoadManifest(%q) error = %v, want %q", tt.content, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package updater
+
+import (
+	"context"
+	"os"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// manualStrategy handles tools Spark can detect but never modifies
+type manualStrategy struct{}
+
+func init() {
+	Register(&manualStrategy{}, core.MethodManual)
+}
+
+func (s *manualStrategy) Name() string { return "manual" }
+
+func (s *manualStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	// Special handling for Antigravity (multiple paths)
+	if t.Binary == "antigravity" {
+		return d.getAntigravityVersion(), SourcePath
+	}
+	return d.getCliToolVersion(t)
+}
+
+// WarmUp is a no-op: there is no package manager to ask
+func (s *manualStrategy) WarmUp(d *Detector) {}
+
+func (s *manualStrategy) Plan(a Action, t core.Tool) []PlanStep { return nil }
+
+func (s *manualStrategy) LockKey(t core.Tool) string { return "" }
+
+func (s *manualStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return errManual("update")
+}
+
+func (s *manualStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return errManual("install")
+}
+
+func (s *manualStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return errManual("uninstall")
+}
+
+// getAntigravityVersion checks multiple possible installation paths
+func (d *Detector) getAntigravityVersion() string {
+	customPath := d.home + "/.antigravity/antigravity/bin/antigravity"
+	if _, err := os.Stat(customPath); err == nil {
+		output := d.runCmd(customPath, "--version")
+		return ParseToolSpecificVersion("antigravity", output)
+	}
+	output := d.runCmd("antigravity", "--version")
+	return ParseToolSpecificVersion("antigravity", output)
+}
@@ -0,0 +1,109 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// npmStrategy handles global npm packages (npm_pkg, npm_sys, claude)
+type npmStrategy struct{}
+
+func init() {
+	Register(&npmStrategy{}, core.MethodNpmPkg, core.MethodNpmSys, core.MethodClaude)
+}
+
+func (s *npmStrategy) Name() string { return "npm" }
+
+func (s *npmStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	return d.getCliToolVersion(t)
+}
+
+type npmOutdatedItem struct {
+	Current  string `json:"current"`
+	Wanted   string `json:"wanted"`
+	Latest   string `json:"latest"`
+	Location string `json:"location"`
+}
+
+func (s *npmStrategy) WarmUp(d *Detector) {
+	// npm outdated -g --json
+	// Ignore errors, npm outdated exits 1 when anything is outdated
+	out, _ := d.output("npm", "outdated", "-g", "--json")
+
+	var data map[string]npmOutdatedItem
+	if err := json.Unmarshal([]byte(out), &data); err == nil {
+		for pkg, info := range data {
+			d.setRemote(s.Name(), pkg, remoteInfo{info.Latest, SourceNpmOutdated})
+		}
+	}
+}
+
+func (s *npmStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	switch a {
+	case ActionInstall:
+		return []PlanStep{step("npm", "install", "-g", packageName(t))}
+	case ActionUninstall:
+		return []PlanStep{step("npm", "uninstall", "-g", packageName(t))}
+	}
+	// npm install -g <package>@latest
+	pkg := packageName(t) + "@latest"
+	force := step("npm", "install", "-g", pkg, "--force")
+	force.RetryOn = "EEXIST"
+	force.Note = "broken symlinks or files left by another install"
+	return []PlanStep{step("npm", "install", "-g", pkg), force}
+}
+
+func (s *npmStrategy) LockKey(t core.Tool) string { return "npm" }
+
+// Owns claims binaries of the active global prefix, nvm's included
+func (s *npmStrategy) Owns(o Owner) bool { return o == OwnerNpm }
+
+func (s *npmStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	plan := s.Plan(ActionUpgrade, t)
+	install, force := plan[0], plan[1]
+
+	output, err := e.stepOutput(ctx, install)
+	if err != nil {
+		// Auto-recovery for EEXIST (broken symlinks or permissions)
+		if strings.Contains(output, force.RetryOn) {
+			// Retry with --force
+			if outputForce, errForce := e.stepOutput(ctx, force); errForce != nil {
+				return fmt.Errorf("npm install failed (even with --force): %s: %v", outputForce, errForce)
+			}
+			return nil // Success with force
+		}
+		return fmt.Errorf("npm install failed: %s: %v", output, err)
+	}
+	return nil
+}
+
+func (s *npmStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "npm install", s.Plan(ActionInstall, t))
+}
+
+func (s *npmStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "npm uninstall", s.Plan(ActionUninstall, t))
+}
+
+// RollbackSteps reinstalls the version npm has installed now
+func (s *npmStrategy) RollbackSteps(ctx context.Context, e *Executor, t core.Tool) (string, []RollbackStep, error) {
+	// npm ls -g <package> --depth=0 --json
+	// Ignore errors, npm ls exits 1 on unrelated problems in the global tree
+	pkg := packageName(t)
+	out, _ := e.combined(ctx, "npm", "ls", "-g", pkg, "--depth=0", "--json")
+
+	var data struct {
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal([]byte(out), &data); err != nil || data.Dependencies[pkg].Version == "" {
+		return "", nil, fmt.Errorf("npm ls found no installed %s", pkg)
+	}
+	version := data.Dependencies[pkg].Version
+	return version, []RollbackStep{{Args: []string{"npm", "install", "-g", pkg + "@" + version}}}, nil
+}
@@ -0,0 +1,98 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// omzStrategy handles Oh My Zsh, a git checkout with its own upgrade script
+type omzStrategy struct{}
+
+func init() {
+	Register(&omzStrategy{}, core.MethodOmz)
+}
+
+func (s *omzStrategy) Name() string { return "omz" }
+
+func (s *omzStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	return d.getOmzVersion(), SourceGit
+}
+
+// WarmUp is a no-op: Oh My Zsh has no release feed
+func (s *omzStrategy) WarmUp(d *Detector) {}
+
+// Resolve replaces Package with the checkout, see omzDir
+func (s *omzStrategy) Resolve(home string, t core.Tool) (core.Tool, bool) {
+	dir := omzDir(home)
+	if _, err := os.Stat(dir); err != nil {
+		return t, false
+	}
+	t.Package = dir
+	return t, true
+}
+
+// Plan only covers upgrades of the checkout Resolve found: the official
+// installer and uninstaller rewrite ~/.zshrc. upgrade.sh is run directly,
+// its shebang picks the shell, with ZSH set so it updates that checkout.
+func (s *omzStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	dir := packageName(t)
+	if a != ActionUpgrade || !filepath.IsAbs(dir) {
+		return nil
+	}
+	upgrade := step(filepath.Join(dir, "tools", "upgrade.sh"))
+	upgrade.Env = []string{"ZSH=" + dir}
+	return []PlanStep{upgrade}
+}
+
+func (s *omzStrategy) LockKey(t core.Tool) string { return "omz" }
+
+func (s *omzStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "omz upgrade", s.Plan(ActionUpgrade, t))
+}
+
+func (s *omzStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return errManual("install")
+}
+
+func (s *omzStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return errManual("uninstall")
+}
+
+// getOmzVersion gets Oh My Zsh git commit hash
+func (d *Detector) getOmzVersion() string {
+	omzPath := omzDir(d.home)
+	if _, err := os.Stat(omzPath); err != nil {
+		return "MISSING"
+	}
+
+	out, err := d.output("git", "--git-dir="+omzPath+"/.git", "--work-tree="+omzPath, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "Installed"
+	}
+	return strings.TrimSpace(out)
+}
+
+// omzDir is the Oh My Zsh checkout: $ZSH when set, else ~/.oh-my-zsh in home
+func omzDir(home string) string {
+	if dir := os.Getenv("ZSH"); dir != "" {
+		return dir
+	}
+	return filepath.Join(home, ".oh-my-zsh")
+}
+
+// RollbackSteps moves the checkout back to the current commit. reset --keep
+// stays on the branch, so the next upgrade.sh can pull again.
+func (s *omzStrategy) RollbackSteps(ctx context.Context, e *Executor, t core.Tool) (string, []RollbackStep, error) {
+	dir := omzDir(e.home)
+	out, err := e.combined(ctx, "git", "-C", dir, "rev-parse", "--short", "HEAD")
+	hash := strings.TrimSpace(out)
+	if err != nil || hash == "" {
+		return "", nil, fmt.Errorf("git rev-parse failed: %s: %v", out, err)
+	}
+	return hash, []RollbackStep{{Args: []string{"git", "-C", dir, "reset", "--keep", hash}}}, nil
+}
@@ -0,0 +1,102 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// pacmanStrategy handles Arch Linux packages
+type pacmanStrategy struct{}
+
+func init() {
+	Register(&pacmanStrategy{}, core.MethodPacman)
+}
+
+func (s *pacmanStrategy) Name() string { return "pacman" }
+
+func (s *pacmanStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	out := d.runOutput("pacman", "-Q", t.Package)
+	return d.detectDistroPackage(t, parsePacmanQuery(out), SourcePacman)
+}
+
+func (s *pacmanStrategy) WarmUp(d *Detector) {
+	// pacman -Qu lists packages with a newer version in the sync database
+	out := d.runOutput("pacman", "-Qu")
+	for pkg, latest := range parsePacmanUpgrades(out) {
+		d.setRemote(s.Name(), pkg, remoteInfo{latest, SourcePacmanUpgrades})
+	}
+}
+
+func (s *pacmanStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	switch a {
+	case ActionInstall:
+		return []PlanStep{privilegedStep("pacman", "-S", "--noconfirm", t.Package)}
+	case ActionUninstall:
+		return []PlanStep{privilegedStep("pacman", "-R", "--noconfirm", t.Package)}
+	}
+	return []PlanStep{privilegedStep("pacman", "-S", "--noconfirm", "--needed", t.Package)}
+}
+
+// LockKey is per manager: pacman holds /var/lib/pacman/db.lck
+func (s *pacmanStrategy) LockKey(t core.Tool) string { return "pacman" }
+
+// Owns claims the system directories the package database installs into
+func (s *pacmanStrategy) Owns(o Owner) bool { return o == OwnerSystem }
+
+func (s *pacmanStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "pacman upgrade", s.Plan(ActionUpgrade, t))
+}
+
+func (s *pacmanStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "pacman install", s.Plan(ActionInstall, t))
+}
+
+func (s *pacmanStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "pacman remove", s.Plan(ActionUninstall, t))
+}
+
+// parsePacmanQuery handles `pacman -Q <pkg>` ("jq 1.7.1-1")
+func parsePacmanQuery(out string) string {
+	fields := strings.Fields(out)
+	if len(fields) < 2 || strings.HasPrefix(out, "error:") {
+		return "MISSING"
+	}
+	return normalizeDistroVersion(fields[1])
+}
+
+// parsePacmanUpgrades handles lines like:
+// jq 1.7-1 -> 1.7.1-1
+func parsePacmanUpgrades(out string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[2] != "->" {
+			continue
+		}
+		result[fields[0]] = normalizeDistroVersion(fields[3])
+	}
+	return result
+}
+
+// pacmanCache is where pacman keeps the packages it downloaded
+const pacmanCache = "/var/cache/pacman/pkg"
+
+// RollbackSteps reinstalls the installed version from pacman's package
+// cache; Arch repositories only carry the latest one
+func (s *pacmanStrategy) RollbackSteps(ctx context.Context, e *Executor, t core.Tool) (string, []RollbackStep, error) {
+	out, err := e.combined(ctx, "pacman", "-Q", t.Package)
+	fields := strings.Fields(out)
+	if err != nil || len(fields) < 2 {
+		return "", nil, fmt.Errorf("pacman found no installed %s", t.Package)
+	}
+	version := fields[1]
+	// Skip the detached signatures stored next to the packages
+	find := fmt.Sprintf("ls %s/%s-%s-*.pkg.tar.* | grep -v '\\.sig$' | head -n 1", pacmanCache, t.Package, version)
+	return version, []RollbackStep{{
+		Args:       []string{"sh", "-c", `pacman -U --noconfirm "$(` + find + `)"`},
+		Privileged: true,
+	}}, nil
+}
@@ -0,0 +1,258 @@
+package updater
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// PinHold is the pin spec that keeps a tool at whatever version is installed
+const PinHold = "hold"
+
+// Constraint is the range of versions a pinned tool may move to. Clauses are
+// separated by commas or spaces and must all match:
+//
+//	16          any 16.x (a bare or "=" version matches every version it prefixes)
+//	=1.5.7      exactly 1.5.7
+//	~1.5.2      >=1.5.2, <1.6
+//	^1.5        >=1.5, <2 (^0.3 means <0.4)
+//	>=1.5, <2   explicit bounds with >, >=, <, <=
+type Constraint struct {
+	clauses []clause
+}
+
+// clause compares a version against v with op ("=", ">", ">=", "<", "<=")
+type clause struct {
+	op string
+	v  Version
+}
+
+// ParseConstraint parses a constraint such as "~1.5" or ">=1.5, <2"
+func ParseConstraint(s string) (Constraint, error) {
+	var c Constraint
+	fields := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	if len(fields) == 0 {
+		return c, fmt.Errorf("empty version constraint")
+	}
+
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if strings.Trim(field, "<>=~^") == "" && i+1 < len(fields) {
+			// Operator written apart from its version: ">= 1.5"
+			i++
+			field += fields[i]
+		}
+		clauses, err := parseClause(field)
+		if err != nil {
+			return c, err
+		}
+		c.clauses = append(c.clauses, clauses...)
+	}
+	return c, nil
+}
+
+// parseClause expands one constraint term into its comparisons
+func parseClause(term string) ([]clause, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+	raw := strings.TrimPrefix(term, op)
+
+	v, ok := ParseVersion(raw)
+	if !ok || (v.Kind != KindNumeric && v.Kind != KindCalendar) {
+		return nil, fmt.Errorf("%q is not a version", raw)
+	}
+
+	switch op {
+	case "", "=":
+		return []clause{{"=", v}}, nil
+	case "~":
+		// Bump the minor version, or the major one when only it is given
+		return []clause{{">=", v}, {"<", bump(v, min(1, len(v.Segments)-1))}}, nil
+	case "^":
+		// Bump the first non-zero segment
+		pos := 0
+		for pos < len(v.Segments)-1 && v.Segments[pos] == 0 {
+			pos++
+		}
+		return []clause{{">=", v}, {"<", bump(v, pos)}}, nil
+	}
+	return []clause{{op, v}}, nil
+}
+
+// bump returns the lowest version above every version that shares v's first pos+1 segments
+func bump(v Version, pos int) Version {
+	segments := append([]int(nil), v.Segments[:pos+1]...)
+	segments[pos]++
+	parts := make([]string, len(segments))
+	for i, s := range segments {
+		parts[i] = fmt.Sprint(s)
+	}
+	return Version{Raw: strings.Join(parts, "."), Kind: v.Kind, Segments: segments}
+}
+
+// Allows reports whether version satisfies every clause
+func (c Constraint) Allows(version string) bool {
+	v, ok := ParseVersion(version)
+	if !ok {
+		return false
+	}
+	for _, cl := range c.clauses {
+		if !cl.allows(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Side tells where version falls when the constraint rejects it: -1 below
+// the allowed range, 1 above it, 0 when it is allowed or not comparable
+func (c Constraint) Side(version string) int {
+	v, ok := ParseVersion(version)
+	if !ok {
+		return 0
+	}
+	for _, cl := range c.clauses {
+		if cl.allows(v) {
+			continue
+		}
+		cmp, ok := v.Compare(cl.v)
+		if !ok {
+			return 0
+		}
+		if cmp < 0 {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+func (cl clause) allows(v Version) bool {
+	if cl.op == "=" && len(cl.v.PreRelease) == 0 {
+		// A bare version matches on the segments it spells out: 16 allows 16.4.1
+		if v.Kind != cl.v.Kind || len(v.Segments) < len(cl.v.Segments) {
+			return false
+		}
+		for i, s := range cl.v.Segments {
+			if v.Segments[i] != s {
+				return false
+			}
+		}
+		return true
+	}
+
+	c, ok := v.Compare(cl.v)
+	if !ok {
+		return false
+	}
+	switch cl.op {
+	case "=":
+		return c == 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+// Pin holds a tool back from updates. A hold pin never offers an update;
+// otherwise an update is offered only when the latest release satisfies the
+// constraint, since that is the version the package manager would install.
+type Pin struct {
+	Spec       string // As written in config.toml, e.g. "hold" or "~1.5"
+	Hold       bool
+	Constraint Constraint
+}
+
+// ParsePin parses a pin spec: "hold" or a Constraint
+func ParsePin(spec string) (Pin, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == PinHold {
+		return Pin{Spec: spec, Hold: true}, nil
+	}
+	c, err := ParseConstraint(spec)
+	if err != nil {
+		return Pin{}, err
+	}
+	return Pin{Spec: spec, Constraint: c}, nil
+}
+
+// Classify is ClassifyVersions for a pinned tool: a newer release the pin
+// excludes leaves the tool up to date, with a message saying why
+func (p Pin) Classify(local, remote string) (core.ToolStatus, string) {
+	status, message := ClassifyVersions(local, remote)
+	if status != core.StatusOutdated {
+		return status, message
+	}
+	if p.Hold {
+		return core.StatusInstalled, "Held at " + local
+	}
+	if !p.Constraint.Allows(remote) {
+		return core.StatusInstalled, fmt.Sprintf("Pinned to %s, %s excluded", p.Spec, remote)
+	}
+	return status, "Update available within pin " + p.Spec
+}
+
+// Pins maps tool keys to their pins. The zero value pins nothing.
+type Pins map[string]Pin
+
+// ParsePins parses the [pins] table of config.toml. Pins for keys that name
+// no tool of the inventory, such as disabled tools, are left out and their
+// keys returned as unknown.
+func ParsePins(specs map[string]string, tools []core.Tool) (pins Pins, unknown []string, err error) {
+	known := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		known[t.Key] = true
+	}
+
+	keys := make([]string, 0, len(specs))
+	for key := range specs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // Report the first bad entry deterministically
+
+	pins = make(Pins, len(specs))
+	for _, key := range keys {
+		pin, err := ParsePin(specs[key])
+		if err != nil {
+			return nil, nil, fmt.Errorf("pins.%s: %w", key, err)
+		}
+		if !known[key] {
+			unknown = append(unknown, key)
+			continue
+		}
+		pins[key] = pin
+	}
+	return pins, unknown, nil
+}
+
+// For returns t's pin
+func (p Pins) For(t core.Tool) (Pin, bool) {
+	pin, ok := p[t.Key]
+	return pin, ok
+}
+
+// Spec returns t's pin spec, "" when t is not pinned
+func (p Pins) Spec(t core.Tool) string {
+	return p[t.Key].Spec
+}
+
+// Classify classifies t's versions, honoring its pin if it has one
+func (p Pins) Classify(t core.Tool, local, remote string) (core.ToolStatus, string) {
+	if pin, ok := p.For(t); ok {
+		return pin.Classify(local, remote)
+	}
+	return ClassifyVersions(local, remote)
+}
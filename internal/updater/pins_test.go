@@ -0,0 +1,109 @@
+package updater
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestConstraintAllows(t *testing.T) {
+	tests := []struct {
+		constraint, version string
+		want                bool
+	}{
+		{"16", "16.4", true},
+		{"16", "17.0", false},
+		{"16", "1.6", false},
+		{"=1.5.7", "1.5.7", true},
+		{"=1.5.7", "1.5.8", false},
+		{"~1.5.2", "1.5.9", true},
+		{"~1.5.2", "1.6.0", false},
+		{"~1.5.2", "1.5.1", false},
+		{"~1", "1.9.0", true},
+		{"^1.5", "1.99.0", true},
+		{"^1.5", "2.0.0", false},
+		{"^0.3.1", "0.3.9", true},
+		{"^0.3.1", "0.4.0", false},
+		{">=1.5, <2", "1.7.3", true},
+		{">=1.5 <2", "2.0.0", false},
+		{">= 1.5", "1.5.0", true},
+		{"<2", "2.0.0-rc.1", true},
+		{"<=2", "2.0.1", false},
+		{">1.5", "1.5.0", false},
+		{"16", "MISSING", false},
+		{"16", "abc123f", false},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q) error = %v", tt.constraint, err)
+		}
+		if got := c.Allows(tt.version); got != tt.want {
+			t.Errorf("%q.Allows(%q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, s := range []string{"", "latest", ">=", "~abc123f"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q) succeeded", s)
+		}
+	}
+}
+
+func TestPinClassify(t *testing.T) {
+	tests := []struct {
+		spec, local, remote string
+		want                core.ToolStatus
+		wantMessage         string
+	}{
+		{"hold", "16.4", "17.2", core.StatusInstalled, "Held at 16.4"},
+		{"16", "16.4", "17.2", core.StatusInstalled, "Pinned to 16, 17.2 excluded"},
+		{"16", "16.4", "16.6", core.StatusOutdated, "Update available within pin 16"},
+		{"~1.5", "1.5.7", "1.9.0", core.StatusInstalled, "Pinned to ~1.5, 1.9.0 excluded"},
+		{"hold", "MISSING", "1.0.0", core.StatusMissing, "Not installed"},
+		{"hold", "2.0.0", "1.0.0", core.StatusAhead, "Newer than latest release"},
+	}
+	for _, tt := range tests {
+		pin, err := ParsePin(tt.spec)
+		if err != nil {
+			t.Fatalf("ParsePin(%q) error = %v", tt.spec, err)
+		}
+		status, message := pin.Classify(tt.local, tt.remote)
+		if status != tt.want || message != tt.wantMessage {
+			t.Errorf("pin %q: Classify(%q, %q) = (%v, %q), want (%v, %q)",
+				tt.spec, tt.local, tt.remote, status, message, tt.want, tt.wantMessage)
+		}
+	}
+}
+
+func TestParsePins(t *testing.T) {
+	tools := []core.Tool{{Key: "postgresql"}, {Key: "terraform"}}
+
+	pins, unknown, err := ParsePins(map[string]string{"postgresql": "16", "terraform": "hold", "terraformm": "hold", "gone": "1"}, tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec := pins.Spec(core.Tool{Key: "postgresql"}); spec != "16" {
+		t.Errorf("Spec(postgresql) = %q, want 16", spec)
+	}
+	if _, ok := pins.For(core.Tool{Key: "jq"}); ok {
+		t.Error("For(jq) found a pin")
+	}
+	if _, ok := pins["terraformm"]; ok {
+		t.Error("kept the pin for terraformm, which names no tool")
+	}
+	if want := []string{"gone", "terraformm"}; !reflect.DeepEqual(unknown, want) {
+		t.Errorf("unknown = %q, want %q", unknown, want)
+	}
+
+	// A bad spec is an error even for a key that names no tool
+	for _, specs := range []map[string]string{{"terraform": "newest"}, {"gone": "newest"}} {
+		if _, _, err := ParsePins(specs, tools); err == nil || !strings.Contains(err.Error(), "pins.") {
+			t.Errorf("ParsePins(%v) error = %v, want the bad pin", specs, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// pipxStrategy handles Python applications installed with pipx, each in its own venv
+type pipxStrategy struct{}
+
+func init() {
+	Register(&pipxStrategy{}, core.MethodPipx)
+}
+
+func (s *pipxStrategy) Name() string { return "pipx" }
+
+func (s *pipxStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	return d.detectListed(t, parsePipxList(d.runOutput("pipx", "list", "--json")), SourcePipxList)
+}
+
+// WarmUp asks PyPI about every installed venv: pipx has no outdated command
+func (s *pipxStrategy) WarmUp(d *Detector) {
+	installed := parsePipxList(d.runOutput("pipx", "list", "--json"))
+	d.warmUpFromRegistry(s.Name(), core.RegistryPyPI, sameNames(installed))
+}
+
+func (s *pipxStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	switch a {
+	case ActionInstall:
+		return []PlanStep{step("pipx", "install", packageName(t))}
+	case ActionUninstall:
+		return []PlanStep{step("pipx", "uninstall", packageName(t))}
+	}
+	return []PlanStep{step("pipx", "upgrade", packageName(t))}
+}
+
+// LockKey is per manager: pipx shares one shared-libs venv between its apps
+func (s *pipxStrategy) LockKey(t core.Tool) string { return "pipx" }
+
+// Owns claims the venvs pipx links into ~/.local/bin
+func (s *pipxStrategy) Owns(o Owner) bool { return o == OwnerPipx }
+
+func (s *pipxStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "pipx upgrade", s.Plan(ActionUpgrade, t))
+}
+
+func (s *pipxStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "pipx install", s.Plan(ActionInstall, t))
+}
+
+func (s *pipxStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "pipx uninstall", s.Plan(ActionUninstall, t))
+}
+
+// parsePipxList handles `pipx list --json`, keyed by the venv's main package
+func parsePipxList(out string) map[string]string {
+	var data struct {
+		Venvs map[string]struct {
+			Metadata struct {
+				MainPackage struct {
+					Package        string `json:"package"`
+					PackageVersion string `json:"package_version"`
+				} `json:"main_package"`
+			} `json:"metadata"`
+		} `json:"venvs"`
+	}
+	result := make(map[string]string)
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		return result
+	}
+	for venv, info := range data.Venvs {
+		name := info.Metadata.MainPackage.Package
+		if name == "" {
+			name = venv
+		}
+		result[name] = info.Metadata.MainPackage.PackageVersion
+	}
+	return result
+}
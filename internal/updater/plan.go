@@ -0,0 +1,205 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// PlanStep is one command an action runs. Strategies build their commands
+// from these steps, so a dry run shows exactly what would execute.
+type PlanStep struct {
+	Args       []string // argv, without the sudo prefix
+	Env        []string // KEY=VALUE added to the environment
+	Privileged bool     // Runs through non-interactive sudo unless Spark runs as root
+	Remote     bool     // Downloads a script and runs it (curl | sh)
+	Probe      bool     // Read-only check; when it fails the rest is skipped
+	RetryOn    string   // Only runs when the previous step failed with this in its output
+	Note       string   // Why a probe or retry is there
+}
+
+// Argv is the command line as executed, sudo included
+func (s PlanStep) Argv() []string {
+	if s.Privileged {
+		return privileged(s.Args...)
+	}
+	return s.Args
+}
+
+// Shell renders the exact command line, quoted for a POSIX shell
+func (s PlanStep) Shell() string {
+	var parts []string
+	if len(s.Env) > 0 {
+		parts = append(parts, "env")
+		for _, kv := range s.Env {
+			parts = append(parts, shellQuote(kv))
+		}
+	}
+	for _, arg := range s.Argv() {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// String is the command as shown in logs: a script instead of its `sh -c`
+// (or `bash -c`) wrapper, sudo without its flags
+func (s PlanStep) String() string {
+	if len(s.Args) == 3 && (s.Args[0] == "sh" || s.Args[0] == "bash") && s.Args[1] == "-c" {
+		return s.Args[2]
+	}
+	cmd := Command{Name: s.Args[0], Args: s.Args[1:]}.String()
+	if s.Privileged {
+		return sudoPrefix() + cmd
+	}
+	return cmd
+}
+
+// shellQuote single-quotes s unless it is made of characters every shell
+// takes literally
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:@+,%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// step is a PlanStep running argv as is
+func step(args ...string) PlanStep {
+	return PlanStep{Args: args}
+}
+
+// privilegedStep is a PlanStep that needs root
+func privilegedStep(args ...string) PlanStep {
+	return PlanStep{Args: args, Privileged: true}
+}
+
+// scriptStep runs a shell one-liner; remote marks a downloaded script
+func scriptStep(script string, remote bool) PlanStep {
+	return PlanStep{Args: []string{"sh", "-c", script}, Remote: remote}
+}
+
+// summarize joins the steps that always run, for PlannedCommand
+func summarize(steps []PlanStep) string {
+	var cmds []string
+	for _, s := range steps {
+		if !s.Probe && s.RetryOn == "" {
+			cmds = append(cmds, s.String())
+		}
+	}
+	return strings.Join(cmds, " && ")
+}
+
+// Plan is what applying an action to a tool would do
+type Plan struct {
+	Tool   core.Tool
+	Action Action
+	Steps  []PlanStep
+	From   string // Installed version, "" when missing or unknown
+	Target string // Expected version afterwards, "" when unknown or removed
+	Manual string // Why nothing can run, when Steps is empty
+	Note   string // Why the steps use another method than the tool's, see Redirect
+}
+
+// Privileged reports whether any step needs sudo
+func (p Plan) Privileged() bool {
+	for _, s := range p.Steps {
+		if s.Privileged {
+			return true
+		}
+	}
+	return false
+}
+
+// Remote reports whether any step downloads and runs a script
+func (p Plan) Remote() bool {
+	for _, s := range p.Steps {
+		if s.Remote {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan describes what applying a to the tool in s would run, without running
+// anything. Rollback plans come from the recorded recipe; upgrades go through
+// the owner of the binary on PATH, see Redirect.
+func (e *Executor) Plan(a Action, s core.ToolState) Plan {
+	p := Plan{Tool: s.Tool, Action: a}
+	if IsKnownVersion(s.LocalVersion) {
+		p.From = s.LocalVersion
+	}
+
+	if a == ActionRollback {
+		rb, ok := e.rollbacks.Get(s.Tool.Key)
+		if !ok {
+			p.Manual = "no rollback recorded"
+			return p
+		}
+		for _, rs := range rb.Steps {
+			p.Steps = append(p.Steps, PlanStep{Args: rs.Args, Privileged: rs.Privileged})
+		}
+		p.Target = rb.Version
+		return p
+	}
+
+	if a == ActionUpgrade {
+		t, err := Redirect(s)
+		if err != nil {
+			p.Manual = err.Error()
+			return p
+		}
+		if t.Method != s.Tool.Method {
+			p.Tool, p.Note = t, OwnerReason(s)
+		}
+	}
+
+	strategy, ok := StrategyFor(p.Tool.Method)
+	if !ok {
+		p.Manual = fmt.Sprintf("update method %s not implemented", p.Tool.Method)
+		return p
+	}
+	t, err := e.resolve(strategy, a, p.Tool)
+	if err != nil {
+		p.Manual = err.Error()
+		return p
+	}
+	p.Steps = strategy.Plan(a, t)
+	if len(p.Steps) == 0 {
+		p.Manual = "manual " + a.String() + " required (check vendor portal)"
+		return p
+	}
+	if a != ActionUninstall && IsKnownVersion(s.RemoteVersion) {
+		p.Target = s.RemoteVersion
+	}
+	return p
+}
+
+// stepOutput runs one plan step and returns its combined output
+func (e *Executor) stepOutput(ctx context.Context, s PlanStep) (string, error) {
+	argv := s.Argv()
+	res := e.exec(ctx, Command{Name: argv[0], Args: argv[1:], Env: s.Env})
+	return res.Combined(), res.Err
+}
+
+// runStep runs one plan step, wrapping failures like run and runPrivileged
+func (e *Executor) runStep(ctx context.Context, label string, s PlanStep) error {
+	if s.Privileged {
+		return e.runPrivileged(ctx, label, s.Args...)
+	}
+	if output, err := e.stepOutput(ctx, s); err != nil {
+		return fmt.Errorf("%s failed: %s: %v", label, output, err)
+	}
+	return nil
+}
+
+// runSteps runs steps in order, stopping at the first failure
+func (e *Executor) runSteps(ctx context.Context, label string, steps []PlanStep) error {
+	for _, s := range steps {
+		if err := e.runStep(ctx, label, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package updater
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestExecutorPlan(t *testing.T) {
+	store := newTestRollbacks(t)
+	store.set(Rollback{Key: "jq", Version: "1.6", Steps: []RollbackStep{{Args: []string{"brew", "install", "spark/rollback/jq@1.6"}}}})
+	e := NewExecutorWithRunner(NewFakeRunner())
+	e.UseRollbacks(store)
+
+	gemini := core.Tool{Key: "gemini", Package: "@google/gemini-cli", Method: core.MethodNpmPkg}
+	zed := core.Tool{Key: "zed", Package: "zed", Method: core.MethodMacApp}
+	toad := core.Tool{Key: "toad", Method: core.MethodToad}
+	jq := core.Tool{Key: "jq", Package: "jq", Method: core.MethodBrewPkg}
+	apt := core.Tool{Key: "git", Package: "git", Method: core.MethodApt}
+
+	tests := []struct {
+		name       string
+		action     Action
+		state      core.ToolState
+		wantShell  []string
+		wantTarget string
+		check      func(t *testing.T, p Plan)
+	}{
+		{
+			name:       "npm retry on EEXIST",
+			state:      core.ToolState{Tool: gemini, LocalVersion: "0.9.0", RemoteVersion: "0.10.1"},
+			wantShell:  []string{"npm install -g @google/gemini-cli@latest", "npm install -g @google/gemini-cli@latest --force"},
+			wantTarget: "0.10.1",
+			check: func(t *testing.T, p Plan) {
+				if p.Steps[1].RetryOn != "EEXIST" {
+					t.Errorf("retry step RetryOn = %q, want EEXIST", p.Steps[1].RetryOn)
+				}
+			},
+		},
+		{
+			name:       "cask probe",
+			state:      core.ToolState{Tool: zed, LocalVersion: "0.150.0", RemoteVersion: "Unknown"},
+			wantShell:  []string{"brew list --cask zed", "brew upgrade --cask zed"},
+			wantTarget: "",
+			check: func(t *testing.T, p Plan) {
+				if !p.Steps[0].Probe {
+					t.Error("cask check is not a probe")
+				}
+			},
+		},
+		{
+			name:       "remote script",
+			action:     ActionInstall,
+			state:      core.ToolState{Tool: toad, LocalVersion: "MISSING"},
+			wantShell:  []string{"sh -c 'curl -fsSL https://batrachian.ai/install | sh'"},
+			wantTarget: "",
+			check: func(t *testing.T, p Plan) {
+				if !p.Remote() || p.From != "" {
+					t.Errorf("Remote() = %v, From = %q, want a remote script from nothing", p.Remote(), p.From)
+				}
+			},
+		},
+		{
+			name:       "privileged",
+			state:      core.ToolState{Tool: apt, LocalVersion: "2.43.0", RemoteVersion: "2.43.1"},
+			wantShell:  []string{PlanStep{Args: []string{"apt-get", "install", "--only-upgrade", "-y", "-q", "git"}, Privileged: true}.Shell()},
+			wantTarget: "2.43.1",
+			check: func(t *testing.T, p Plan) {
+				if !p.Privileged() {
+					t.Error("apt plan does not need sudo")
+				}
+			},
+		},
+		{
+			name:       "rollback recipe",
+			action:     ActionRollback,
+			state:      core.ToolState{Tool: jq, LocalVersion: "1.7.1", RemoteVersion: "1.7.1"},
+			wantShell:  []string{"brew install spark/rollback/jq@1.6"},
+			wantTarget: "1.6",
+		},
+		{
+			name:   "manual",
+			action: ActionUninstall,
+			state:  core.ToolState{Tool: core.Tool{Key: "omz", Method: core.MethodOmz}, LocalVersion: "abc123f"},
+			check: func(t *testing.T, p Plan) {
+				if p.Manual == "" {
+					t.Error("manual plan has no reason")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := e.Plan(tt.action, tt.state)
+			var shell []string
+			for _, s := range p.Steps {
+				shell = append(shell, s.Shell())
+			}
+			if !reflect.DeepEqual(shell, tt.wantShell) {
+				t.Errorf("steps = %q, want %q", shell, tt.wantShell)
+			}
+			if p.Target != tt.wantTarget {
+				t.Errorf("Target = %q, want %q", p.Target, tt.wantTarget)
+			}
+			if tt.check != nil {
+				tt.check(t, p)
+			}
+		})
+	}
+}
+
+func TestPlanStepShell(t *testing.T) {
+	s := PlanStep{Args: []string{"sh", "-c", "$ZSH/tools/upgrade.sh"}, Env: []string{"ZSH=~/.oh-my-zsh"}}
+	if got, want := s.Shell(), "env 'ZSH=~/.oh-my-zsh' sh -c '$ZSH/tools/upgrade.sh'"; got != want {
+		t.Errorf("Shell() = %q, want %q", got, want)
+	}
+	if got, want := s.String(), "$ZSH/tools/upgrade.sh"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := shellQuote("it's"), `'it'\''s'`; got != want {
+		t.Errorf("shellQuote() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package updater
+
+import "os/exec"
+
+// setProcessGroup is a no-op where process groups don't exist; cancelling
+// the context still kills the direct child.
+func setProcessGroup(cmd *exec.Cmd) (release func()) { return func() {} }
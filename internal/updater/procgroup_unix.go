@@ -0,0 +1,41 @@
+//go:build unix
+
+package updater
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// killGrace is how long a cancelled command gets to exit after SIGTERM
+var killGrace = 5 * time.Second
+
+// signalGroup sends sig to a process group, replaced in tests
+var signalGroup = func(pgid int, sig syscall.Signal) error { return syscall.Kill(-pgid, sig) }
+
+// setProcessGroup runs cmd in its own process group so cancelling the context
+// stops everything it spawned (brew's curl, npm's node-gyp, sudo's child),
+// not just the direct child. The group gets SIGTERM first and SIGKILL after
+// killGrace if anything is still alive.
+//
+// The returned function must be called once cmd.Wait has returned: it stops
+// the pending SIGKILL, whose pgid may belong to another group by then.
+func setProcessGroup(cmd *exec.Cmd) (release func()) {
+	var kill *time.Timer
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		err := signalGroup(pgid, syscall.SIGTERM)
+		kill = time.AfterFunc(killGrace, func() { _ = signalGroup(pgid, syscall.SIGKILL) })
+		return err
+	}
+	// Don't hang on grandchildren that keep stdout open after the group is gone
+	cmd.WaitDelay = killGrace + time.Second
+	// Wait returns only after Cancel has, so kill is set by the time this runs
+	return func() {
+		if kill != nil {
+			kill.Stop()
+		}
+	}
+}
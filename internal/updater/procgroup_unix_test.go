@@ -0,0 +1,56 @@
+//go:build unix
+
+package updater
+
+import (
+	"context"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExecRunnerCancelKillsProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	// The backgrounded sleep inherits stdout; if only sh were killed, Run
+	// would block on the open pipe until WaitDelay expires.
+	start := time.Now()
+	res := ExecRunner{}.Run(ctx, Command{Name: "sh", Args: []string{"-c", "sleep 30 & sleep 30"}})
+	if res.Err == nil {
+		t.Fatal("Run() succeeded, want cancellation error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Run() took %s after cancel, want the whole group terminated", elapsed)
+	}
+}
+
+func TestExecRunnerCancelSkipsKillAfterExit(t *testing.T) {
+	var mu sync.Mutex
+	var sent []syscall.Signal
+	defer func(grace time.Duration, signal func(int, syscall.Signal) error) {
+		killGrace, signalGroup = grace, signal
+	}(killGrace, signalGroup)
+	killGrace = 200 * time.Millisecond
+	signalGroup = func(pgid int, sig syscall.Signal) error {
+		mu.Lock()
+		sent = append(sent, sig)
+		mu.Unlock()
+		return syscall.Kill(-pgid, sig)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	if res := (ExecRunner{}).Run(ctx, Command{Name: "sleep", Args: []string{"30"}}); res.Err == nil {
+		t.Fatal("Run() succeeded, want cancellation error")
+	}
+
+	// sleep exits on SIGTERM; its pgid may be reused by the time SIGKILL would fire
+	time.Sleep(2 * killGrace)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 1 || sent[0] != syscall.SIGTERM {
+		t.Errorf("signals = %v, want only SIGTERM", sent)
+	}
+}
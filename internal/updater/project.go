@@ -0,0 +1,87 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// ProjectFile is the name of a repository's tool requirements
+const ProjectFile = ".spark.toml"
+
+// ErrNoProject is returned by FindProject when no project file applies
+var ErrNoProject = errors.New("no " + ProjectFile + " found")
+
+// FindProject returns the project file that applies in dir: the nearest
+// .spark.toml in dir or its parents, looking no higher than the root of the
+// git repository dir is in
+func FindProject(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, ProjectFile)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", ErrNoProject // Repository root, parents belong to other projects
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrNoProject
+		}
+		dir = parent
+	}
+}
+
+// LoadProject reads a project file. It is a manifest without the version key:
+//
+//	[tools]
+//	terraform = ">=1.6"
+//	helm      = "*"
+//	kubectl   = ">=1.28, <1.31"
+func LoadProject(path string, tools []core.Tool) (Manifest, error) {
+	var doc struct {
+		Tools map[string]string `toml:"tools"`
+	}
+	md, err := toml.DecodeFile(path, &doc)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Manifest{}, fmt.Errorf("%s: %w", path, ErrNoProject)
+	}
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return Manifest{}, fmt.Errorf("%s: unknown key %q (expected [tools])", path, undecoded[0].String())
+	}
+
+	reqs, err := parseRequirements(doc.Tools, tools)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return Manifest{Path: path, Tools: reqs}, nil
+}
+
+// Unmet returns the listed tools among states whose installed version does
+// not meet their requirement, keeping their order
+func (m Manifest) Unmet(states []core.ToolState) []core.ToolState {
+	var unmet []core.ToolState
+	for _, s := range states {
+		if req, ok := m.Tools[s.Tool.Key]; ok && !req.Allows(s.LocalVersion) {
+			unmet = append(unmet, s)
+		}
+	}
+	return unmet
+}
+
+// Spec returns the requirement written for t, "" when t is not listed
+func (m Manifest) Spec(t core.Tool) string {
+	return m.Tools[t.Key].Spec
+}
@@ -0,0 +1,72 @@
+package updater
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestFindProject(t *testing.T) {
+	outer := t.TempDir()
+	repo := filepath.Join(outer, "repo")
+	nested := filepath.Join(repo, "modules", "network")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A project file above the repository does not apply to it
+	if err := os.WriteFile(filepath.Join(outer, ProjectFile), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FindProject(nested); !errors.Is(err, ErrNoProject) {
+		t.Errorf("FindProject() without a project in the repository: error = %v, want ErrNoProject", err)
+	}
+
+	want := filepath.Join(repo, ProjectFile)
+	if err := os.WriteFile(want, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := FindProject(nested); err != nil || got != want {
+		t.Errorf("FindProject() = %q, %v, want %q", got, err, want)
+	}
+}
+
+func TestLoadProject(t *testing.T) {
+	tools := []core.Tool{{ID: "F-01", Key: "terraform"}, {ID: "F-02", Key: "helm"}, {ID: "U-01", Key: "jq"}}
+	path := filepath.Join(t.TempDir(), ProjectFile)
+	content := "[tools]\nterraform = \">=1.6\"\nF-02 = \"*\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadProject(path, tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := []core.ToolState{
+		{Tool: tools[0], LocalVersion: "1.5.7"},
+		{Tool: tools[1], LocalVersion: "MISSING"},
+		{Tool: tools[2], LocalVersion: "MISSING"},
+	}
+	unmet := m.Unmet(states)
+	if len(unmet) != 2 || unmet[0].Tool.Key != "terraform" || unmet[1].Tool.Key != "helm" {
+		t.Errorf("Unmet() = %v, want terraform and helm", unmet)
+	}
+	if spec := m.Spec(tools[0]); spec != ">=1.6" {
+		t.Errorf("Spec(terraform) = %q, want >=1.6", spec)
+	}
+
+	if err := os.WriteFile(path, []byte("version = 1\n[tools]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProject(path, tools); err == nil || !strings.Contains(err.Error(), "unknown key") {
+		t.Errorf("LoadProject() with a version key: error = %v, want unknown key", err)
+	}
+}
@@ -0,0 +1,288 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// Owner is the installer a binary on PATH belongs to, judged from where it
+// lives once symlinks are followed
+type Owner string
+
+const (
+	OwnerUnknown  Owner = ""          // Nowhere Spark recognizes, e.g. /usr/local/bin or /opt
+	OwnerBrew     Owner = "brew"      // Homebrew Cellar or Caskroom
+	OwnerNpm      Owner = "npm"       // A global npm prefix (lib/node_modules)
+	OwnerLocalBin Owner = "local_bin" // ~/.local/bin, where vendor install scripts put binaries
+	OwnerNvm      Owner = "nvm"       // ~/.nvm/versions
+	OwnerAsdf     Owner = "asdf"      // ~/.asdf shims and installs
+	OwnerMise     Owner = "mise"      // ~/.local/share/mise shims and installs
+	OwnerVolta    Owner = "volta"     // ~/.volta
+	OwnerPyenv    Owner = "pyenv"     // ~/.pyenv shims and versions
+	OwnerRbenv    Owner = "rbenv"     // ~/.rbenv shims and versions
+	OwnerGoenv    Owner = "goenv"     // ~/.goenv shims and versions
+	OwnerPipx     Owner = "pipx"      // pipx venvs, linked from ~/.local/bin
+	OwnerUv       Owner = "uv"        // uv tool environments, linked from ~/.local/bin
+	OwnerCargo    Owner = "cargo"     // ~/.cargo/bin
+	OwnerGo       Owner = "go"        // ~/go/bin, the default GOBIN
+	OwnerSnap     Owner = "snap"      // /snap/bin launchers
+	OwnerFlatpak  Owner = "flatpak"   // Flatpak exports and app directories, system or user
+	OwnerAppImage Owner = "appimage"  // A self-contained .AppImage file
+	OwnerSystem   Owner = "system"    // /usr/bin, /bin and the sbin directories
+)
+
+// describe names the owner in messages
+func (o Owner) describe() string {
+	switch o {
+	case OwnerBrew:
+		return "Homebrew"
+	case OwnerLocalBin:
+		return "a vendor install script"
+	case OwnerVolta:
+		return "Volta"
+	case OwnerSystem:
+		return "the system package manager"
+	case OwnerUv:
+		return "uv tool"
+	case OwnerCargo:
+		return "cargo install"
+	case OwnerGo:
+		return "go install"
+	case OwnerFlatpak:
+		return "Flatpak"
+	case OwnerAppImage:
+		return "an AppImage"
+	}
+	return string(o)
+}
+
+// ErrOwnerMismatch is returned when the binary on PATH belongs to another
+// installer than the tool's method, so upgrading would change a copy nobody runs
+var ErrOwnerMismatch = errors.New("wrong package manager")
+
+// Provenance is where the binary a tool runs from came from
+type Provenance struct {
+	Path     string // Binary found on PATH, "" when not found
+	Resolved string // Path with symlinks followed
+	Owner    Owner
+}
+
+// DetectProvenance finds t's binary on PATH, follows its symlinks and tells
+// which installer put it there
+func (d *Detector) DetectProvenance(t core.Tool) Provenance {
+	if t.Binary == "" {
+		return Provenance{}
+	}
+	path, err := d.runner.LookPath(t.Binary)
+	if err != nil || path == "" {
+		if s, ok := StrategyFor(t.Method); ok {
+			if l, ok := s.(appLocator); ok {
+				p, _ := l.Locate(d, t)
+				return p
+			}
+		}
+		return Provenance{}
+	}
+	resolved := resolveBinary(path)
+	return Provenance{Path: path, Resolved: resolved, Owner: classifyBinary(path, resolved, d.home)}
+}
+
+// resolveBinary follows path's symlinks, except for snap launchers: they all
+// link to /usr/bin/snap, which picks the snap to run by the launcher's name
+func resolveBinary(path string) string {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil || strings.HasPrefix(path, "/snap/bin/") {
+		return path
+	}
+	return resolved
+}
+
+// appLocator is implemented by strategies whose tools need not be on PATH,
+// such as desktop applications, to tell where the installed copy lives
+type appLocator interface {
+	Locate(d *Detector, t core.Tool) (Provenance, bool)
+}
+
+// classifyBinary maps a binary to its owner. Version manager shims are
+// scripts, so they are recognized by the PATH entry; everything else by
+// where the symlinks lead, which tells an npm package linked from
+// ~/.local/bin from a vendor binary copied there.
+func classifyBinary(path, resolved, home string) Owner {
+	under := func(p, dir string) bool {
+		return strings.HasPrefix(p, filepath.Join(home, dir)+string(filepath.Separator))
+	}
+	switch {
+	case home != "" && under(path, ".asdf/shims"):
+		return OwnerAsdf
+	case home != "" && under(path, ".local/share/mise/shims"):
+		return OwnerMise
+	case home != "" && under(path, ".volta/bin"):
+		return OwnerVolta
+	case home != "" && under(path, ".pyenv/shims"):
+		return OwnerPyenv
+	case home != "" && under(path, ".rbenv/shims"):
+		return OwnerRbenv
+	case home != "" && under(path, ".goenv/shims"):
+		return OwnerGoenv
+	case strings.Contains(resolved, "/lib/node_modules/"):
+		return OwnerNpm // Even under nvm: the active npm owns its global packages
+	case strings.Contains(resolved, "/Cellar/") || strings.Contains(resolved, "/Caskroom/"):
+		return OwnerBrew
+	case home != "" && under(resolved, ".nvm"):
+		return OwnerNvm
+	case home != "" && under(resolved, ".asdf/installs"):
+		return OwnerAsdf
+	case home != "" && under(resolved, ".local/share/mise/installs"):
+		return OwnerMise
+	case home != "" && under(resolved, ".volta"):
+		return OwnerVolta
+	case home != "" && under(resolved, ".pyenv/versions"):
+		return OwnerPyenv
+	case home != "" && under(resolved, ".rbenv/versions"):
+		return OwnerRbenv
+	case home != "" && under(resolved, ".goenv/versions"):
+		return OwnerGoenv
+	case home != "" && (under(resolved, ".local/share/pipx/venvs") || under(resolved, ".local/pipx/venvs")):
+		return OwnerPipx
+	case home != "" && under(resolved, ".local/share/uv/tools"):
+		return OwnerUv
+	case home != "" && under(path, ".cargo/bin"):
+		return OwnerCargo
+	case home != "" && under(path, "go/bin"):
+		return OwnerGo
+	case strings.HasPrefix(path, "/snap/") || strings.HasPrefix(resolved, "/snap/"):
+		return OwnerSnap
+	case strings.Contains(resolved, "/flatpak/app/") || strings.Contains(path, "/flatpak/exports/bin/"):
+		return OwnerFlatpak
+	case strings.HasSuffix(strings.ToLower(resolved), ".appimage"):
+		return OwnerAppImage
+	case home != "" && under(path, ".local/bin"):
+		return OwnerLocalBin
+	}
+	for _, dir := range []string{"/usr/bin/", "/bin/", "/usr/sbin/", "/sbin/"} {
+		if strings.HasPrefix(path, dir) || strings.HasPrefix(resolved, dir) {
+			return OwnerSystem
+		}
+	}
+	return OwnerUnknown
+}
+
+// binaryOwner is implemented by strategies whose package manager installs
+// binaries somewhere classifyBinary recognizes
+type binaryOwner interface {
+	Owns(o Owner) bool
+}
+
+// OwnerMatches reports whether upgrading t through its method changes the
+// binary owned by o. An unknown owner, or a method whose installs cannot be
+// recognized, gets the benefit of the doubt.
+func OwnerMatches(t core.Tool, o Owner) bool {
+	s, ok := StrategyFor(t.Method)
+	if !ok || o == OwnerUnknown {
+		return true
+	}
+	if b, ok := s.(binaryOwner); ok {
+		return b.Owns(o)
+	}
+	return true
+}
+
+// Redirect returns the tool an upgrade of s should go through. That is the
+// tool itself when its method owns the binary on PATH; when Homebrew, npm,
+// pipx, uv, snap or Flatpak owns it instead, or it is an AppImage, the tool
+// is rewritten to upgrade the formula, cask, package or file the binary
+// comes from. Any other owner wraps ErrOwnerMismatch.
+func Redirect(s core.ToolState) (core.Tool, error) {
+	owner := Owner(s.Owner)
+	if OwnerMatches(s.Tool, owner) {
+		return s.Tool, nil
+	}
+
+	t := s.Tool
+	switch owner {
+	case OwnerBrew:
+		if pkg, ok := pathSegmentAfter(s.BinaryPath, "Caskroom"); ok {
+			t.Method, t.Package = core.MethodMacApp, pkg
+			return t, nil
+		}
+		if pkg, ok := pathSegmentAfter(s.BinaryPath, "Cellar"); ok {
+			t.Method, t.Package = core.MethodBrewPkg, pkg
+			return t, nil
+		}
+	case OwnerNpm:
+		if pkg, ok := pathSegmentAfter(s.BinaryPath, "node_modules"); ok {
+			if strings.HasPrefix(pkg, "@") {
+				scoped, _ := pathSegmentAfter(s.BinaryPath, pkg)
+				pkg += "/" + scoped
+			}
+			t.Method, t.Package = core.MethodNpmPkg, pkg
+			return t, nil
+		}
+	case OwnerPipx:
+		if pkg, ok := pathSegmentAfter(s.BinaryPath, "venvs"); ok {
+			t.Method, t.Package = core.MethodPipx, pkg
+			return t, nil
+		}
+	case OwnerUv:
+		if pkg, ok := pathSegmentAfter(s.BinaryPath, "tools"); ok {
+			t.Method, t.Package = core.MethodUv, pkg
+			return t, nil
+		}
+	case OwnerSnap:
+		// /snap/bin/<snap>[.<app>], or /snap/<snap>/<revision>/...
+		pkg, _, _ := strings.Cut(filepath.Base(s.BinaryPath), ".")
+		if !strings.HasPrefix(s.BinaryPath, "/snap/bin/") {
+			pkg, _ = pathSegmentAfter(s.BinaryPath, "snap")
+		}
+		if pkg != "" {
+			t.Method, t.Package = core.MethodSnap, pkg
+			return t, nil
+		}
+	case OwnerFlatpak:
+		// exports/bin/<id> or app/<id>/...
+		id, ok := pathSegmentAfter(s.BinaryPath, "app")
+		if !ok && strings.Contains(s.BinaryPath, "/exports/bin/") {
+			id, ok = filepath.Base(s.BinaryPath), true
+		}
+		if ok {
+			t.Method, t.Package = core.MethodFlatpak, id
+			return t, nil
+		}
+	case OwnerAppImage:
+		t.Method, t.Package = core.MethodAppImage, s.BinaryPath
+		return t, nil
+	}
+
+	method := string(s.Tool.Method)
+	if st, ok := StrategyFor(s.Tool.Method); ok {
+		method = st.Name()
+	}
+	return s.Tool, fmt.Errorf("%w: %s, so upgrading with %s would not change it (update it there, or set its method in the inventory)",
+		ErrOwnerMismatch, OwnerReason(s), method)
+}
+
+// OwnerReason describes the binary on PATH and its owner, and the managed
+// copy it shadows, for messages about redirected and refused upgrades
+func OwnerReason(s core.ToolState) string {
+	reason := fmt.Sprintf("%s on PATH resolves to %s, installed by %s", s.Tool.Binary, s.BinaryPath, Owner(s.Owner).describe())
+	if s.Shadowed != "" {
+		reason += " ahead of " + s.Shadowed
+	}
+	return reason
+}
+
+// pathSegmentAfter returns the path element that follows the first dir in p,
+// such as the formula in .../Cellar/<formula>/<version>/bin/jq
+func pathSegmentAfter(p, dir string) (string, bool) {
+	parts := strings.Split(filepath.ToSlash(p), "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] == dir && parts[i+1] != "" {
+			return parts[i+1], true
+		}
+	}
+	return "", false
+}
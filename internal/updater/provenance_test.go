@@ -0,0 +1,174 @@
+package updater
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestClassifyBinary(t *testing.T) {
+	home := "/home/dev"
+	tests := []struct {
+		path, resolved string
+		want           Owner
+	}{
+		{"/opt/homebrew/bin/jq", "/opt/homebrew/Cellar/jq/1.7.1/bin/jq", OwnerBrew},
+		{"/opt/homebrew/bin/ghostty", "/opt/homebrew/Caskroom/ghostty/1.1.0/Ghostty.app/Contents/MacOS/ghostty", OwnerBrew},
+		{"/usr/local/bin/gemini", "/usr/local/lib/node_modules/@google/gemini-cli/dist/index.js", OwnerNpm},
+		{"/home/dev/.nvm/versions/node/v20.11.0/bin/codex", "/home/dev/.nvm/versions/node/v20.11.0/lib/node_modules/@openai/codex/bin/codex.js", OwnerNpm},
+		{"/home/dev/.nvm/versions/node/v20.11.0/bin/node", "/home/dev/.nvm/versions/node/v20.11.0/bin/node", OwnerNvm},
+		{"/home/dev/.asdf/shims/node", "/home/dev/.asdf/shims/node", OwnerAsdf},
+		{"/home/dev/.local/share/mise/shims/python3", "/home/dev/.local/share/mise/shims/python3", OwnerMise},
+		{"/home/dev/.volta/bin/node", "/home/dev/.volta/tools/image/node/20.11.0/bin/node", OwnerVolta},
+		{"/home/dev/.local/bin/claude", "/home/dev/.local/share/claude/versions/1.0.51", OwnerLocalBin},
+		{"/home/dev/.pyenv/shims/python3", "/home/dev/.pyenv/shims/python3", OwnerPyenv},
+		{"/home/dev/.rbenv/versions/3.3.0/bin/ruby", "/home/dev/.rbenv/versions/3.3.0/bin/ruby", OwnerRbenv},
+		{"/home/dev/.local/bin/black", "/home/dev/.local/share/pipx/venvs/black/bin/black", OwnerPipx},
+		{"/home/dev/.local/bin/toad", "/home/dev/.local/share/uv/tools/batrachian-toad/bin/toad", OwnerUv},
+		{"/home/dev/.cargo/bin/rg", "/home/dev/.cargo/bin/rg", OwnerCargo},
+		{"/home/dev/go/bin/gopls", "/home/dev/go/bin/gopls", OwnerGo},
+		{"/snap/bin/code", "/snap/bin/code", OwnerSnap},
+		{"/var/lib/flatpak/exports/bin/dev.zed.Zed", "/var/lib/flatpak/app/dev.zed.Zed/current/active/export/bin/dev.zed.Zed", OwnerFlatpak},
+		{"/home/dev/.local/bin/cursor", "/home/dev/Applications/Cursor-0.42.3-x86_64.AppImage", OwnerAppImage},
+		{"/usr/bin/git", "/usr/bin/git", OwnerSystem},
+		{"/bin/zsh", "/usr/bin/zsh", OwnerSystem},
+		{"/usr/local/bin/terraform", "/usr/local/bin/terraform", OwnerUnknown},
+	}
+	for _, tt := range tests {
+		if got := classifyBinary(tt.path, tt.resolved, home); got != tt.want {
+			t.Errorf("classifyBinary(%q, %q) = %q, want %q", tt.path, tt.resolved, got, tt.want)
+		}
+	}
+}
+
+func TestDetectProvenance(t *testing.T) {
+	r := NewFakeRunner()
+	d := newTestDetector(t, r)
+	target := filepath.Join(d.home, ".nvm", "versions", "node", "v20.11.0", "lib", "node_modules", "@openai", "codex", "bin", "codex.js")
+	link := filepath.Join(d.home, ".nvm", "versions", "node", "v20.11.0", "bin", "codex")
+	for _, dir := range []string{filepath.Dir(target), filepath.Dir(link)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(target, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	r.OnPath("codex", link)
+	target, _ = filepath.EvalSymlinks(target) // The temp dir itself may be behind a symlink
+
+	p := d.DetectProvenance(core.Tool{Binary: "codex"})
+	if p.Owner != OwnerNpm || p.Path != link || p.Resolved != target {
+		t.Errorf("DetectProvenance() = %+v, want npm owning %s", p, target)
+	}
+	if p := d.DetectProvenance(core.Tool{Binary: "missing"}); p.Owner != OwnerUnknown || p.Path != "" {
+		t.Errorf("DetectProvenance() of a missing binary = %+v, want nothing", p)
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	claude := core.Tool{Key: "claude", Binary: "claude", Package: "@anthropic-ai/claude-code", Method: core.MethodClaude}
+	jq := core.Tool{Key: "jq", Binary: "jq", Package: "jq", Method: core.MethodApt}
+	gemini := core.Tool{Key: "gemini", Binary: "gemini", Package: "gemini-cli", Method: core.MethodBrewPkg}
+	code := core.Tool{Key: "vscode", Binary: "code", Package: "visual-studio-code", Method: core.MethodMacApp}
+
+	tests := []struct {
+		name        string
+		state       core.ToolState
+		wantMethod  core.UpdateMethod
+		wantPackage string
+		wantErr     bool
+	}{
+		{
+			name:        "owner matches",
+			state:       core.ToolState{Tool: claude, Owner: "npm", BinaryPath: "/usr/local/lib/node_modules/@anthropic-ai/claude-code/cli.js"},
+			wantMethod:  core.MethodClaude,
+			wantPackage: "@anthropic-ai/claude-code",
+		},
+		{
+			name:        "unknown owner",
+			state:       core.ToolState{Tool: jq, BinaryPath: "/usr/local/bin/jq"},
+			wantMethod:  core.MethodApt,
+			wantPackage: "jq",
+		},
+		{
+			name:        "formula instead of apt",
+			state:       core.ToolState{Tool: jq, Owner: "brew", BinaryPath: "/home/linuxbrew/.linuxbrew/Cellar/jq/1.7.1/bin/jq"},
+			wantMethod:  core.MethodBrewPkg,
+			wantPackage: "jq",
+		},
+		{
+			name:        "scoped npm package instead of brew",
+			state:       core.ToolState{Tool: gemini, Owner: "npm", BinaryPath: "/opt/homebrew/lib/node_modules/@google/gemini-cli/dist/index.js"},
+			wantMethod:  core.MethodNpmPkg,
+			wantPackage: "@google/gemini-cli",
+		},
+		{
+			name:        "uv tool instead of brew",
+			state:       core.ToolState{Tool: core.Tool{Key: "ruff", Binary: "ruff", Package: "ruff", Method: core.MethodBrewPkg}, Owner: "uv", BinaryPath: "/home/dev/.local/share/uv/tools/ruff/bin/ruff"},
+			wantMethod:  core.MethodUv,
+			wantPackage: "ruff",
+		},
+		{
+			name:    "vendor installer",
+			state:   core.ToolState{Tool: claude, Owner: "local_bin", BinaryPath: "/home/dev/.local/share/claude/versions/1.0.51"},
+			wantErr: true,
+		},
+		{
+			name:        "snap instead of cask",
+			state:       core.ToolState{Tool: code, Owner: "snap", BinaryPath: "/snap/bin/code"},
+			wantMethod:  core.MethodSnap,
+			wantPackage: "code",
+		},
+		{
+			name:        "flatpak instead of cask",
+			state:       core.ToolState{Tool: code, Owner: "flatpak", BinaryPath: "/var/lib/flatpak/app/com.visualstudio.code/current/active/export/bin/com.visualstudio.code"},
+			wantMethod:  core.MethodFlatpak,
+			wantPackage: "com.visualstudio.code",
+		},
+		{
+			name:        "appimage instead of cask",
+			state:       core.ToolState{Tool: code, Owner: "appimage", BinaryPath: "/home/dev/Applications/Code-1.89.1.AppImage"},
+			wantMethod:  core.MethodAppImage,
+			wantPackage: "/home/dev/Applications/Code-1.89.1.AppImage",
+		},
+		{
+			name:    "distro package instead of cask",
+			state:   core.ToolState{Tool: code, Owner: "system", BinaryPath: "/usr/share/code/code"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Redirect(tt.state)
+			if tt.wantErr {
+				if !errors.Is(err, ErrOwnerMismatch) {
+					t.Fatalf("Redirect() error = %v, want ErrOwnerMismatch", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Method != tt.wantMethod || got.Package != tt.wantPackage {
+				t.Errorf("Redirect() = %s %s, want %s %s", got.Method, got.Package, tt.wantMethod, tt.wantPackage)
+			}
+		})
+	}
+
+	// Plans follow the redirect and refuse with the same reason
+	e := NewExecutorWithRunner(NewFakeRunner())
+	p := e.Plan(ActionUpgrade, tests[2].state)
+	if len(p.Steps) != 1 || p.Steps[0].Shell() != "brew upgrade jq" || p.Note == "" {
+		t.Errorf("redirected plan = %+v, want brew upgrade jq with a note", p)
+	}
+	if p := e.Plan(ActionUpgrade, tests[5].state); len(p.Steps) != 0 || p.Manual == "" {
+		t.Errorf("refused plan = %+v, want no steps and a reason", p)
+	}
+}
@@ -0,0 +1,237 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// Sources for versions looked up in package registries
+const (
+	SourceNpmRegistry   = "npm_registry"   // registry.npmjs.org /<pkg>/latest
+	SourcePyPI          = "pypi"           // pypi.org /pypi/<pkg>/json
+	SourceGitHubRelease = "github_release" // api.github.com /repos/<repo>/releases/latest
+	SourceHomebrewAPI   = "homebrew_api"   // formulae.brew.sh /api/{formula,cask}/<name>.json
+	SourceCratesIO      = "crates_io"      // crates.io /api/v1/crates/<name>
+	SourceGoProxy       = "goproxy"        // proxy.golang.org /<module>/@latest
+)
+
+// registryTimeout bounds one HTTP lookup so a slow registry cannot stall the grid
+const registryTimeout = 10 * time.Second
+
+// RegistryURLs are the base URLs of the registries. An empty URL disables
+// that registry; tests point them at an httptest server.
+type RegistryURLs struct {
+	Npm      string
+	PyPI     string
+	GitHub   string
+	Homebrew string
+	Crates   string
+	GoProxy  string
+}
+
+// Registries looks up latest released versions over HTTP
+type Registries struct {
+	urls   RegistryURLs
+	client *http.Client
+	token  string // GITHUB_TOKEN, raises the GitHub API rate limit
+}
+
+// NewRegistries returns registries at urls using a client with a short timeout
+func NewRegistries(urls RegistryURLs) *Registries {
+	return &Registries{
+		urls:   urls,
+		client: &http.Client{Timeout: registryTimeout},
+		token:  os.Getenv("GITHUB_TOKEN"),
+	}
+}
+
+// errNoRegistry means a registry kind has no base URL configured
+var errNoRegistry = errors.New("registry not configured")
+
+// errNotFound is a 404 or 410: the registry does not know the name
+var errNotFound = errors.New("not found")
+
+// Latest returns the latest released version of ref ("kind:name") and its source
+func (r *Registries) Latest(ctx context.Context, ref string) (version, source string, err error) {
+	kind, name, err := core.ParseRegistry(ref)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch kind {
+	case core.RegistryNpm:
+		var doc struct {
+			Version string `json:"version"`
+		}
+		err = r.getJSON(ctx, r.urls.Npm, name+"/latest", &doc)
+		return doc.Version, SourceNpmRegistry, r.check(ref, doc.Version, err)
+
+	case core.RegistryPyPI:
+		var doc struct {
+			Info struct {
+				Version string `json:"version"`
+			} `json:"info"`
+		}
+		err = r.getJSON(ctx, r.urls.PyPI, "pypi/"+url.PathEscape(name)+"/json", &doc)
+		return doc.Info.Version, SourcePyPI, r.check(ref, doc.Info.Version, err)
+
+	case core.RegistryGitHub:
+		var doc struct {
+			TagName string `json:"tag_name"`
+		}
+		err = r.getJSON(ctx, r.urls.GitHub, "repos/"+name+"/releases/latest", &doc)
+		return CleanVersionString(doc.TagName), SourceGitHubRelease, r.check(ref, doc.TagName, err)
+
+	case core.RegistryBrew:
+		var doc struct {
+			Versions struct {
+				Stable string `json:"stable"`
+			} `json:"versions"`
+		}
+		err = r.getJSON(ctx, r.urls.Homebrew, "api/formula/"+url.PathEscape(name)+".json", &doc)
+		return doc.Versions.Stable, SourceHomebrewAPI, r.check(ref, doc.Versions.Stable, err)
+
+	case core.RegistryCask:
+		var doc struct {
+			Version string `json:"version"`
+		}
+		err = r.getJSON(ctx, r.urls.Homebrew, "api/cask/"+url.PathEscape(name)+".json", &doc)
+		// Casks may append a build after a comma: "1.2.3,4567"
+		version, _, _ = strings.Cut(doc.Version, ",")
+		return version, SourceHomebrewAPI, r.check(ref, version, err)
+
+	case core.RegistryCrates:
+		var doc struct {
+			Crate struct {
+				MaxStableVersion string `json:"max_stable_version"`
+			} `json:"crate"`
+		}
+		err = r.getJSON(ctx, r.urls.Crates, "api/v1/crates/"+url.PathEscape(name), &doc)
+		return doc.Crate.MaxStableVersion, SourceCratesIO, r.check(ref, doc.Crate.MaxStableVersion, err)
+
+	case core.RegistryGo:
+		version, err = r.goLatest(ctx, name)
+		return version, SourceGoProxy, r.check(ref, version, err)
+	}
+	return "", "", fmt.Errorf("registry %q: unsupported kind", ref)
+}
+
+// goLatest asks the module proxy for the latest version of the module that
+// provides pkg. `go install` takes package paths, so like the go command it
+// tries pkg and then each parent until the proxy knows one.
+func (r *Registries) goLatest(ctx context.Context, pkg string) (string, error) {
+	var err error
+	for mod := pkg; strings.Contains(mod, "/"); mod = path.Dir(mod) {
+		var doc struct {
+			Version string `json:"Version"`
+		}
+		if err = r.getJSON(ctx, r.urls.GoProxy, escapeModulePath(mod)+"/@latest", &doc); err == nil {
+			return CleanVersionString(doc.Version), nil
+		}
+		if !errors.Is(err, errNotFound) {
+			return "", err
+		}
+	}
+	return "", err
+}
+
+// escapeModulePath encodes uppercase letters the way module proxies expect:
+// "github.com/BurntSushi/toml" becomes "github.com/!burnt!sushi/toml"
+func escapeModulePath(mod string) string {
+	var b strings.Builder
+	for _, c := range mod {
+		if c >= 'A' && c <= 'Z' {
+			b.WriteByte('!')
+			c += 'a' - 'A'
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// check turns an empty answer into an error so callers only test err
+func (r *Registries) check(ref, version string, err error) error {
+	if err != nil {
+		return fmt.Errorf("%s: %w", ref, err)
+	}
+	if version == "" {
+		return fmt.Errorf("%s: no version in response", ref)
+	}
+	return nil
+}
+
+// getJSON decodes the JSON document at base/path into v
+func (r *Registries) getJSON(ctx context.Context, base, path string, v any) error {
+	if base == "" {
+		return errNoRegistry
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, registryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(base, "/")+"/"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "spark")
+	if r.token != "" && base == r.urls.GitHub {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return fmt.Errorf("GET %s: %s: %w", req.URL, resp.Status, errNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", req.URL, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// registryNamer is implemented by strategies whose installed files name
+// where their releases are published, for tools the inventory gives no registry
+type registryNamer interface {
+	Registry(d *Detector, t core.Tool) string
+}
+
+// registryFor returns where t's latest release is published. explicit is true
+// when the inventory names it; otherwise it is derived from the update method.
+func registryFor(t core.Tool) (ref string, explicit bool) {
+	if t.Registry != "" {
+		return t.Registry, true
+	}
+	if t.Package == "" {
+		return "", false
+	}
+	switch t.Method {
+	case core.MethodNpmPkg, core.MethodNpmSys, core.MethodClaude:
+		return core.RegistryNpm + ":" + t.Package, false
+	case core.MethodBrew, core.MethodBrewPkg:
+		return core.RegistryBrew + ":" + t.Package, false
+	case core.MethodMacApp:
+		return core.RegistryCask + ":" + t.Package, false
+	case core.MethodPipx, core.MethodUv:
+		return core.RegistryPyPI + ":" + t.Package, false
+	case core.MethodCargo:
+		return core.RegistryCrates + ":" + t.Package, false
+	case core.MethodGoInstall:
+		return core.RegistryGo + ":" + t.Package, false
+	}
+	return "", false
+}
@@ -0,0 +1,168 @@
+package updater
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// newRegistryStub serves canned JSON documents by request path and counts requests
+func newRegistryStub(t *testing.T, docs map[string]string) (*Registries, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		doc, ok := docs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(doc))
+	}))
+	t.Cleanup(srv.Close)
+
+	return NewRegistries(RegistryURLs{
+		Npm:      srv.URL,
+		PyPI:     srv.URL + "/pypi-mirror",
+		GitHub:   srv.URL + "/gh/",
+		Homebrew: srv.URL,
+		Crates:   srv.URL,
+		GoProxy:  srv.URL + "/goproxy",
+	}), &hits
+}
+
+func TestRegistriesLatest(t *testing.T) {
+	r, _ := newRegistryStub(t, map[string]string{
+		"/@openai/codex/latest":                         `{"name": "@openai/codex", "version": "0.5.0"}`,
+		"/pypi-mirror/pypi/batrachian-toad/json":        `{"info": {"version": "0.5.2"}}`,
+		"/gh/repos/ollama/ollama/releases/latest":       `{"tag_name": "v0.9.1"}`,
+		"/api/formula/jq.json":                          `{"versions": {"stable": "1.7.1"}}`,
+		"/api/cask/ghostty.json":                        `{"version": "1.1.3,b4c7d2"}`,
+		"/gh/repos/empty/release/releases/latest":       `{}`,
+		"/api/v1/crates/ripgrep":                        `{"crate": {"max_stable_version": "14.1.0", "max_version": "15.0.0-rc.1"}}`,
+		"/goproxy/github.com/!burnt!sushi/toml/@latest": `{"Version": "v1.4.0", "Time": "2024-06-12T00:00:00Z"}`,
+		"/goproxy/honnef.co/go/tools/@latest":           `{"Version": "v0.4.7"}`,
+	})
+
+	tests := []struct {
+		ref        string
+		wantVer    string
+		wantSource string
+		wantErr    string
+	}{
+		{"npm:@openai/codex", "0.5.0", SourceNpmRegistry, ""},
+		{"pypi:batrachian-toad", "0.5.2", SourcePyPI, ""},
+		{"github:ollama/ollama", "0.9.1", SourceGitHubRelease, ""},
+		{"brew:jq", "1.7.1", SourceHomebrewAPI, ""},
+		{"cask:ghostty", "1.1.3", SourceHomebrewAPI, ""},
+		{"crates:ripgrep", "14.1.0", SourceCratesIO, ""},
+		{"go:github.com/BurntSushi/toml", "1.4.0", SourceGoProxy, ""},
+		{"go:honnef.co/go/tools/cmd/staticcheck", "0.4.7", SourceGoProxy, ""},
+		{"go:example.com/gone/tool", "", "", "404"},
+		{"brew:nope", "", "", "404"},
+		{"github:empty/release", "", "", "no version"},
+		{"apt:jq", "", "", "unknown kind"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			ver, source, err := r.Latest(context.Background(), tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Latest() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ver != tt.wantVer || source != tt.wantSource {
+				t.Errorf("Latest() = (%q, %q), want (%q, %q)", ver, source, tt.wantVer, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestRegistriesDisabled(t *testing.T) {
+	r := NewRegistries(RegistryURLs{})
+	if _, _, err := r.Latest(context.Background(), "npm:left-pad"); err == nil {
+		t.Error("Latest() with no npm URL succeeded")
+	}
+}
+
+func TestDetectRemoteFromRegistries(t *testing.T) {
+	reg, hits := newRegistryStub(t, map[string]string{
+		"/pypi-mirror/pypi/batrachian-toad/json":  `{"info": {"version": "0.6.0"}}`,
+		"/@google/gemini-cli/latest":              `{"version": "0.2.0"}`,
+		"/gh/repos/ollama/ollama/releases/latest": `{"tag_name": "v0.9.1"}`,
+	})
+	r := NewFakeRunner().
+		On("brew outdated --json=v2", exitWithStdout(1, readFixture(t, "brew_outdated.json"))).
+		On("npm outdated -g --json", Stdout("{}"))
+	d := newTestDetector(t, r)
+	d.UseRegistries(reg)
+	d.WarmUpCache()
+
+	tests := []struct {
+		name       string
+		tool       core.Tool
+		local      string
+		wantVer    string
+		wantSource string
+	}{
+		{"curl install names its registry", core.Tool{Package: "batrachian-toad", Method: core.MethodToad, Registry: "pypi:batrachian-toad"}, "0.5.2", "0.6.0", SourcePyPI},
+		{"missing npm tool", core.Tool{Package: "@google/gemini-cli", Method: core.MethodNpmPkg}, "MISSING", "0.2.0", SourceNpmRegistry},
+		{"installed npm tool keeps npm's answer", core.Tool{Package: "@google/gemini-cli", Method: core.MethodNpmPkg}, "0.1.0", "0.1.0", SourceNotOutdated},
+		{"outdated list wins over the registry", core.Tool{Package: "fzf", Method: core.MethodBrew, Registry: "github:junegunn/fzf"}, "0.45.0", "0.46.0", SourceBrewOutdated},
+		{"registry error falls back", core.Tool{Package: "antigravity", Method: core.MethodManual, Registry: "github:google/antigravity"}, "1.0.0", "1.0.0", SourceNotOutdated},
+		{"missing without registry", core.Tool{Package: "jq", Method: core.MethodApt}, "MISSING", "Unknown", SourceNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ver, source := d.DetectRemote(tt.tool, tt.local)
+			if ver != tt.wantVer || source != tt.wantSource {
+				t.Errorf("DetectRemote() = (%q, %q), want (%q, %q)", ver, source, tt.wantVer, tt.wantSource)
+			}
+		})
+	}
+
+	// Each reference is fetched once per run
+	before := hits.Load()
+	d.DetectRemote(core.Tool{Method: core.MethodToad, Registry: "pypi:batrachian-toad"}, "0.5.2")
+	if hits.Load() != before {
+		t.Error("registry queried again for a reference already looked up")
+	}
+}
+
+func TestRegistryLookupsAreCachedOnDisk(t *testing.T) {
+	reg, hits := newRegistryStub(t, map[string]string{
+		"/gh/repos/ollama/ollama/releases/latest": `{"tag_name": "v0.9.1"}`,
+	})
+	cache := newTestCache(t, time.Hour)
+	ollama := core.Tool{Method: core.MethodManual, Registry: "github:ollama/ollama"}
+
+	d := newTestDetector(t, NewFakeRunner())
+	d.UseCache(cache)
+	d.UseRegistries(reg)
+	d.DetectRemote(ollama, "0.9.0")
+	if err := d.SaveCache(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new run within the TTL answers from disk
+	next := newTestDetector(t, NewFakeRunner())
+	next.UseCache(OpenCache(cache.path, time.Hour))
+	next.UseRegistries(reg)
+	if ver, source := next.DetectRemote(ollama, "0.9.0"); ver != "0.9.1" || source != SourceGitHubRelease {
+		t.Errorf("DetectRemote() = (%q, %q), want (0.9.1, %q)", ver, source, SourceGitHubRelease)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("registry hit %d times, want 1", got)
+	}
+}
@@ -0,0 +1,261 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// rollbackSchemaVersion is bumped when the file layout changes; older files are discarded
+const rollbackSchemaVersion = 1
+
+// ErrNoRollback is returned by RecordRollback when t's method has no way back
+// to an older version (casks, vendor scripts, manual installs)
+var ErrNoRollback = errors.New("rollback not supported")
+
+// RollbackStep is one command of a rollback recipe
+type RollbackStep struct {
+	Args       []string `json:"args"`
+	Privileged bool     `json:"privileged,omitempty"` // Run through sudo -n, see privileged
+}
+
+func (s RollbackStep) String() string {
+	cmd := Command{Name: s.Args[0], Args: s.Args[1:]}.String()
+	if s.Privileged {
+		return sudoPrefix() + cmd
+	}
+	return cmd
+}
+
+// Rollback is the recipe restoring the version a tool had before its last
+// upgrade, recorded just before the upgrade ran
+type Rollback struct {
+	Key        string         `json:"key"`
+	Name       string         `json:"name"`
+	Method     string         `json:"method"`
+	Version    string         `json:"version"` // As the package manager names it
+	Steps      []RollbackStep `json:"steps"`
+	RecordedAt time.Time      `json:"recorded_at"`
+}
+
+// String is the recipe as shown in logs and confirmations
+func (r Rollback) String() string {
+	parts := make([]string, len(r.Steps))
+	for i, s := range r.Steps {
+		parts[i] = s.String()
+	}
+	return strings.Join(parts, " && ")
+}
+
+// rollbacker is implemented by strategies whose package manager can install
+// an older version. RollbackSteps reads the installed version from the
+// package manager itself, since that is the name it reinstalls by.
+type rollbacker interface {
+	RollbackSteps(ctx context.Context, e *Executor, t core.Tool) (version string, steps []RollbackStep, err error)
+}
+
+// Rollbacks keeps the latest rollback recipe of each tool on disk. Unlike the
+// version cache it is state: --refresh never clears it.
+type Rollbacks struct {
+	path string
+	now  func() time.Time
+
+	mu   sync.Mutex
+	data rollbackData
+}
+
+type rollbackData struct {
+	SchemaVersion int                 `json:"schema_version"`
+	Tools         map[string]Rollback `json:"tools"` // Tool key -> recipe
+}
+
+// NewRollbacks returns an empty store that will be saved to path
+func NewRollbacks(path string) *Rollbacks {
+	return &Rollbacks{
+		path: path,
+		now:  time.Now,
+		data: rollbackData{
+			SchemaVersion: rollbackSchemaVersion,
+			Tools:         make(map[string]Rollback),
+		},
+	}
+}
+
+// OpenRollbacks loads the store at path. A missing file gives an empty store;
+// an unreadable one is an error so that saving never overwrites it.
+func OpenRollbacks(path string) (*Rollbacks, error) {
+	r := NewRollbacks(path)
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var data rollbackData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if data.SchemaVersion == rollbackSchemaVersion && data.Tools != nil {
+		r.data.Tools = data.Tools
+	}
+	return r, nil
+}
+
+// Get returns the recipe recorded for the tool with key. A nil store has none.
+func (r *Rollbacks) Get(key string) (Rollback, bool) {
+	if r == nil {
+		return Rollback{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rb, ok := r.data.Tools[key]
+	return rb, ok
+}
+
+// List returns every recorded recipe, most recent first
+func (r *Rollbacks) List() []Rollback {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	list := make([]Rollback, 0, len(r.data.Tools))
+	for _, rb := range r.data.Tools {
+		list = append(list, rb)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].RecordedAt.Equal(list[j].RecordedAt) {
+			return list[i].RecordedAt.After(list[j].RecordedAt)
+		}
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
+
+func (r *Rollbacks) set(rb Rollback) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rb.RecordedAt = r.now()
+	r.data.Tools[rb.Key] = rb
+}
+
+func (r *Rollbacks) delete(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.data.Tools, key)
+}
+
+// Save writes the store atomically, creating its directory if needed. The
+// lock is held throughout so concurrent upgrades cannot save out of order.
+func (r *Rollbacks) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	raw, err := json.MarshalIndent(r.data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), ".rollbacks-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// UseRollbacks makes the Executor record rollbacks in r and run them with ActionRollback
+func (e *Executor) UseRollbacks(r *Rollbacks) {
+	e.rollbacks = r
+}
+
+// PendingRollback is a recipe captured right before an upgrade, see Keep
+type PendingRollback struct {
+	store *Rollbacks
+	rb    Rollback
+}
+
+// RecordRollback captures the recipe that brings t back to its installed
+// version. Call it right before upgrading t and Keep the result once the
+// upgrade is done; until then the store is left alone. Without a store it
+// returns nil, which keeps nothing.
+func (e *Executor) RecordRollback(ctx context.Context, t core.Tool) (*PendingRollback, error) {
+	if e.rollbacks == nil {
+		return nil, nil
+	}
+	s, ok := StrategyFor(t.Method)
+	if !ok {
+		return nil, ErrNoRollback
+	}
+	rb, ok := s.(rollbacker)
+	if !ok {
+		return nil, ErrNoRollback
+	}
+
+	version, steps, err := rb.RollbackSteps(ctx, e, t)
+	if err != nil {
+		return nil, fmt.Errorf("could not record rollback: %w", err)
+	}
+	return &PendingRollback{store: e.rollbacks, rb: Rollback{
+		Key:     t.Key,
+		Name:    t.Name,
+		Method:  string(t.Method),
+		Version: version,
+		Steps:   steps,
+	}}, nil
+}
+
+// Keep saves the recipe in place of any older one when the upgrade succeeded
+// (upgradeErr is nil) and moved the installed version from before to after.
+// A failed or no-op upgrade leaves the older recipe, which still leads back
+// past the last real change. It reports whether the recipe was saved.
+func (p *PendingRollback) Keep(upgradeErr error, before, after string) (bool, error) {
+	if p == nil || upgradeErr != nil || !IsKnownVersion(after) || after == before {
+		return false, nil
+	}
+	p.store.set(p.rb)
+	return true, p.store.Save()
+}
+
+// rollback runs t's recorded recipe and forgets it once it succeeded
+func (e *Executor) rollback(ctx context.Context, t core.Tool) error {
+	rb, ok := e.rollbacks.Get(t.Key)
+	if !ok {
+		return fmt.Errorf("no rollback recorded for %s", t.Name)
+	}
+	for _, step := range rb.Steps {
+		var err error
+		if step.Privileged {
+			err = e.runPrivileged(ctx, "rollback", step.Args...)
+		} else {
+			err = e.run(ctx, "rollback", step.Args[0], step.Args[1:]...)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	e.rollbacks.delete(t.Key)
+	return e.rollbacks.Save()
+}
@@ -0,0 +1,240 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func newTestRollbacks(t *testing.T) *Rollbacks {
+	t.Helper()
+	return NewRollbacks(filepath.Join(t.TempDir(), "spark", "rollbacks.json"))
+}
+
+func TestRecordRollback(t *testing.T) {
+	t.Setenv("ZSH", "")
+	tests := []struct {
+		name        string
+		tool        core.Tool
+		query       string
+		result      Result
+		wantVersion string
+		wantRecipe  string
+	}{
+		{
+			name:        "npm",
+			tool:        core.Tool{Key: "gemini", Name: "Gemini CLI", Package: "@google/gemini-cli", Method: core.MethodNpmPkg},
+			query:       "npm ls -g @google/gemini-cli --depth=0 --json",
+			result:      Stdout(`{"dependencies": {"@google/gemini-cli": {"version": "0.9.0"}}}`),
+			wantVersion: "0.9.0",
+			wantRecipe:  "npm install -g @google/gemini-cli@0.9.0",
+		},
+		{
+			name:        "brew drops the bottle revision",
+			tool:        core.Tool{Key: "jq", Name: "jq", Package: "jq", Method: core.MethodBrew},
+			query:       "brew list --versions jq",
+			result:      Stdout("jq 1.7.1_1\n"),
+			wantVersion: "1.7.1_1",
+			wantRecipe: "sh -c brew tap | grep -qx spark/rollback || brew tap-new --no-git spark/rollback" +
+				" && brew extract --force --version=1.7.1 jq spark/rollback" +
+				" && brew unlink jq && brew install spark/rollback/jq@1.7.1",
+		},
+		{
+			name:        "omz",
+			tool:        core.Tool{Key: "omz", Name: "Oh My Zsh", Method: core.MethodOmz},
+			query:       "git -C /home/dev/.oh-my-zsh rev-parse --short HEAD",
+			result:      Stdout("abc123f\n"),
+			wantVersion: "abc123f",
+			wantRecipe:  "git -C /home/dev/.oh-my-zsh reset --keep abc123f",
+		},
+		{
+			name:        "apt keeps the epoch",
+			tool:        core.Tool{Key: "git", Name: "Git", Package: "git", Method: core.MethodApt},
+			query:       "dpkg-query -W -f=${Version} git",
+			result:      Stdout("1:2.43.0-1ubuntu7"),
+			wantVersion: "1:2.43.0-1ubuntu7",
+			wantRecipe:  sudoPrefix() + "apt-get install -y -q --allow-downgrades git=1:2.43.0-1ubuntu7",
+		},
+		{
+			name:        "dnf",
+			tool:        core.Tool{Key: "jq", Name: "jq", Package: "jq", Method: core.MethodDnf},
+			query:       "rpm -q jq",
+			result:      Stdout("jq-1.7.1-1.fc39.x86_64\n"),
+			wantVersion: "1.7.1-1.fc39",
+			wantRecipe:  sudoPrefix() + "dnf downgrade -y jq-1.7.1-1.fc39.x86_64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestRollbacks(t)
+			e := NewExecutorWithRunner(NewFakeRunner().On(tt.query, tt.result))
+			e.home = "/home/dev"
+			e.UseRollbacks(store)
+
+			pending, err := e.RecordRollback(context.Background(), tt.tool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := store.Get(tt.tool.Key); ok {
+				t.Fatal("rollback saved before the upgrade")
+			}
+			if kept, err := pending.Keep(nil, tt.wantVersion, "99.0.0"); !kept || err != nil {
+				t.Fatalf("Keep() = (%v, %v), want the recipe saved", kept, err)
+			}
+			rb, ok := store.Get(tt.tool.Key)
+			if !ok {
+				t.Fatal("no rollback recorded")
+			}
+			if rb.Version != tt.wantVersion || rb.String() != tt.wantRecipe {
+				t.Errorf("recorded (%q, %q), want (%q, %q)", rb.Version, rb.String(), tt.wantVersion, tt.wantRecipe)
+			}
+			if _, err := os.Stat(store.path); err != nil {
+				t.Errorf("store not saved: %v", err)
+			}
+		})
+	}
+}
+
+func TestRecordRollbackUnsupported(t *testing.T) {
+	e := NewExecutorWithRunner(NewFakeRunner())
+	e.UseRollbacks(newTestRollbacks(t))
+
+	cask := core.Tool{Key: "zed", Package: "zed", Method: core.MethodMacApp}
+	if _, err := e.RecordRollback(context.Background(), cask); !errors.Is(err, ErrNoRollback) {
+		t.Errorf("RecordRollback(cask) error = %v, want ErrNoRollback", err)
+	}
+
+	// brew list fails: the tool is not a formula after all
+	jq := core.Tool{Key: "jq", Package: "jq", Method: core.MethodBrew}
+	if _, err := e.RecordRollback(context.Background(), jq); err == nil || errors.Is(err, ErrNoRollback) {
+		t.Errorf("RecordRollback(jq) error = %v, want a query failure", err)
+	}
+}
+
+func TestRecordRollbackOmzDir(t *testing.T) {
+	// $ZSH wins over ~/.oh-my-zsh and stays one argument, spaces and all
+	dir := "/opt/my zsh/oh-my-zsh"
+	t.Setenv("ZSH", dir)
+	store := newTestRollbacks(t)
+	e := NewExecutorWithRunner(NewFakeRunner().On("git -C "+dir+" rev-parse --short HEAD", Stdout("abc123f\n")))
+	e.UseRollbacks(store)
+
+	omz := core.Tool{Key: "omz", Name: "Oh My Zsh", Method: core.MethodOmz}
+	pending, err := e.RecordRollback(context.Background(), omz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pending.Keep(nil, "abc123f", "def4567"); err != nil {
+		t.Fatal(err)
+	}
+	rb, _ := store.Get(omz.Key)
+	if want := []string{"git", "-C", dir, "reset", "--keep", "abc123f"}; len(rb.Steps) != 1 || !reflect.DeepEqual(rb.Steps[0].Args, want) {
+		t.Errorf("recipe = %+v, want %q", rb.Steps, want)
+	}
+}
+
+func TestRollbackKeptOnlyAfterAnUpgrade(t *testing.T) {
+	// node went 20 -> 22 earlier; the recipe back to 20 must survive
+	// anything but another upgrade that really changes the version
+	node := core.Tool{Key: "node", Name: "Node.js", Package: "node", Method: core.MethodBrew}
+	tests := []struct {
+		name          string
+		upgradeErr    error
+		before, after string
+		wantVersion   string
+	}{
+		{"failed upgrade", errors.New("brew upgrade failed"), "22.1.0", "", "20.11.1"},
+		{"already up to date", nil, "22.1.0", "22.1.0", "20.11.1"},
+		{"version no longer detected", nil, "22.1.0", "MISSING", "20.11.1"},
+		{"upgraded", nil, "22.1.0", "22.2.0", "22.1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestRollbacks(t)
+			store.set(Rollback{Key: "node", Name: "Node.js", Version: "20.11.1", Steps: []RollbackStep{{Args: []string{"brew", "install", "node@20"}}}})
+			e := NewExecutorWithRunner(NewFakeRunner().On("brew list --versions node", Stdout("node 22.1.0\n")))
+			e.UseRollbacks(store)
+
+			pending, err := e.RecordRollback(context.Background(), node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			kept, err := pending.Keep(tt.upgradeErr, tt.before, tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rb, _ := store.Get("node"); rb.Version != tt.wantVersion || kept != (tt.wantVersion == "22.1.0") {
+				t.Errorf("Keep() = %v, recipe back to %q, want %q", kept, rb.Version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestRollbackRunsRecipe(t *testing.T) {
+	tool := core.Tool{Key: "gemini", Name: "Gemini CLI", Package: "@google/gemini-cli", Method: core.MethodNpmPkg}
+	r := NewFakeRunner().
+		On("npm ls -g @google/gemini-cli --depth=0 --json", Stdout(`{"dependencies": {"@google/gemini-cli": {"version": "0.9.0"}}}`)).
+		On("npm install -g @google/gemini-cli@0.9.0", Stdout(""))
+	store := newTestRollbacks(t)
+	e := NewExecutorWithRunner(r)
+	e.UseRollbacks(store)
+
+	if err := e.Rollback(context.Background(), tool); err == nil || !strings.Contains(err.Error(), "no rollback recorded") {
+		t.Fatalf("Rollback before recording error = %v", err)
+	}
+	pending, err := e.RecordRollback(context.Background(), tool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pending.Keep(nil, "0.9.0", "0.10.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Rollback(context.Background(), tool); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := r.Calls()
+	if last := calls[len(calls)-1]; last != "npm install -g @google/gemini-cli@0.9.0" {
+		t.Errorf("last call = %q, want the recorded install", last)
+	}
+	loaded, err := OpenRollbacks(store.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded.Get(tool.Key); ok {
+		t.Error("rollback still recorded after it ran")
+	}
+}
+
+func TestOpenRollbacks(t *testing.T) {
+	store := newTestRollbacks(t)
+	store.set(Rollback{Key: "jq", Name: "jq", Version: "1.7.1", Steps: []RollbackStep{{Args: []string{"brew", "install", "jq"}}}})
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := OpenRollbacks(store.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := loaded.List(); len(list) != 1 || list[0].Version != "1.7.1" {
+		t.Errorf("List() = %+v, want the jq recipe", list)
+	}
+
+	if _, err := OpenRollbacks(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("OpenRollbacks(missing) error = %v", err)
+	}
+	garbage := filepath.Join(t.TempDir(), "garbage.json")
+	if err := os.WriteFile(garbage, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenRollbacks(garbage); err == nil {
+		t.Error("OpenRollbacks(garbage) succeeded; saving would overwrite the file")
+	}
+}
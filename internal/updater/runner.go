@@ -0,0 +1,85 @@
+package updater
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Command is one external program invocation
+type Command struct {
+	Name string
+	Args []string
+	Env  []string // Extra KEY=value pairs on top of the current environment
+
+	// OnLine, when set, receives stdout/stderr line by line as the command runs.
+	// Result still carries the complete output.
+	OnLine LineFunc
+}
+
+// String renders the command line ("brew upgrade jq"); FakeRunner keys on it
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// Result is what a finished command produced. Err is non-nil when the command
+// could not start or exited non-zero, mirroring exec.Cmd.Run.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Err      error
+}
+
+// Combined returns stdout followed by stderr, like exec.Cmd.CombinedOutput
+func (r Result) Combined() string {
+	return r.Stdout + r.Stderr
+}
+
+// CommandRunner is the only way the Detector and Executor reach the outside
+// world, so tests can swap in a FakeRunner instead of real brew/npm.
+type CommandRunner interface {
+	Run(ctx context.Context, c Command) Result
+	LookPath(name string) (string, error)
+}
+
+// ExecRunner runs commands with os/exec
+type ExecRunner struct{}
+
+func (ExecRunner) Run(ctx context.Context, c Command) Result {
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	release := setProcessGroup(cmd)
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	var stdout, stderr bytes.Buffer
+	if c.OnLine == nil {
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+	} else {
+		outLines, errLines := newLineWriters(c.OnLine)
+		defer outLines.Flush()
+		defer errLines.Flush()
+		cmd.Stdout = io.MultiWriter(&stdout, outLines)
+		cmd.Stderr = io.MultiWriter(&stderr, errLines)
+	}
+
+	err := cmd.Run()
+	release()
+	res := Result{Stdout: stdout.String(), Stderr: stderr.String(), Err: err}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		res.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		res.ExitCode = -1
+	}
+	return res
+}
+
+func (ExecRunner) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
@@ -0,0 +1,115 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+)
+
+// FakeRunner replays scripted results and records every invocation.
+// Commands with an OnLine callback get their scripted stdout then stderr
+// replayed line by line.
+// Commands are matched on their full command line (see Command.String).
+// Several results queued for the same command are returned in order, the
+// last one repeating; unscripted commands fail as if not installed.
+// updatertest.FakeRunner is the same fake for tests of other packages.
+type FakeRunner struct {
+	mu        sync.Mutex
+	responses map[string][]Result
+	paths     map[string]string
+	calls     []Command
+}
+
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{
+		responses: make(map[string][]Result),
+		paths:     make(map[string]string),
+	}
+}
+
+// On queues results for a command line such as "brew outdated --json=v2"
+func (f *FakeRunner) On(cmdline string, results ...Result) *FakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[cmdline] = append(f.responses[cmdline], results...)
+	return f
+}
+
+// OnPath makes LookPath find name at path
+func (f *FakeRunner) OnPath(name, path string) *FakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paths[name] = path
+	return f
+}
+
+func (f *FakeRunner) Run(ctx context.Context, c Command) Result {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, c)
+
+	// A cancelled context kills real commands before they produce anything
+	if err := ctx.Err(); err != nil {
+		return Result{ExitCode: -1, Err: err}
+	}
+
+	res := f.next(c)
+	if c.OnLine != nil {
+		outLines, errLines := newLineWriters(c.OnLine)
+		_, _ = outLines.Write([]byte(res.Stdout))
+		outLines.Flush()
+		_, _ = errLines.Write([]byte(res.Stderr))
+		errLines.Flush()
+	}
+	return res
+}
+
+func (f *FakeRunner) next(c Command) Result {
+	queue := f.responses[c.String()]
+	switch len(queue) {
+	case 0:
+		return Result{ExitCode: 127, Err: fmt.Errorf("%s: %w", c.Name, exec.ErrNotFound)}
+	case 1:
+		return queue[0]
+	}
+	f.responses[c.String()] = queue[1:]
+	return queue[0]
+}
+
+func (f *FakeRunner) LookPath(name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if path, ok := f.paths[name]; ok {
+		return path, nil
+	}
+	return "", fmt.Errorf("%s: %w", name, exec.ErrNotFound)
+}
+
+// Calls returns the command lines run so far, in order
+func (f *FakeRunner) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	lines := make([]string, len(f.calls))
+	for i, c := range f.calls {
+		lines[i] = c.String()
+	}
+	return lines
+}
+
+// Invocations returns the recorded commands including their environment
+func (f *FakeRunner) Invocations() []Command {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Command(nil), f.calls...)
+}
+
+// Stdout is a successful result printing out
+func Stdout(out string) Result {
+	return Result{Stdout: out}
+}
+
+// Failure is a result exiting with code and printing stderr
+func Failure(code int, stderr string) Result {
+	return Result{Stderr: stderr, ExitCode: code, Err: fmt.Errorf("exit status %d", code)}
+}
@@ -0,0 +1,96 @@
+package updater
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// Job is one tool queued for an upgrade or install. Index is the caller's identifier
+// (the dashboard row), passed back untouched.
+type Job struct {
+	Index  int
+	Tool   core.Tool
+	Action Action // Zero value is ActionUpgrade
+}
+
+// Scheduler runs update jobs on a pool of workers. Jobs whose strategies
+// report the same LockKey never overlap, so brew and npm upgrade side by
+// side while two brew upgrades wait for each other.
+type Scheduler struct {
+	workers int
+}
+
+func NewScheduler(workers int) *Scheduler {
+	return &Scheduler{workers: max(workers, 1)}
+}
+
+// Run calls do for every job and returns once all started jobs finished.
+// Jobs start in queue order, except that a job waiting for a lock lets later
+// jobs with a free lock go first. Once ctx is cancelled no new job starts;
+// those jobs are returned as skipped.
+func (s *Scheduler) Run(ctx context.Context, jobs []Job, do func(Job)) (skipped []Job) {
+	var mu sync.Mutex
+	cond := sync.NewCond(&mu)
+	pending := append([]Job(nil), jobs...)
+	held := make(map[string]bool)
+
+	// Wake idle workers so they notice the cancellation
+	stop := context.AfterFunc(ctx, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		cond.Broadcast()
+	})
+	defer stop()
+
+	next := func() (Job, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		for {
+			if ctx.Err() != nil || len(pending) == 0 {
+				return Job{}, false
+			}
+			for i, j := range pending {
+				key := LockKey(j.Tool)
+				if key != "" && held[key] {
+					continue
+				}
+				if key != "" {
+					held[key] = true
+				}
+				pending = append(pending[:i], pending[i+1:]...)
+				return j, true
+			}
+			cond.Wait()
+		}
+	}
+
+	release := func(j Job) {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(held, LockKey(j.Tool))
+		cond.Broadcast()
+	}
+
+	var wg sync.WaitGroup
+	for range min(s.workers, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				j, ok := next()
+				if !ok {
+					return
+				}
+				do(j)
+				release(j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	return pending
+}
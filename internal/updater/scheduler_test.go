@@ -0,0 +1,91 @@
+package updater
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestSchedulerSerializesSharedLocks(t *testing.T) {
+	jobs := []Job{
+		{Index: 0, Tool: core.Tool{Package: "jq", Method: core.MethodBrew}},
+		{Index: 1, Tool: core.Tool{Package: "fzf", Method: core.MethodBrewPkg}},
+		{Index: 2, Tool: core.Tool{Package: "ghostty", Method: core.MethodMacApp}},
+		{Index: 3, Tool: core.Tool{Package: "@google/gemini-cli", Method: core.MethodNpmPkg}},
+		{Index: 4, Tool: core.Tool{Package: "@anthropic-ai/claude-code", Method: core.MethodClaude}},
+		{Index: 5, Tool: core.Tool{Binary: "antigravity", Method: core.MethodManual}},
+		{Index: 6, Tool: core.Tool{Binary: "toad", Method: core.MethodToad}},
+	}
+
+	var mu sync.Mutex
+	running := make(map[string]int)
+	var total, peak int
+	var order []int
+
+	skipped := NewScheduler(3).Run(context.Background(), jobs, func(j Job) {
+		key := LockKey(j.Tool)
+		mu.Lock()
+		running[key]++
+		total++
+		peak = max(peak, total)
+		if key != "" && running[key] > 1 {
+			t.Errorf("job %d: %d concurrent holders of lock %q", j.Index, running[key], key)
+		}
+		order = append(order, j.Index)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running[key]--
+		total--
+		mu.Unlock()
+	})
+
+	if len(skipped) != 0 {
+		t.Errorf("skipped = %v, want none", skipped)
+	}
+	if len(order) != len(jobs) {
+		t.Fatalf("ran %d jobs, want %d", len(order), len(jobs))
+	}
+	if peak > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3 workers", peak)
+	}
+	if peak < 2 {
+		t.Errorf("peak concurrency = %d, want brew and npm to overlap", peak)
+	}
+
+	// Jobs sharing a lock keep their queue order
+	pos := make(map[int]int)
+	for i, idx := range order {
+		pos[idx] = i
+	}
+	if pos[0] > pos[1] || pos[1] > pos[2] || pos[3] > pos[4] {
+		t.Errorf("order = %v, want brew 0<1<2 and npm 3<4", order)
+	}
+}
+
+func TestSchedulerStopsOnCancel(t *testing.T) {
+	jobs := []Job{
+		{Index: 0, Tool: core.Tool{Package: "jq", Method: core.MethodBrew}},
+		{Index: 1, Tool: core.Tool{Package: "fzf", Method: core.MethodBrew}},
+		{Index: 2, Tool: core.Tool{Package: "bat", Method: core.MethodBrew}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran []int
+	skipped := NewScheduler(2).Run(ctx, jobs, func(j Job) {
+		ran = append(ran, j.Index) // Only one brew job runs at a time
+		cancel()
+	})
+
+	if len(ran) != 1 || ran[0] != 0 {
+		t.Errorf("ran = %v, want only job 0", ran)
+	}
+	if len(skipped) != 2 || skipped[0].Index != 1 || skipped[1].Index != 2 {
+		t.Errorf("skipped = %v, want jobs 1 and 2", skipped)
+	}
+}
@@ -0,0 +1,77 @@
+package updater
+
+import (
+	"context"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// scriptStrategy handles vendor tools installed by a shell script into ~/.local/bin.
+// A strategy without an upgrade script still detects versions but needs manual updates.
+type scriptStrategy struct {
+	name      string
+	script    string // Install/upgrade one-liner, empty when the vendor ships none
+	uninstall string // Removes what the vendor script installed
+	via       Owner  // Package manager the script installs through, if any
+}
+
+func init() {
+	Register(&scriptStrategy{
+		name:      "toad",
+		script:    "curl -fsSL https://batrachian.ai/install | sh",
+		uninstall: "uv tool uninstall batrachian-toad", // The script installs through uv
+		via:       OwnerUv,
+	}, core.MethodToad)
+	Register(&scriptStrategy{name: "droid", uninstall: "rm -f ~/.local/bin/droid"}, core.MethodDroid)
+	Register(&scriptStrategy{name: "opencode", uninstall: "rm -rf ~/.opencode"}, core.MethodOpencode)
+}
+
+func (s *scriptStrategy) Name() string { return s.name }
+
+func (s *scriptStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	return d.getCliToolVersion(t)
+}
+
+// WarmUp is a no-op: install scripts have no release feed
+func (s *scriptStrategy) WarmUp(d *Detector) {}
+
+// Plan is the vendor script, downloaded and run, for both install and upgrade
+func (s *scriptStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	switch {
+	case a == ActionUninstall && s.uninstall != "":
+		return []PlanStep{scriptStep(s.uninstall, false)}
+	case a != ActionUninstall && s.script != "":
+		return []PlanStep{scriptStep(s.script, true)}
+	}
+	return nil
+}
+
+func (s *scriptStrategy) LockKey(t core.Tool) string { return s.name }
+
+// Owns claims ~/.local/bin, where the vendor scripts install, and the
+// package manager a script installs through
+func (s *scriptStrategy) Owns(o Owner) bool {
+	return o == OwnerLocalBin || (s.via != OwnerUnknown && o == s.via)
+}
+
+func (s *scriptStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return s.runScript(ctx, e, "update")
+}
+
+func (s *scriptStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return s.runScript(ctx, e, "install")
+}
+
+func (s *scriptStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	if s.uninstall == "" {
+		return errManual("uninstall")
+	}
+	return e.runSteps(ctx, s.name+" uninstall", s.Plan(ActionUninstall, t))
+}
+
+func (s *scriptStrategy) runScript(ctx context.Context, e *Executor, action string) error {
+	if s.script == "" {
+		return errManual(action)
+	}
+	return e.runStep(ctx, s.name+" "+action, scriptStep(s.script, true))
+}
@@ -0,0 +1,279 @@
+package updater
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// VersionKind tells which family a parsed version belongs to.
+// Versions of different kinds cannot be ordered against each other.
+type VersionKind int
+
+const (
+	KindInvalid  VersionKind = iota // Not a version ("MISSING", "Detected", ...)
+	KindNumeric                     // Dotted numbers: 1.2.3, 20.11, 3.4.5_1
+	KindCalendar                    // Calendar versions: 2024.1.15
+	KindGitHash                     // Git commit hashes: abc123f
+)
+
+// Version is a parsed version string that can be ordered
+type Version struct {
+	Raw        string
+	Kind       VersionKind
+	Segments   []int    // Numeric release segments (1.2.3 -> [1 2 3])
+	Revision   int      // Package revision suffix (Homebrew's 1.2.3_1 -> 1)
+	PreRelease []string // Dot separated pre-release identifiers (1.0.0-rc.1 -> [rc 1])
+	Distro     string   // Distro package revision (1.7.1-r1 -> 1), see normalizeDistroVersion
+	Build      string   // Build metadata, ignored for ordering (1.0.0+abc)
+	Hash       string   // Lower-case commit hash for KindGitHash
+}
+
+var (
+	// Leading "v", dotted numbers, optional _revision, -pre-release, -r<distro revision> and +build
+	numericVersionPattern = regexp.MustCompile(`^[vV]?(\d+(?:\.\d+)*)(?:_(\d+))?(?:-?([0-9A-Za-z][0-9A-Za-z.\-]*?))??(?:-r(\d[0-9A-Za-z.]*))?(?:\+([0-9A-Za-z.\-]+))?$`)
+	hashVersionPattern    = regexp.MustCompile(`^[a-fA-F0-9]{7,40}$`)
+)
+
+// calendarYearMin is the lowest leading segment treated as a calendar year
+const calendarYearMin = 1990
+
+// ParseVersion parses s into a Version. The second result is false when s is
+// not a version at all (placeholders like "MISSING" or "Unknown").
+func ParseVersion(s string) (Version, bool) {
+	s = strings.TrimSpace(s)
+	v := Version{Raw: s}
+
+	// Pure digits are ambiguous with hashes, so check for a hash only when a letter is present
+	if hashVersionPattern.MatchString(s) && strings.ContainsAny(strings.ToLower(s), "abcdef") {
+		v.Kind = KindGitHash
+		v.Hash = strings.ToLower(s)
+		return v, true
+	}
+
+	m := numericVersionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return v, false
+	}
+
+	for _, part := range strings.Split(m[1], ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return v, false
+		}
+		v.Segments = append(v.Segments, n)
+	}
+	if m[2] != "" {
+		v.Revision, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" {
+		v.PreRelease = strings.Split(strings.Trim(m[3], ".-"), ".")
+	}
+	v.Distro = m[4]
+	v.Build = m[5]
+
+	v.Kind = KindNumeric
+	if len(v.Segments) >= 2 && v.Segments[0] >= calendarYearMin {
+		v.Kind = KindCalendar
+	}
+	return v, true
+}
+
+// Compare orders v against o: -1 if v is older, 0 if equal, 1 if newer.
+// The second result is false when the two versions cannot be ordered.
+func (v Version) Compare(o Version) (int, bool) {
+	if v.Kind == KindInvalid || o.Kind == KindInvalid || v.Kind != o.Kind {
+		return 0, false
+	}
+
+	if v.Kind == KindGitHash {
+		// Short and long forms of the same commit are equal; anything else has no order
+		if strings.HasPrefix(v.Hash, o.Hash) || strings.HasPrefix(o.Hash, v.Hash) {
+			return 0, true
+		}
+		return 0, false
+	}
+
+	// Missing trailing segments count as zero, so 20.11 == 20.11.0
+	for i := 0; i < len(v.Segments) || i < len(o.Segments); i++ {
+		if c := compareInt(segmentAt(v.Segments, i), segmentAt(o.Segments, i)); c != 0 {
+			return c, true
+		}
+	}
+
+	if c := comparePreRelease(v.PreRelease, o.PreRelease); c != 0 {
+		return c, true
+	}
+	if c := compareInt(v.Revision, o.Revision); c != 0 {
+		return c, true
+	}
+	// A distro package of 1.7.1 is 1.7.1 as far as upstream releases, pins
+	// and manifests go; only two packages are told apart by their revision
+	if v.Distro != "" && o.Distro != "" {
+		return compareDistroRevision(v.Distro, o.Distro), true
+	}
+	return 0, true
+}
+
+// IsNewer reports whether v is strictly newer than o
+func (v Version) IsNewer(o Version) bool {
+	c, ok := v.Compare(o)
+	return ok && c > 0
+}
+
+func (v Version) String() string {
+	return v.Raw
+}
+
+// comparePreRelease applies the semver rules: a release sorts after any of its
+// pre-releases, numeric identifiers sort numerically and before alphanumeric ones.
+func comparePreRelease(a, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		an, aErr := strconv.Atoi(a[i])
+		bn, bErr := strconv.Atoi(b[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInt(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInt(len(a), len(b))
+}
+
+// compareDistroRevision orders package revisions the way dpkg and rpm do:
+// runs of digits numerically, everything else as text (1ubuntu3 < 1ubuntu10)
+func compareDistroRevision(a, b string) int {
+	for a != "" || b != "" {
+		var ar, br string
+		ar, a = leadingRun(a)
+		br, b = leadingRun(b)
+		an, aErr := strconv.Atoi(ar)
+		bn, bErr := strconv.Atoi(br)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInt(an, bn); c != 0 {
+				return c
+			}
+		default:
+			if c := strings.Compare(ar, br); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// leadingRun splits s after its first run of digits or non-digits
+func leadingRun(s string) (run, rest string) {
+	if s == "" {
+		return "", ""
+	}
+	digit := s[0] >= '0' && s[0] <= '9'
+	i := 1
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9') == digit {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func segmentAt(segments []int, i int) int {
+	if i < len(segments) {
+		return segments[i]
+	}
+	return 0
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// VersionRelation describes how an installed version relates to the latest known one
+type VersionRelation int
+
+const (
+	RelationIncomparable VersionRelation = iota // One side is unknown or the formats differ
+	RelationUpToDate                            // Local matches the latest release
+	RelationOutdated                            // A newer release exists
+	RelationAhead                               // Local is newer than the latest release
+)
+
+// CompareVersions relates a local version to a remote one
+func CompareVersions(local, remote string) VersionRelation {
+	lv, ok := ParseVersion(local)
+	if !ok {
+		return RelationIncomparable
+	}
+	rv, ok := ParseVersion(remote)
+	if !ok {
+		return RelationIncomparable
+	}
+
+	c, ok := lv.Compare(rv)
+	switch {
+	case !ok:
+		return RelationIncomparable
+	case c < 0:
+		return RelationOutdated
+	case c > 0:
+		return RelationAhead
+	}
+	return RelationUpToDate
+}
+
+// ClassifyVersions maps a local/remote pair onto the dashboard status and message.
+// Placeholders ("...", "Checking...", "Unknown") keep the tool as installed.
+func ClassifyVersions(local, remote string) (core.ToolStatus, string) {
+	if local == "MISSING" {
+		return core.StatusMissing, "Not installed"
+	}
+	if !IsKnownVersion(remote) {
+		return core.StatusInstalled, ""
+	}
+
+	switch CompareVersions(local, remote) {
+	case RelationOutdated:
+		return core.StatusOutdated, "Update available"
+	case RelationAhead:
+		return core.StatusAhead, "Newer than latest release"
+	case RelationIncomparable:
+		if local != remote {
+			return core.StatusInstalled, "Versions not comparable"
+		}
+	}
+	return core.StatusInstalled, ""
+}
+
+// IsKnownVersion reports whether s holds real version data rather than a placeholder
+func IsKnownVersion(s string) bool {
+	switch s {
+	case "", "...", "Checking...", "Unknown", "MISSING":
+		return false
+	}
+	return true
+}
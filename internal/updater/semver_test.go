@@ -0,0 +1,161 @@
+package updater
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		local, remote string
+		want          VersionRelation
+	}{
+		{"1.2.3", "1.2.4", RelationOutdated},
+		{"1.10.0", "1.9.0", RelationAhead},
+		{"v20.11.0", "20.11", RelationUpToDate},
+		{"1.0.0-rc.1", "1.0.0", RelationOutdated},
+		{"1.0.0-alpha", "1.0.0-alpha.1", RelationOutdated},
+		{"1.0.0-2", "1.0.0-beta", RelationOutdated},
+		{"1.0.0+abc", "1.0.0+def", RelationUpToDate},
+		{"1.2.3.post1", "1.2.3", RelationIncomparable},
+		{"1.2.3~rc1", "1.2.3", RelationIncomparable},
+		{"3.4.5", "3.4.5_1", RelationOutdated},
+		{"2024.1.15", "2024.2.1", RelationOutdated},
+		{"2024.1.15", "1.2.3", RelationIncomparable},
+		{"abc123f", "abc123f0d9e", RelationUpToDate},
+		{"abc123f", "def4567", RelationIncomparable},
+		{"Detected", "1.0.0", RelationIncomparable},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.local, tt.remote); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %v, want %v", tt.local, tt.remote, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyVersions(t *testing.T) {
+	tests := []struct {
+		local, remote string
+		want          core.ToolStatus
+	}{
+		{"MISSING", "Unknown", core.StatusMissing},
+		{"1.0.0", "Checking...", core.StatusInstalled},
+		{"1.0.0", "1.1.0", core.StatusOutdated},
+		{"1.2.0", "1.1.0", core.StatusAhead},
+		{"Detected", "1.1.0", core.StatusInstalled},
+	}
+	for _, tt := range tests {
+		if got, _ := ClassifyVersions(tt.local, tt.remote); got != tt.want {
+			t.Errorf("ClassifyVersions(%q, %q) = %v, want %v", tt.local, tt.remote, got, tt.want)
+		}
+	}
+}
+
+func TestParseToolSpecificVersion(t *testing.T) {
+	tests := []struct {
+		binary, output, want string
+	}{
+		{"aws", "aws-cli/2.22.35 Python/3.11.9 Darwin/24.0.0 source/arm64", "2.22.35"},
+		{"go", "go version go1.23.4 darwin/arm64", "1.23.4"},
+		{"python3", "Python 3.13.1", "3.13.1"},
+		{"node", "v20.11.0", "20.11.0"},
+		{"docker", "Docker version 24.0.7, build afdd53b", "24.0.7"},
+		{"brew", "Homebrew 4.2.0\nHomebrew/homebrew-core (git revision 1a2b)", "4.2.0"},
+		{"git", "git version 2.43.0", "2.43.0"},
+		{"rg", "ripgrep 14.1.0\n\nfeatures:+pcre2", "14.1.0"},
+		{"kubectl", "Client Version: v1.28.2", "1.28.2"},
+		{"uv", "", "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := ParseToolSpecificVersion(tt.binary, tt.output); got != tt.want {
+			t.Errorf("ParseToolSpecificVersion(%q, %q) = %q, want %q", tt.binary, tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+		ok   bool
+	}{
+		{"1.2.3", Version{Kind: KindNumeric, Segments: []int{1, 2, 3}}, true},
+		{"v20.11", Version{Kind: KindNumeric, Segments: []int{20, 11}}, true},
+		{" 3.4.5_1 ", Version{Kind: KindNumeric, Segments: []int{3, 4, 5}, Revision: 1}, true},
+		{"1.0.0-rc.1+build.7", Version{Kind: KindNumeric, Segments: []int{1, 0, 0}, PreRelease: []string{"rc", "1"}, Build: "build.7"}, true},
+		{"2024.1.15", Version{Kind: KindCalendar, Segments: []int{2024, 1, 15}}, true},
+		{"2024", Version{Kind: KindNumeric, Segments: []int{2024}}, true},
+		{"ABC123F", Version{Kind: KindGitHash, Hash: "abc123f"}, true},
+		{"1234567", Version{Kind: KindNumeric, Segments: []int{1234567}}, true},
+		{"MISSING", Version{}, false},
+		{"", Version{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseVersion(tt.in)
+		if ok != tt.ok {
+			t.Errorf("ParseVersion(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		got.Raw = ""
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseVersion(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{"1.2", "1.2.0", 0, true},
+		{"1.2.10", "1.2.9", 1, true},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1, true},
+		{"1.0.0-rc.1", "1.0.0-beta", 1, true},
+		{"1.2.3_2", "1.2.3_10", -1, true},
+		{"2024.1.1", "1.2.3", 0, false},
+		{"abc123f", "abc123f", 0, true},
+		{"abc123f", "1.2.3", 0, false},
+	}
+	for _, tt := range tests {
+		a, _ := ParseVersion(tt.a)
+		b, _ := ParseVersion(tt.b)
+		got, ok := a.Compare(b)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%q.Compare(%q) = (%d, %v), want (%d, %v)", tt.a, tt.b, got, ok, tt.want, tt.wantOK)
+		}
+		if want := tt.want > 0 && tt.wantOK; a.IsNewer(b) != want {
+			t.Errorf("%q.IsNewer(%q) = %v, want %v", tt.a, tt.b, !want, want)
+		}
+	}
+}
+
+func TestClassifyVersionsMessage(t *testing.T) {
+	tests := []struct {
+		local, remote string
+		want          string
+	}{
+		{"MISSING", "1.0.0", "Not installed"},
+		{"1.0.0", "1.1.0", "Update available"},
+		{"1.2.0", "1.1.0", "Newer than latest release"},
+		{"2024.1.1", "1.1.0", "Versions not comparable"},
+		{"1.1.0", "1.1.0", ""},
+		{"1.1.0", "...", ""},
+	}
+	for _, tt := range tests {
+		if _, got := ClassifyVersions(tt.local, tt.remote); got != tt.want {
+			t.Errorf("ClassifyVersions(%q, %q) message = %q, want %q", tt.local, tt.remote, got, tt.want)
+		}
+	}
+	for _, placeholder := range []string{"", "...", "Checking...", "Unknown", "MISSING"} {
+		if IsKnownVersion(placeholder) {
+			t.Errorf("IsKnownVersion(%q) = true, want false", placeholder)
+		}
+	}
+}
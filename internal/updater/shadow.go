@@ -0,0 +1,118 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// Installation is one copy of a tool's binary
+type Installation struct {
+	Path     string // Where the copy was found
+	Resolved string // Path with symlinks followed
+	Owner    Owner
+	Active   bool // First on PATH: the copy a shell runs
+}
+
+// installDirs are where installers put binaries, searched after PATH so
+// copies left outside it are listed too
+func (d *Detector) installDirs() []string {
+	dirs := []string{"/opt/homebrew/bin", "/usr/local/bin", "/home/linuxbrew/.linuxbrew/bin", "/usr/bin", "/bin", "/snap/bin"}
+	if d.home != "" {
+		dirs = append(dirs,
+			filepath.Join(d.home, ".local/bin"),
+			filepath.Join(d.home, ".npm-global/bin"),
+			filepath.Join(d.home, ".volta/bin"),
+			filepath.Join(d.home, ".asdf/shims"),
+			filepath.Join(d.home, ".local/share/mise/shims"),
+			filepath.Join(d.home, ".pyenv/shims"),
+			filepath.Join(d.home, ".rbenv/shims"),
+			filepath.Join(d.home, ".goenv/shims"),
+			filepath.Join(d.home, ".cargo/bin"),
+			filepath.Join(d.home, "go/bin"),
+		)
+	}
+	return dirs
+}
+
+// Installations lists every copy of t's binary: the active one first, then
+// the rest of PATH in order, then the install locations PATH leaves out.
+// Links to the same file count once.
+func (d *Detector) Installations(t core.Tool) []Installation {
+	var copies []Installation
+	seen := make(map[string]bool)
+	if p := d.DetectProvenance(t); p.Path != "" {
+		copies = append(copies, Installation{Path: p.Path, Resolved: p.Resolved, Owner: p.Owner, Active: true})
+		seen[p.Resolved] = true
+	}
+	if t.Binary == "" || filepath.Base(t.Binary) != t.Binary {
+		return copies // App names and absolute paths are not searched for
+	}
+
+	for _, dir := range append(filepath.SplitList(d.path), d.installDirs()...) {
+		if dir == "" {
+			continue
+		}
+		path := filepath.Join(dir, t.Binary)
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+			continue
+		}
+		resolved := resolveBinary(path)
+		if seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		copies = append(copies, Installation{Path: path, Resolved: resolved, Owner: classifyBinary(path, resolved, d.home)})
+	}
+	return copies
+}
+
+// InstallationVersion runs a copy's --version, like local detection does
+// for the active one
+func (d *Detector) InstallationVersion(t core.Tool, c Installation) string {
+	if output := d.runCmd(c.Path, "--version"); output != "" && output != "MISSING" && output != "Unknown" {
+		return ParseToolSpecificVersion(t.Binary, output)
+	}
+	return "Unknown"
+}
+
+// manages reports whether t's method is known to install the copies o owns
+func manages(t core.Tool, o Owner) bool {
+	s, ok := StrategyFor(t.Method)
+	if !ok || o == OwnerUnknown {
+		return false
+	}
+	b, ok := s.(binaryOwner)
+	return ok && b.Owns(o)
+}
+
+// Shadowed returns the copy t's method manages when an earlier copy on PATH
+// hides it, so upgrading changes a binary the shell never runs
+func Shadowed(t core.Tool, copies []Installation) (Installation, bool) {
+	if len(copies) < 2 || !copies[0].Active || manages(t, copies[0].Owner) {
+		return Installation{}, false
+	}
+	for _, c := range copies[1:] {
+		if manages(t, c.Owner) {
+			return c, true
+		}
+	}
+	return Installation{}, false
+}
+
+// DetectOwnership returns the provenance of t's active binary and the path
+// of the managed copy it shadows, "" when there is none
+func (d *Detector) DetectOwnership(t core.Tool) (Provenance, string) {
+	copies := d.Installations(t)
+	if len(copies) == 0 || !copies[0].Active {
+		return Provenance{}, ""
+	}
+	active := copies[0]
+	p := Provenance{Path: active.Path, Resolved: active.Resolved, Owner: active.Owner}
+	if c, ok := Shadowed(t, copies); ok {
+		return p, c.Path
+	}
+	return p, ""
+}
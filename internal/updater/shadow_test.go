@@ -0,0 +1,51 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestInstallationsShadowed(t *testing.T) {
+	r := NewFakeRunner()
+	d := newTestDetector(t, r)
+	first := filepath.Join(d.home, "bin")
+	localBin := filepath.Join(d.home, ".local", "bin")
+	d.path = first + string(os.PathListSeparator) + localBin + string(os.PathListSeparator) + first
+
+	active := filepath.Join(first, "droid")
+	managed := filepath.Join(localBin, "droid")
+	writeExecutable(t, active)
+	writeExecutable(t, managed)
+	r.OnPath("droid", active)
+	droid := core.Tool{Key: "droid", Binary: "droid", Method: core.MethodDroid}
+
+	copies := d.Installations(droid)
+	if len(copies) != 2 {
+		t.Fatalf("Installations() = %+v, want the PATH copy and ~/.local/bin", copies)
+	}
+	if !copies[0].Active || copies[0].Owner != OwnerUnknown || copies[1].Active || copies[1].Owner != OwnerLocalBin {
+		t.Errorf("Installations() = %+v, want an active unknown copy, then local_bin", copies)
+	}
+	if c, ok := Shadowed(droid, copies); !ok || c.Path != managed {
+		t.Errorf("Shadowed() = %+v, %v, want %s", c, ok, managed)
+	}
+	if p, shadowed := d.DetectOwnership(droid); p.Path != active || shadowed != managed {
+		t.Errorf("DetectOwnership() = %+v, %q, want %s shadowing %s", p, shadowed, active, managed)
+	}
+
+	// The managed copy first on PATH shadows nothing
+	r.OnPath("droid", managed)
+	d.path = localBin + string(os.PathListSeparator) + first
+	if c, ok := Shadowed(droid, d.Installations(droid)); ok {
+		t.Errorf("Shadowed() with the managed copy active = %+v, want none", c)
+	}
+
+	// Methods that do not say where they install are never shadowed
+	omz := core.Tool{Key: "omz", Binary: "droid", Method: core.MethodOmz}
+	if _, ok := Shadowed(omz, copies); ok {
+		t.Error("Shadowed() for a method without known install locations, want false")
+	}
+}
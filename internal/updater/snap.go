@@ -0,0 +1,75 @@
+package updater
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// snapStrategy handles snaps, Package being the snap name
+type snapStrategy struct{}
+
+func init() {
+	Register(&snapStrategy{}, core.MethodSnap)
+}
+
+func (s *snapStrategy) Name() string { return "snap" }
+
+func (s *snapStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	out := d.runOutput("snap", "list", packageName(t))
+	return d.detectListed(t, parseSnapList(out), SourceSnapList)
+}
+
+// WarmUp also fills the cask entries of mac_app tools installed as a snap,
+// the way brewStrategy fills casks
+func (s *snapStrategy) WarmUp(d *Detector) {
+	// snap refresh --list (runOutput returns nothing when snapd is absent)
+	for snap, latest := range parseSnapList(d.runOutput("snap", "refresh", "--list")) {
+		info := remoteInfo{latest, SourceSnapRefresh}
+		d.setRemote(s.Name(), snap, info)
+		for _, app := range desktopApps {
+			if app.snap == snap {
+				d.setRemote(caskCacheName, app.cask, info)
+			}
+		}
+	}
+}
+
+func (s *snapStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	switch a {
+	case ActionInstall:
+		// Editors and terminals are mostly classic snaps, which must be asked for
+		classic := privilegedStep("snap", "install", "--classic", packageName(t))
+		classic.RetryOn = "classic confinement"
+		classic.Note = "the snap needs access outside its sandbox"
+		return []PlanStep{privilegedStep("snap", "install", packageName(t)), classic}
+	case ActionUninstall:
+		return []PlanStep{privilegedStep("snap", "remove", packageName(t))}
+	}
+	return []PlanStep{privilegedStep("snap", "refresh", packageName(t))}
+}
+
+// LockKey is per manager: snapd serializes changes to the same snaps anyway
+func (s *snapStrategy) LockKey(t core.Tool) string { return "snap" }
+
+// Owns claims the /snap/bin launchers
+func (s *snapStrategy) Owns(o Owner) bool { return o == OwnerSnap }
+
+func (s *snapStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "snap refresh", s.Plan(ActionUpgrade, t))
+}
+
+func (s *snapStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	plan := s.Plan(ActionInstall, t)
+	install, classic := plan[0], plan[1]
+	err := e.runStep(ctx, "snap install", install)
+	if err != nil && strings.Contains(err.Error(), classic.RetryOn) {
+		return e.runStep(ctx, "snap install --classic", classic)
+	}
+	return err
+}
+
+func (s *snapStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "snap remove", s.Plan(ActionUninstall, t))
+}
@@ -0,0 +1,182 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// Strategy implements everything Spark does with one kind of UpdateMethod.
+// Each package manager lives in its own file and registers itself in init(),
+// so adding a method never touches the Detector, Executor or TUI.
+type Strategy interface {
+	// Name namespaces the strategy's entries in the remote version cache
+	Name() string
+
+	// DetectLocal returns the installed version of t and its detection source
+	DetectLocal(d *Detector, t core.Tool) (version, source string)
+
+	// WarmUp fetches latest versions in bulk and stores them with d.setRemote.
+	// It must be a cheap no-op when the package manager is not installed.
+	WarmUp(d *Detector)
+
+	// Plan lists the commands action a runs on t, probes and retries
+	// included; the action's method builds its commands from it. An empty
+	// plan means the action needs manual work.
+	Plan(a Action, t core.Tool) []PlanStep
+
+	// LockKey names the resource an upgrade of t holds exclusively (the brew
+	// prefix, the npm global prefix, the dpkg database). The Scheduler never
+	// runs two upgrades with the same key at once; "" means no lock.
+	LockKey(t core.Tool) string
+
+	Upgrade(ctx context.Context, e *Executor, t core.Tool) error
+	Install(ctx context.Context, e *Executor, t core.Tool) error
+	Uninstall(ctx context.Context, e *Executor, t core.Tool) error
+}
+
+var registry = make(map[core.UpdateMethod]Strategy)
+
+// Register makes s responsible for the given methods. It panics on duplicates
+// because two files claiming the same method is a programming error.
+func Register(s Strategy, methods ...core.UpdateMethod) {
+	for _, m := range methods {
+		if _, dup := registry[m]; dup {
+			panic(fmt.Sprintf("updater: strategy for method %q registered twice", m))
+		}
+		registry[m] = s
+	}
+}
+
+// StrategyFor returns the strategy registered for m
+func StrategyFor(m core.UpdateMethod) (Strategy, bool) {
+	s, ok := registry[m]
+	return s, ok
+}
+
+// strategies returns each registered strategy once, in a stable order
+func strategies() []Strategy {
+	seen := make(map[Strategy]bool)
+	var list []Strategy
+	for _, s := range registry {
+		if !seen[s] {
+			seen[s] = true
+			list = append(list, s)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
+
+// PlannedCommand returns the command Spark will run to apply a to t, as shown
+// in logs. A rollback's commands depend on the recorded recipe, see
+// Rollback.String; Executor.Plan has the full detail.
+func PlannedCommand(a Action, t core.Tool) string {
+	if s, ok := StrategyFor(t.Method); ok && a != ActionRollback {
+		if cmd := summarize(s.Plan(a, t)); cmd != "" {
+			return cmd
+		}
+	}
+	return a.Progressive() + " " + t.Name + "..."
+}
+
+// CanInstall reports whether Spark knows a command that installs t
+func CanInstall(t core.Tool) bool {
+	s, ok := StrategyFor(t.Method)
+	return ok && len(s.Plan(ActionInstall, t)) > 0
+}
+
+// CanUninstall reports whether Spark knows a command that removes t
+func CanUninstall(t core.Tool) bool {
+	s, ok := StrategyFor(t.Method)
+	if _, resolves := s.(toolResolver); resolves {
+		t.Package = "/" + packageName(t) // Stands in for what Executor.resolve finds
+	}
+	return ok && len(s.Plan(ActionUninstall, t)) > 0
+}
+
+// toolResolver is implemented by strategies whose package names files to look
+// up before anything is planned, so that commands only ever name files that
+// exist. Resolve returns false when they are not found.
+type toolResolver interface {
+	Resolve(home string, t core.Tool) (core.Tool, bool)
+}
+
+// resolve hands t to its strategy's toolResolver, if any, for every action
+// but install: a missing tool has no files to find
+func (e *Executor) resolve(s Strategy, a Action, t core.Tool) (core.Tool, error) {
+	r, ok := s.(toolResolver)
+	if !ok || a == ActionInstall || a == ActionRollback {
+		return t, nil
+	}
+	resolved, ok := r.Resolve(e.home, t)
+	if !ok {
+		return t, fmt.Errorf("%s not found: manual %s required", packageName(t), a)
+	}
+	return resolved, nil
+}
+
+// dependentsLister is implemented by strategies whose package manager tracks
+// reverse dependencies, so an uninstall can warn about what it would break
+type dependentsLister interface {
+	Dependents(ctx context.Context, e *Executor, t core.Tool) ([]string, error)
+}
+
+// Dependents returns the installed packages that depend on t. It is empty
+// when t's package manager does not track dependencies.
+func (e *Executor) Dependents(ctx context.Context, t core.Tool) ([]string, error) {
+	s, ok := StrategyFor(t.Method)
+	if !ok {
+		return nil, nil
+	}
+	if l, ok := s.(dependentsLister); ok {
+		return l.Dependents(ctx, e, t)
+	}
+	return nil, nil
+}
+
+// LockKey returns the lock an update of t needs, "" for none
+func LockKey(t core.Tool) string {
+	if s, ok := StrategyFor(t.Method); ok {
+		return s.LockKey(t)
+	}
+	return ""
+}
+
+// packageName falls back to the binary when a tool has no package
+func packageName(t core.Tool) string {
+	if t.Package != "" {
+		return t.Package
+	}
+	return t.Binary
+}
+
+// errManual is returned by strategies that cannot act on a tool automatically
+func errManual(action string) error {
+	return fmt.Errorf("manual %s required (check vendor portal)", action)
+}
+
+// combined runs a command and returns its combined stdout/stderr
+func (e *Executor) combined(ctx context.Context, name string, args ...string) (string, error) {
+	res := e.exec(ctx, Command{Name: name, Args: args})
+	return res.Combined(), res.Err
+}
+
+// exec runs c, streaming its output to the Executor's LineFunc when one is set
+func (e *Executor) exec(ctx context.Context, c Command) Result {
+	if e.onLine != nil {
+		e.onLine(OutputLine{Stream: StreamCommand, Text: "$ " + c.String()})
+		c.OnLine = e.onLine
+	}
+	return e.runner.Run(ctx, c)
+}
+
+// run executes a command, wrapping failures with the label and the command output
+func (e *Executor) run(ctx context.Context, label string, name string, args ...string) error {
+	if output, err := e.combined(ctx, name, args...); err != nil {
+		return fmt.Errorf("%s failed: %s: %v", label, output, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package updater
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// Stream tells where a line of command output came from
+type Stream int
+
+const (
+	StreamStdout  Stream = iota
+	StreamStderr         // Child process stderr
+	StreamCommand        // Synthetic "$ brew upgrade jq" line emitted before each command
+)
+
+// OutputLine is one line of live output from an update
+type OutputLine struct {
+	Stream Stream
+	Text   string
+}
+
+// LineFunc receives output line by line while a command runs
+type LineFunc func(OutputLine)
+
+// lineWriter splits a byte stream into lines for a LineFunc. Progress bars
+// redraw with "\r", so carriage returns end a line too. stdout and stderr
+// writers share one mutex because os/exec copies them from separate goroutines.
+type lineWriter struct {
+	mu     *sync.Mutex
+	stream Stream
+	fn     LineFunc
+	buf    []byte
+}
+
+func newLineWriters(fn LineFunc) (stdout, stderr *lineWriter) {
+	mu := &sync.Mutex{}
+	return &lineWriter{mu: mu, stream: StreamStdout, fn: fn}, &lineWriter{mu: mu, stream: StreamStderr, fn: fn}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexAny(w.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		w.emit(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush emits a trailing line that had no newline
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.emit(string(w.buf))
+	w.buf = nil
+}
+
+func (w *lineWriter) emit(text string) {
+	if text = strings.TrimRight(text, " \t"); text != "" {
+		w.fn(OutputLine{Stream: w.stream, Text: text})
+	}
+}
@@ -0,0 +1,55 @@
+package updater
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestLineWriterSplitsLines(t *testing.T) {
+	var got []OutputLine
+	stdout, stderr := newLineWriters(func(l OutputLine) { got = append(got, l) })
+
+	_, _ = stdout.Write([]byte("==> Downloading jq\n==> Pou"))
+	_, _ = stderr.Write([]byte("#### 40%\r######## 80%\r"))
+	_, _ = stdout.Write([]byte("ring jq--1.7.1\n\n"))
+	_, _ = stdout.Write([]byte("no trailing newline"))
+	stdout.Flush()
+	stderr.Flush()
+
+	want := []OutputLine{
+		{StreamStdout, "==> Downloading jq"},
+		{StreamStderr, "#### 40%"},
+		{StreamStderr, "######## 80%"},
+		{StreamStdout, "==> Pouring jq--1.7.1"},
+		{StreamStdout, "no trailing newline"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+}
+
+func TestUpdateStreaming(t *testing.T) {
+	r := NewFakeRunner().On("brew upgrade jq", Result{
+		Stdout: "==> Upgrading jq\n  1.7 -> 1.7.1\n",
+		Stderr: "Warning: jq was already upgraded\n",
+	})
+	tool := core.Tool{Binary: "jq", Package: "jq", Method: core.MethodBrew}
+
+	var got []OutputLine
+	if err := NewExecutorWithRunner(r).UpdateStreaming(context.Background(), tool, func(l OutputLine) { got = append(got, l) }); err != nil {
+		t.Fatalf("UpdateStreaming() error = %v", err)
+	}
+
+	want := []OutputLine{
+		{StreamCommand, "$ brew upgrade jq"},
+		{StreamStdout, "==> Upgrading jq"},
+		{StreamStdout, "  1.7 -> 1.7.1"},
+		{StreamStderr, "Warning: jq was already upgraded"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,128 @@
+// Package updatertest provides a scripted updater.CommandRunner for tests of
+// the packages built on the updater, such as cli. The updater's own tests
+// use their copy in runner_fake_test.go, as importing this package from
+// them would be a cycle.
+package updatertest
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/dpeluche/spark/internal/updater"
+)
+
+// FakeRunner replays scripted results and records every invocation.
+// Commands with an OnLine callback get their scripted stdout then stderr
+// replayed line by line.
+// Commands are matched on their full command line (see updater.Command.String).
+// Several results queued for the same command are returned in order, the
+// last one repeating; unscripted commands fail as if not installed.
+type FakeRunner struct {
+	mu        sync.Mutex
+	responses map[string][]updater.Result
+	paths     map[string]string
+	calls     []updater.Command
+}
+
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{
+		responses: make(map[string][]updater.Result),
+		paths:     make(map[string]string),
+	}
+}
+
+// On queues results for a command line such as "brew outdated --json=v2"
+func (f *FakeRunner) On(cmdline string, results ...updater.Result) *FakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[cmdline] = append(f.responses[cmdline], results...)
+	return f
+}
+
+// OnPath makes LookPath find name at path
+func (f *FakeRunner) OnPath(name, path string) *FakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paths[name] = path
+	return f
+}
+
+func (f *FakeRunner) Run(ctx context.Context, c updater.Command) updater.Result {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, c)
+
+	// A cancelled context kills real commands before they produce anything
+	if err := ctx.Err(); err != nil {
+		return updater.Result{ExitCode: -1, Err: err}
+	}
+
+	res := f.next(c)
+	if c.OnLine != nil {
+		replay(c.OnLine, updater.StreamStdout, res.Stdout)
+		replay(c.OnLine, updater.StreamStderr, res.Stderr)
+	}
+	return res
+}
+
+// replay splits out into lines the way the real runner does: "\r" ends a
+// line too and blank lines are dropped
+func replay(fn updater.LineFunc, stream updater.Stream, out string) {
+	for _, line := range strings.FieldsFunc(out, func(r rune) bool { return r == '\r' || r == '\n' }) {
+		if line = strings.TrimRight(line, " \t"); line != "" {
+			fn(updater.OutputLine{Stream: stream, Text: line})
+		}
+	}
+}
+
+func (f *FakeRunner) next(c updater.Command) updater.Result {
+	queue := f.responses[c.String()]
+	switch len(queue) {
+	case 0:
+		return updater.Result{ExitCode: 127, Err: fmt.Errorf("%s: %w", c.Name, exec.ErrNotFound)}
+	case 1:
+		return queue[0]
+	}
+	f.responses[c.String()] = queue[1:]
+	return queue[0]
+}
+
+func (f *FakeRunner) LookPath(name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if path, ok := f.paths[name]; ok {
+		return path, nil
+	}
+	return "", fmt.Errorf("%s: %w", name, exec.ErrNotFound)
+}
+
+// Calls returns the command lines run so far, in order
+func (f *FakeRunner) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	lines := make([]string, len(f.calls))
+	for i, c := range f.calls {
+		lines[i] = c.String()
+	}
+	return lines
+}
+
+// Invocations returns the recorded commands including their environment
+func (f *FakeRunner) Invocations() []updater.Command {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]updater.Command(nil), f.calls...)
+}
+
+// Stdout is a successful result printing out
+func Stdout(out string) updater.Result {
+	return updater.Result{Stdout: out}
+}
+
+// Failure is a result exiting with code and printing stderr
+func Failure(code int, stderr string) updater.Result {
+	return updater.Result{Stderr: stderr, ExitCode: code, Err: fmt.Errorf("exit status %d", code)}
+}
@@ -0,0 +1,69 @@
+package updater
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// uvStrategy handles Python applications installed with `uv tool`
+type uvStrategy struct{}
+
+func init() {
+	Register(&uvStrategy{}, core.MethodUv)
+}
+
+func (s *uvStrategy) Name() string { return "uv" }
+
+func (s *uvStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	return d.detectListed(t, parseUvToolList(d.runOutput("uv", "tool", "list")), SourceUvToolList)
+}
+
+// WarmUp asks PyPI about every installed tool: uv has no outdated command
+func (s *uvStrategy) WarmUp(d *Detector) {
+	installed := parseUvToolList(d.runOutput("uv", "tool", "list"))
+	d.warmUpFromRegistry(s.Name(), core.RegistryPyPI, sameNames(installed))
+}
+
+func (s *uvStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	switch a {
+	case ActionInstall:
+		return []PlanStep{step("uv", "tool", "install", packageName(t))}
+	case ActionUninstall:
+		return []PlanStep{step("uv", "tool", "uninstall", packageName(t))}
+	}
+	return []PlanStep{step("uv", "tool", "upgrade", packageName(t))}
+}
+
+// LockKey is per manager: uv tools share its cache and tool directory
+func (s *uvStrategy) LockKey(t core.Tool) string { return "uv" }
+
+// Owns claims the tool environments uv links into ~/.local/bin
+func (s *uvStrategy) Owns(o Owner) bool { return o == OwnerUv }
+
+func (s *uvStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "uv tool upgrade", s.Plan(ActionUpgrade, t))
+}
+
+func (s *uvStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "uv tool install", s.Plan(ActionInstall, t))
+}
+
+func (s *uvStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, "uv tool uninstall", s.Plan(ActionUninstall, t))
+}
+
+// parseUvToolList handles `uv tool list`: a "<package> v<version>" line per
+// tool, followed by "- <executable>" lines
+func parseUvToolList(out string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] == "-" || !strings.HasPrefix(fields[1], "v") {
+			continue
+		}
+		result[fields[0]] = strings.TrimPrefix(fields[1], "v")
+	}
+	return result
+}
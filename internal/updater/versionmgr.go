@@ -0,0 +1,307 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+// SourceVersionManager marks versions reported by asdf, mise, nvm, pyenv,
+// rbenv or goenv: the global version locally, the newest release remotely
+const SourceVersionManager = "version_manager"
+
+// versionManagerTimeout bounds one query; nvm and mise may go over the network
+const versionManagerTimeout = 30 * time.Second
+
+// versionManagerStrategy handles runtimes installed through a version
+// manager. The installed version is the manager's global one; an upgrade
+// installs the newest release and makes it global, leaving older versions
+// in place for projects that pin them. Every query is a shell one-liner,
+// because nvm is a shell function and the others print plain text.
+//
+// Package names the runtime as "<plugin>[@<prefix>]": asdf and mise need
+// the plugin ("node", "python@3.13"), the single-runtime managers only use
+// the version prefix, if any, to stay on a release line.
+type versionManagerStrategy struct {
+	name    string
+	owner   Owner
+	shell   string // Interpreter for the one-liners
+	prelude string // Prepended to every one-liner (nvm is a function in nvm.sh)
+
+	latest    func(plugin, prefix string) string // Prints the newest release
+	current   func(plugin string) string         // Prints the global version
+	installed func(plugin string) string         // Lists installed versions, one per line
+	use       func(plugin string) string         // Installs "$v" and makes it global
+}
+
+func init() {
+	Register(&versionManagerStrategy{
+		name:  "asdf",
+		owner: OwnerAsdf,
+		shell: "sh",
+		latest: func(plugin, prefix string) string {
+			return strings.TrimSpace("asdf latest " + plugin + " " + prefix)
+		},
+		current:   func(plugin string) string { return `basename "$(asdf where ` + plugin + `)"` },
+		installed: func(plugin string) string { return "asdf list " + plugin },
+		// asdf 0.16 replaced `global` with `set --home`
+		use: func(plugin string) string {
+			return `asdf install ` + plugin + ` "$v" && { asdf set --home ` + plugin + ` "$v" 2>/dev/null || asdf global ` + plugin + ` "$v"; }`
+		},
+	}, core.MethodAsdf)
+	Register(&versionManagerStrategy{
+		name:  "mise",
+		owner: OwnerMise,
+		shell: "sh",
+		latest: func(plugin, prefix string) string {
+			if prefix != "" {
+				return "mise latest " + plugin + "@" + prefix
+			}
+			return "mise latest " + plugin
+		},
+		current:   func(plugin string) string { return "mise current " + plugin },
+		installed: func(plugin string) string { return "mise ls --installed " + plugin },
+		use:       func(plugin string) string { return "mise use --global " + plugin + `@"$v"` },
+	}, core.MethodMise)
+	Register(&versionManagerStrategy{
+		name:    "nvm",
+		owner:   OwnerNvm,
+		shell:   "bash",
+		prelude: `. "${NVM_DIR:-$HOME/.nvm}/nvm.sh" && `,
+		latest: func(plugin, prefix string) string {
+			if prefix != "" {
+				return "nvm version-remote " + prefix
+			}
+			return "nvm version-remote --lts" // Runtimes stay on LTS unless a prefix says otherwise
+		},
+		current:   func(string) string { return "nvm version default" },
+		installed: func(string) string { return "nvm ls --no-colors --no-alias" },
+		use:       func(string) string { return `nvm install "$v" && nvm alias default "$v"` },
+	}, core.MethodNvm)
+	Register(&versionManagerStrategy{
+		name:  "pyenv",
+		owner: OwnerPyenv,
+		shell: "sh",
+		latest: func(plugin, prefix string) string {
+			if prefix == "" {
+				prefix = "3"
+			}
+			return "pyenv latest --known " + prefix
+		},
+		current:   func(string) string { return "pyenv global" },
+		installed: func(string) string { return "pyenv versions --bare" },
+		use:       func(string) string { return `pyenv install --skip-existing "$v" && pyenv global "$v"` },
+	}, core.MethodPyenv)
+	Register(&versionManagerStrategy{
+		name:  "rbenv",
+		owner: OwnerRbenv,
+		shell: "sh",
+		latest: func(plugin, prefix string) string {
+			return "rbenv install --list 2>/dev/null | grep -E " + shellQuote(releasePattern(prefix)) + " | tail -n 1"
+		},
+		current:   func(string) string { return "rbenv global" },
+		installed: func(string) string { return "rbenv versions --bare" },
+		use:       func(string) string { return `rbenv install --skip-existing "$v" && rbenv global "$v"` },
+	}, core.MethodRbenv)
+	Register(&versionManagerStrategy{
+		name:  "goenv",
+		owner: OwnerGoenv,
+		shell: "sh",
+		latest: func(plugin, prefix string) string {
+			return "goenv install --list | tr -d ' ' | grep -E " + shellQuote(releasePattern(prefix)) + " | tail -n 1"
+		},
+		current:   func(string) string { return "goenv global" },
+		installed: func(string) string { return "goenv versions --bare" },
+		use:       func(string) string { return `goenv install --skip-existing "$v" && goenv global "$v"` },
+	}, core.MethodGoenv)
+}
+
+// releasePattern matches stable releases in an install --list, within the
+// release line prefix when one is given ("3.2" matches 3.2 and 3.2.x)
+func releasePattern(prefix string) string {
+	if prefix == "" {
+		return `^[0-9]+(\.[0-9]+)+$`
+	}
+	return "^" + regexp.QuoteMeta(prefix) + `(\.[0-9]+)*$`
+}
+
+// asdfPlugins maps the names other package managers use to asdf plugins
+var asdfPlugins = map[string]string{"node": "nodejs", "go": "golang"}
+
+// releasePrefixPattern is what a release line prefix may hold; it goes into
+// the manager's shell unquoted
+var releasePrefixPattern = regexp.MustCompile(`^[0-9][0-9.]*$`)
+
+// runtime splits t.Package into the plugin and the release line prefix.
+// A prefix must be dotted numbers, so brew-style names such as
+// "python@3.13" keep working and "node" or "node@lts" have none.
+func (s *versionManagerStrategy) runtime(t core.Tool) (plugin, prefix string) {
+	plugin, prefix, _ = strings.Cut(packageName(t), "@")
+	if !releasePrefixPattern.MatchString(prefix) {
+		prefix = ""
+	}
+	if s.name == "asdf" && asdfPlugins[plugin] != "" {
+		plugin = asdfPlugins[plugin]
+	}
+	return shellQuote(plugin), prefix
+}
+
+func (s *versionManagerStrategy) Name() string { return s.name }
+
+// script is a PlanStep running a one-liner through the manager's shell
+func (s *versionManagerStrategy) script(cmd string) PlanStep {
+	return PlanStep{Args: []string{s.shell, "-c", s.prelude + cmd}}
+}
+
+// query runs a read-only one-liner and returns its trimmed output
+func (s *versionManagerStrategy) query(d *Detector, cmd string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), versionManagerTimeout)
+	defer cancel()
+	step := s.script(cmd)
+	res := d.runner.Run(ctx, Command{Name: step.Args[0], Args: step.Args[1:]})
+	if res.Err != nil {
+		return "", fmt.Errorf("%s: %v", s.name, res.Err)
+	}
+	return strings.TrimSpace(res.Stdout), nil
+}
+
+// managedVersion cleans one version as a manager prints it ("v20.11.0",
+// "*22.3.0", "-> v20.11.0 *"), "" when the text holds none
+func managedVersion(text string) string {
+	for _, field := range strings.Fields(text) {
+		field = strings.TrimLeft(field, "*v")
+		if field != "" && field[0] >= '0' && field[0] <= '9' {
+			return field
+		}
+	}
+	return ""
+}
+
+// DetectLocal reports the global version. "system" or nothing at all means
+// the manager does not provide the runtime, so it counts as missing.
+func (s *versionManagerStrategy) DetectLocal(d *Detector, t core.Tool) (string, string) {
+	plugin, _ := s.runtime(t)
+	out, err := s.query(d, s.current(plugin))
+	if err != nil {
+		return "MISSING", SourceNone
+	}
+	if s.name == "asdf" {
+		out = path.Base(out)
+	}
+	if v := managedVersion(firstLine(out)); v != "" {
+		return v, SourceVersionManager
+	}
+	return "MISSING", SourceNone
+}
+
+// firstLine returns text up to its first newline
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
+
+// WarmUp is a no-op: the latest release is asked per tool, see Latest
+func (s *versionManagerStrategy) WarmUp(d *Detector) {}
+
+// Latest asks the manager for its newest release of t
+func (s *versionManagerStrategy) Latest(d *Detector, t core.Tool) (string, error) {
+	plugin, prefix := s.runtime(t)
+	out, err := s.query(d, s.latest(plugin, prefix))
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(out, "\n")
+	if v := managedVersion(lines[len(lines)-1]); v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("%s printed no version for %s", s.name, t.Key)
+}
+
+// Versions lists the installed versions of t and the global one
+func (s *versionManagerStrategy) Versions(d *Detector, t core.Tool) ([]string, string, error) {
+	plugin, _ := s.runtime(t)
+	out, err := s.query(d, s.installed(plugin))
+	if err != nil {
+		return nil, "", err
+	}
+	var versions []string
+	for _, line := range strings.Split(out, "\n") {
+		if v := managedVersion(line); v != "" {
+			versions = append(versions, v)
+		}
+	}
+	global, _ := s.DetectLocal(d, t)
+	return versions, global, nil
+}
+
+// latestFinder is implemented by strategies that ask their own tool for the
+// newest release of each package instead of warming up in bulk
+type latestFinder interface {
+	Latest(d *Detector, t core.Tool) (string, error)
+}
+
+// versionLister is implemented by strategies that keep several versions of
+// a tool side by side
+type versionLister interface {
+	Versions(d *Detector, t core.Tool) (installed []string, current string, err error)
+}
+
+// managesVersions reports whether t's method keeps several versions of it
+func managesVersions(t core.Tool) bool {
+	s, ok := StrategyFor(t.Method)
+	if !ok {
+		return false
+	}
+	_, ok = s.(versionLister)
+	return ok
+}
+
+// ManagedVersions lists the versions of t its version manager has installed
+// and the global one. ok is false when t's method is no version manager or
+// the manager could not be queried.
+func (d *Detector) ManagedVersions(t core.Tool) (installed []string, current string, ok bool) {
+	s, found := StrategyFor(t.Method)
+	if !found {
+		return nil, "", false
+	}
+	l, found := s.(versionLister)
+	if !found {
+		return nil, "", false
+	}
+	installed, current, err := l.Versions(d, t)
+	return installed, current, err == nil
+}
+
+// Plan resolves the newest release in the shell, then installs it and makes
+// it global. Uninstalling is left to the user: projects may pin any of the
+// installed versions.
+func (s *versionManagerStrategy) Plan(a Action, t core.Tool) []PlanStep {
+	if a == ActionUninstall {
+		return nil
+	}
+	plugin, prefix := s.runtime(t)
+	return []PlanStep{s.script(`v=$(` + s.latest(plugin, prefix) + `) && [ -n "$v" ] && ` + s.use(plugin))}
+}
+
+// LockKey is per manager: its installs share one directory of versions
+func (s *versionManagerStrategy) LockKey(t core.Tool) string { return s.name }
+
+// Owns claims the manager's shims and installs
+func (s *versionManagerStrategy) Owns(o Owner) bool { return o == s.owner }
+
+func (s *versionManagerStrategy) Upgrade(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, s.name+" upgrade", s.Plan(ActionUpgrade, t))
+}
+
+func (s *versionManagerStrategy) Install(ctx context.Context, e *Executor, t core.Tool) error {
+	return e.runSteps(ctx, s.name+" install", s.Plan(ActionInstall, t))
+}
+
+func (s *versionManagerStrategy) Uninstall(ctx context.Context, e *Executor, t core.Tool) error {
+	return errManual("uninstall")
+}
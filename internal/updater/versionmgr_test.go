@@ -0,0 +1,137 @@
+package updater
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dpeluche/spark/internal/core"
+)
+
+func TestVersionManagerDetect(t *testing.T) {
+	tests := []struct {
+		name        string
+		tool        core.Tool
+		setup       func(r *FakeRunner)
+		wantLocal   string
+		wantRemote  string
+		wantVersion []string
+	}{
+		{
+			name: "mise keeps the release line",
+			tool: core.Tool{Key: "python3", Binary: "python3", Package: "python@3.12", Method: core.MethodMise},
+			setup: func(r *FakeRunner) {
+				r.On("sh -c mise current python", Stdout("3.12.4\n"))
+				r.On("sh -c mise latest python@3.12", Stdout("3.12.7\n"))
+				r.On("sh -c mise ls --installed python", Stdout("python  3.11.9\npython  3.12.4  ~/.config/mise/config.toml  3.12\n"))
+			},
+			wantLocal:   "3.12.4",
+			wantRemote:  "3.12.7",
+			wantVersion: []string{"3.11.9", "3.12.4"},
+		},
+		{
+			name: "asdf renames node",
+			tool: core.Tool{Key: "node", Binary: "node", Package: "node", Method: core.MethodAsdf},
+			setup: func(r *FakeRunner) {
+				r.On(`sh -c basename "$(asdf where nodejs)"`, Stdout("20.11.0\n"))
+				r.On("sh -c asdf latest nodejs", Stdout("22.3.0\n"))
+				r.On("sh -c asdf list nodejs", Stdout("  20.11.0\n *22.3.0\n"))
+			},
+			wantLocal:   "20.11.0",
+			wantRemote:  "22.3.0",
+			wantVersion: []string{"20.11.0", "22.3.0"},
+		},
+		{
+			name: "nvm prints v prefixes",
+			tool: core.Tool{Key: "node", Binary: "node", Package: "node", Method: core.MethodNvm},
+			setup: func(r *FakeRunner) {
+				prelude := `bash -c . "${NVM_DIR:-$HOME/.nvm}/nvm.sh" && `
+				r.On(prelude+"nvm version default", Stdout("v20.11.0\n"))
+				r.On(prelude+"nvm version-remote --lts", Stdout("v22.11.0\n"))
+				r.On(prelude+"nvm ls --no-colors --no-alias", Stdout("->     v20.11.0 *\n       v18.19.1 *\n"))
+			},
+			wantLocal:   "20.11.0",
+			wantRemote:  "22.11.0",
+			wantVersion: []string{"20.11.0", "18.19.1"},
+		},
+		{
+			name: "pyenv on the system python",
+			tool: core.Tool{Key: "python3", Binary: "python3", Package: "python@3.13", Method: core.MethodPyenv},
+			setup: func(r *FakeRunner) {
+				r.On("sh -c pyenv global", Stdout("system\n"))
+				r.On("sh -c pyenv latest --known 3.13", Stdout("3.13.1\n"))
+				r.On("sh -c pyenv versions --bare", Stdout(""))
+			},
+			wantLocal:  "MISSING",
+			wantRemote: "3.13.1",
+		},
+		{
+			name: "rbenv picks the last stable release",
+			tool: core.Tool{Key: "ruby", Binary: "ruby", Package: "ruby", Method: core.MethodRbenv},
+			setup: func(r *FakeRunner) {
+				r.On("sh -c rbenv global", Stdout("3.3.0\n"))
+				r.On(`sh -c rbenv install --list 2>/dev/null | grep -E '^[0-9]+(\.[0-9]+)+$' | tail -n 1`, Stdout("3.3.6\n"))
+				r.On("sh -c rbenv versions --bare", Stdout("3.2.2\n3.3.0\n"))
+			},
+			wantLocal:   "3.3.0",
+			wantRemote:  "3.3.6",
+			wantVersion: []string{"3.2.2", "3.3.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewFakeRunner()
+			tt.setup(r)
+			d := newTestDetector(t, r)
+
+			local, source := d.DetectLocal(tt.tool)
+			if local != tt.wantLocal {
+				t.Errorf("DetectLocal() = %q, want %q", local, tt.wantLocal)
+			}
+			if local != "MISSING" && source != SourceVersionManager {
+				t.Errorf("DetectLocal() source = %q, want %q", source, SourceVersionManager)
+			}
+			if remote, source := d.DetectRemote(tt.tool, local); remote != tt.wantRemote || source != SourceVersionManager {
+				t.Errorf("DetectRemote() = %q, %q, want %q from the version manager", remote, source, tt.wantRemote)
+			}
+			installed, current, ok := d.ManagedVersions(tt.tool)
+			if !ok || !reflect.DeepEqual(installed, tt.wantVersion) || current != tt.wantLocal {
+				t.Errorf("ManagedVersions() = %v, %q, %v, want %v with %q global", installed, current, ok, tt.wantVersion, tt.wantLocal)
+			}
+		})
+	}
+}
+
+func TestVersionManagerPlan(t *testing.T) {
+	tests := []struct {
+		tool core.Tool
+		want string
+	}{
+		{
+			core.Tool{Key: "node", Package: "node", Method: core.MethodMise},
+			`sh -c 'v=$(mise latest node) && [ -n "$v" ] && mise use --global node@"$v"'`,
+		},
+		{
+			core.Tool{Key: "go", Package: "go", Method: core.MethodAsdf},
+			`sh -c 'v=$(asdf latest golang) && [ -n "$v" ] && asdf install golang "$v" && { asdf set --home golang "$v" 2>/dev/null || asdf global golang "$v"; }'`,
+		},
+		{
+			core.Tool{Key: "python3", Package: "python@3.13", Method: core.MethodPyenv},
+			`sh -c 'v=$(pyenv latest --known 3.13) && [ -n "$v" ] && pyenv install --skip-existing "$v" && pyenv global "$v"'`,
+		},
+		{
+			// Anything but dotted numbers after the @ is not a release line
+			core.Tool{Key: "node", Package: "node@20;touch x", Method: core.MethodMise},
+			`sh -c 'v=$(mise latest node) && [ -n "$v" ] && mise use --global node@"$v"'`,
+		},
+	}
+	for _, tt := range tests {
+		s, _ := StrategyFor(tt.tool.Method)
+		steps := s.Plan(ActionUpgrade, tt.tool)
+		if len(steps) != 1 || steps[0].Shell() != tt.want {
+			t.Errorf("%s upgrade plan = %+v, want %s", tt.tool.Method, steps, tt.want)
+		}
+		if steps := s.Plan(ActionUninstall, tt.tool); len(steps) != 0 {
+			t.Errorf("%s uninstall plan = %+v, want manual", tt.tool.Method, steps)
+		}
+	}
+}